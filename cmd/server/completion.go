@@ -0,0 +1,85 @@
+package main
+
+import (
+	"context"
+	"errors"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+	"github.com/shaowenchen/ops-mcp-server/pkg/auth"
+	"github.com/shaowenchen/ops-mcp-server/pkg/modules"
+	"github.com/shaowenchen/ops-mcp-server/pkg/prompts"
+)
+
+// completionProvider answers completion/complete requests from the current
+// instances of the enabled modules. MCP can only reference prompts and
+// resources, so a ref/prompt naming a tool completes that tool's arguments.
+type completionProvider struct {
+	modules *modules.Set
+	enabled map[string]bool
+}
+
+// completionOptions returns the server options enabling completions of the
+// enabled modules, all modules when enabled is nil
+func completionOptions(set *modules.Set, enabled map[string]bool) []server.ServerOption {
+	provider := &completionProvider{modules: set, enabled: enabled}
+	return []server.ServerOption{
+		server.WithCompletions(),
+		server.WithPromptCompletionProvider(provider),
+		server.WithResourceCompletionProvider(provider),
+	}
+}
+
+// CompletePromptArgument completes an argument of a prompt or a tool
+func (p *completionProvider) CompletePromptArgument(ctx context.Context, name string, argument mcp.CompleteArgument, completeContext mcp.CompleteContext) (*mcp.Completion, error) {
+	sources := prompts.CompletionSources(name, argument.Name)
+	if sources == nil {
+		if module := p.modules.ModuleOf(name); module != "" && p.allowed(ctx, module, name) {
+			sources = []prompts.CompletionSource{{Module: module, Argument: argument.Name}}
+		}
+	}
+
+	var candidates []string
+	var errs []error
+	for _, source := range sources {
+		if !p.allowed(ctx, source.Module, "") {
+			continue
+		}
+		values, err := p.modules.CompleteArgument(ctx, p.enabled, source.Module, source.Argument, completeContext.Arguments)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		candidates = append(candidates, values...)
+	}
+	// Report backend failures only when no source could suggest anything
+	if len(candidates) == 0 && len(errs) > 0 {
+		return nil, errors.Join(errs...)
+	}
+	return modules.Complete(candidates, argument.Value), nil
+}
+
+// CompleteResourceArgument completes a variable of a resource template
+func (p *completionProvider) CompleteResourceArgument(ctx context.Context, uri string, argument mcp.CompleteArgument, completeContext mcp.CompleteContext) (*mcp.Completion, error) {
+	module := p.modules.ModuleOfResource(uri)
+	if module == "" || !p.allowed(ctx, module, "") {
+		return modules.Complete(nil, argument.Value), nil
+	}
+	values, err := p.modules.CompleteArgument(ctx, p.enabled, module, argument.Name, completeContext.Arguments)
+	if err != nil {
+		return nil, err
+	}
+	return modules.Complete(values, argument.Value), nil
+}
+
+// allowed reports whether the caller may use module, and tool when set
+func (p *completionProvider) allowed(ctx context.Context, module, tool string) bool {
+	identity, ok := auth.IdentityFromContext(ctx)
+	if !ok {
+		return true
+	}
+	if tool != "" {
+		return identity.Allows(module, tool)
+	}
+	return identity.Modules == nil || identity.Modules[module]
+}
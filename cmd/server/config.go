@@ -0,0 +1,123 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+	"go.uber.org/zap"
+	"gopkg.in/yaml.v3"
+
+	"github.com/shaowenchen/ops-mcp-server/pkg/auth"
+	"github.com/shaowenchen/ops-mcp-server/pkg/config"
+	"github.com/shaowenchen/ops-mcp-server/pkg/limits"
+	"github.com/shaowenchen/ops-mcp-server/pkg/modules"
+)
+
+var configCmd = &cobra.Command{
+	Use:   "config",
+	Short: "Validate, print or describe the configuration",
+	Long: `Load the configuration the same way the server does, from the config file,
+environment variables and flags, and validate or print the result.`,
+}
+
+var configValidateCmd = &cobra.Command{
+	Use:           "validate",
+	Short:         "Validate the effective configuration",
+	Long:          `Check server settings, backend endpoints, timeouts, auth tokens and limit rules, and build every enabled module.`,
+	SilenceUsage:  true,
+	SilenceErrors: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cfg, err := loadConfig(cmd)
+		if err != nil {
+			return fmt.Errorf("failed to load config: %w", err)
+		}
+		if err := validateConfig(cfg); err != nil {
+			return fmt.Errorf("configuration is invalid:\n%w", err)
+		}
+		fmt.Println("Configuration is valid")
+		return nil
+	},
+}
+
+var configPrintCmd = &cobra.Command{
+	Use:           "print",
+	Short:         "Print the effective configuration with secrets masked",
+	SilenceUsage:  true,
+	SilenceErrors: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cfg, err := loadConfig(cmd)
+		if err != nil {
+			return fmt.Errorf("failed to load config: %w", err)
+		}
+		cfg.MaskSecrets()
+
+		output, _ := cmd.Flags().GetString("output")
+		switch output {
+		case "yaml":
+			encoder := yaml.NewEncoder(os.Stdout)
+			encoder.SetIndent(2)
+			defer encoder.Close()
+			return encoder.Encode(cfg)
+		case "json":
+			encoder := json.NewEncoder(os.Stdout)
+			encoder.SetIndent("", "  ")
+			return encoder.Encode(cfg)
+		default:
+			return fmt.Errorf("unknown output format %q, expected yaml or json", output)
+		}
+	},
+}
+
+var configSchemaCmd = &cobra.Command{
+	Use:   "schema",
+	Short: "Print the JSON Schema of the configuration file",
+	Long:  `Print a JSON Schema of the configuration file for editor completion, e.g. with the yaml-language-server comment "# yaml-language-server: $schema=config.schema.json".`,
+	Run: func(cmd *cobra.Command, args []string) {
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		encoder.Encode(config.Schema())
+	},
+}
+
+func init() {
+	configPrintCmd.Flags().StringP("output", "o", "yaml", "Output format: yaml or json")
+
+	configCmd.AddCommand(configValidateCmd)
+	configCmd.AddCommand(configPrintCmd)
+	configCmd.AddCommand(configSchemaCmd)
+	rootCmd.AddCommand(configCmd)
+}
+
+// validateConfig checks cfg and the settings only verified when their
+// component is built: modules, auth tokens and limit rules
+func validateConfig(cfg *config.Config) error {
+	var errs []error
+	if err := cfg.Validate(); err != nil {
+		errs = append(errs, err)
+	}
+	var instances []*modules.Instance
+	for _, f := range modules.All() {
+		if !*f.Enabled(cfg) {
+			continue
+		}
+		instance, err := f.Build(cfg, zap.NewNop())
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		instances = append(instances, instance)
+	}
+	if err := modules.CheckToolNames(instances); err != nil {
+		errs = append(errs, err)
+	}
+	if _, err := auth.NewTokenStore(cfg.Auth.Tokens); err != nil {
+		errs = append(errs, fmt.Errorf("auth.tokens: %w", err))
+	}
+	if _, err := limits.New(cfg.Limits); err != nil {
+		errs = append(errs, fmt.Errorf("limits: %w", err))
+	}
+	return errors.Join(errs...)
+}
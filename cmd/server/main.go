@@ -1,14 +1,21 @@
 package main
 
 import (
+	"context"
+	"crypto/subtle"
 	"encoding/json"
 	"errors"
 	"fmt"
 	"log"
+	"net"
 	"net/http"
 	"os"
+	"os/signal"
+	"slices"
 	"strconv"
 	"strings"
+	"sync/atomic"
+	"syscall"
 	"time"
 
 	"github.com/spf13/cobra"
@@ -18,14 +25,21 @@ import (
 
 	"github.com/mark3labs/mcp-go/server"
 	"github.com/shaowenchen/ops-mcp-server/cmd/version"
+	"github.com/shaowenchen/ops-mcp-server/pkg/audit"
+	"github.com/shaowenchen/ops-mcp-server/pkg/auth"
 	"github.com/shaowenchen/ops-mcp-server/pkg/config"
 	"github.com/shaowenchen/ops-mcp-server/pkg/docs"
+	"github.com/shaowenchen/ops-mcp-server/pkg/limits"
 	"github.com/shaowenchen/ops-mcp-server/pkg/metrics"
-	eventsModule "github.com/shaowenchen/ops-mcp-server/pkg/modules/events"
-	logsModule "github.com/shaowenchen/ops-mcp-server/pkg/modules/logs"
-	metricsModule "github.com/shaowenchen/ops-mcp-server/pkg/modules/metrics"
-	sopsModule "github.com/shaowenchen/ops-mcp-server/pkg/modules/sops"
-	tracesModule "github.com/shaowenchen/ops-mcp-server/pkg/modules/traces"
+	"github.com/shaowenchen/ops-mcp-server/pkg/modules"
+	"github.com/shaowenchen/ops-mcp-server/pkg/prompts"
+
+	// Modules register themselves with pkg/modules on import
+	_ "github.com/shaowenchen/ops-mcp-server/pkg/modules/events"
+	_ "github.com/shaowenchen/ops-mcp-server/pkg/modules/logs"
+	_ "github.com/shaowenchen/ops-mcp-server/pkg/modules/metrics"
+	_ "github.com/shaowenchen/ops-mcp-server/pkg/modules/sops"
+	_ "github.com/shaowenchen/ops-mcp-server/pkg/modules/traces"
 )
 
 // normalizeURI normalizes the URI path to ensure consistent handling of trailing slashes
@@ -52,12 +66,9 @@ func normalizeURI(uri string) string {
 
 // parseEnabledModules parses the enabled query parameter and returns a map of enabled modules
 func parseEnabledModules(queryParams string) map[string]bool {
-	enabled := map[string]bool{
-		"sops":    true, // default all enabled
-		"events":  true,
-		"metrics": true,
-		"logs":    true,
-		"traces":  true,
+	enabled := make(map[string]bool)
+	for _, name := range modules.Names() {
+		enabled[name] = true // default all enabled
 	}
 
 	if queryParams == "" {
@@ -93,17 +104,6 @@ func parseEnabledModules(queryParams string) map[string]bool {
 	return enabled
 }
 
-// getEnabledModuleNames returns a slice of enabled module names
-func getEnabledModuleNames(enabled map[string]bool) []string {
-	var names []string
-	for module, isEnabled := range enabled {
-		if isEnabled {
-			names = append(names, module)
-		}
-	}
-	return names
-}
-
 var (
 	cfgFile string
 	logger  *zap.Logger
@@ -152,6 +152,15 @@ func applyEnvOverrides(cfg *config.Config) {
 		overrideInt(&cfg.Metrics.Prometheus.Timeout, "METRICS_PROMETHEUS_TIMEOUT")
 	}
 
+	// Alertmanager config overrides
+	if cfg.Metrics.Alertmanager != nil {
+		overrideString(&cfg.Metrics.Alertmanager.Endpoint, "METRICS_ALERTMANAGER_ENDPOINT")
+		overrideString(&cfg.Metrics.Alertmanager.Username, "METRICS_ALERTMANAGER_USERNAME")
+		overrideString(&cfg.Metrics.Alertmanager.Password, "METRICS_ALERTMANAGER_PASSWORD")
+		overrideString(&cfg.Metrics.Alertmanager.Token, "METRICS_ALERTMANAGER_TOKEN")
+		overrideInt(&cfg.Metrics.Alertmanager.Timeout, "METRICS_ALERTMANAGER_TIMEOUT")
+	}
+
 	// Elasticsearch config overrides
 	if cfg.Logs.Elasticsearch != nil {
 		overrideString(&cfg.Logs.Elasticsearch.Endpoint, "LOGS_ELASTICSEARCH_ENDPOINT")
@@ -185,6 +194,11 @@ func applyEnvOverrides(cfg *config.Config) {
 	overrideString(&cfg.Server.URI, "SERVER_URI")
 	overrideString(&cfg.Server.Token, "SERVER_TOKEN")
 	overrideInt(&cfg.Server.Port, "SERVER_PORT")
+	overrideInt(&cfg.Server.ShutdownGracePeriod, "SERVER_SHUTDOWN_GRACE_PERIOD")
+	overrideInt(&cfg.Server.DrainDelay, "SERVER_DRAIN_DELAY")
+	overrideString(&cfg.Server.TLS.CertFile, "SERVER_TLS_CERT_FILE")
+	overrideString(&cfg.Server.TLS.KeyFile, "SERVER_TLS_KEY_FILE")
+	overrideString(&cfg.Server.TLS.ClientCAFile, "SERVER_TLS_CLIENT_CA_FILE")
 
 	// Log level override
 	overrideString(&cfg.Log.Level, "LOG_LEVEL")
@@ -201,24 +215,16 @@ func init() {
 	rootCmd.PersistentFlags().String("log-level", "info", "Log level (debug, info, warn, error)")
 	rootCmd.PersistentFlags().String("host", "0.0.0.0", "Server host")
 	rootCmd.PersistentFlags().Int("port", 80, "Server port")
-	rootCmd.PersistentFlags().String("mode", "stdio", "Server mode: stdio or sse")
+	rootCmd.PersistentFlags().String("mode", "stdio", "Server mode: stdio, sse, streamable-http or both")
 	rootCmd.PersistentFlags().String("uri", "/mcp", "MCP server URI path")
+	rootCmd.PersistentFlags().Int("shutdown-grace-period", 25, "Seconds allowed for draining on SIGTERM/SIGINT")
+	rootCmd.PersistentFlags().Int("drain-delay", 5, "Seconds /healthz reports draining before sessions are closed")
+	rootCmd.PersistentFlags().Bool("watch-config", true, "Reload modules when the config file changes")
 
 	// Module flags with different names to avoid conflicts
-	// SOPS module
-	rootCmd.PersistentFlags().Bool("enable-sops", false, "Enable SOPS module")
-
-	// Events module
-	rootCmd.PersistentFlags().Bool("enable-events", false, "Enable events module")
-
-	// Metrics module
-	rootCmd.PersistentFlags().Bool("enable-metrics", false, "Enable metrics module")
-
-	// Logs module
-	rootCmd.PersistentFlags().Bool("enable-logs", false, "Enable logs module")
-
-	// Traces module
-	rootCmd.PersistentFlags().Bool("enable-traces", false, "Enable traces module")
+	for _, f := range modules.All() {
+		rootCmd.PersistentFlags().Bool("enable-"+f.Name, false, fmt.Sprintf("Enable %s module", f.Name))
+	}
 
 	// Bind flags to viper with unique keys
 	viper.BindPFlag("log.level", rootCmd.PersistentFlags().Lookup("log-level"))
@@ -226,21 +232,14 @@ func init() {
 	viper.BindPFlag("server.port", rootCmd.PersistentFlags().Lookup("port"))
 	viper.BindPFlag("server.mode", rootCmd.PersistentFlags().Lookup("mode"))
 	viper.BindPFlag("server.uri", rootCmd.PersistentFlags().Lookup("uri"))
+	viper.BindPFlag("server.shutdown_grace_period", rootCmd.PersistentFlags().Lookup("shutdown-grace-period"))
+	viper.BindPFlag("server.drain_delay", rootCmd.PersistentFlags().Lookup("drain-delay"))
+	viper.BindPFlag("server.watch_config", rootCmd.PersistentFlags().Lookup("watch-config"))
 
-	// SOPS module bindings
-	viper.BindPFlag("cli.sops.enabled", rootCmd.PersistentFlags().Lookup("enable-sops"))
-
-	// Events module bindings
-	viper.BindPFlag("cli.events.enabled", rootCmd.PersistentFlags().Lookup("enable-events"))
-
-	// Metrics module bindings
-	viper.BindPFlag("cli.metrics.enabled", rootCmd.PersistentFlags().Lookup("enable-metrics"))
-
-	// Logs module bindings
-	viper.BindPFlag("cli.logs.enabled", rootCmd.PersistentFlags().Lookup("enable-logs"))
-
-	// Traces module bindings
-	viper.BindPFlag("cli.traces.enabled", rootCmd.PersistentFlags().Lookup("enable-traces"))
+	// Module bindings
+	for _, f := range modules.All() {
+		viper.BindPFlag("cli."+f.Name+".enabled", rootCmd.PersistentFlags().Lookup("enable-"+f.Name))
+	}
 }
 
 func initConfig() {
@@ -259,6 +258,11 @@ func initConfig() {
 	viper.BindEnv("server.mode", "SERVER_MODE")
 	viper.BindEnv("server.uri", "SERVER_URI")
 	viper.BindEnv("server.token", "SERVER_TOKEN")
+	viper.BindEnv("server.shutdown_grace_period", "SERVER_SHUTDOWN_GRACE_PERIOD")
+	viper.BindEnv("server.drain_delay", "SERVER_DRAIN_DELAY")
+	viper.BindEnv("server.tls.cert_file", "SERVER_TLS_CERT_FILE")
+	viper.BindEnv("server.tls.key_file", "SERVER_TLS_KEY_FILE")
+	viper.BindEnv("server.tls.client_ca_file", "SERVER_TLS_CLIENT_CA_FILE")
 	viper.BindEnv("sops.ops.endpoint", "SOPS_OPS_ENDPOINT")
 	viper.BindEnv("sops.ops.token", "SOPS_OPS_TOKEN")
 	viper.BindEnv("events.ops.endpoint", "EVENTS_OPS_ENDPOINT")
@@ -267,6 +271,10 @@ func initConfig() {
 	viper.BindEnv("metrics.prometheus.username", "METRICS_PROMETHEUS_USERNAME")
 	viper.BindEnv("metrics.prometheus.password", "METRICS_PROMETHEUS_PASSWORD")
 	viper.BindEnv("metrics.prometheus.token", "METRICS_PROMETHEUS_TOKEN")
+	viper.BindEnv("metrics.alertmanager.endpoint", "METRICS_ALERTMANAGER_ENDPOINT")
+	viper.BindEnv("metrics.alertmanager.username", "METRICS_ALERTMANAGER_USERNAME")
+	viper.BindEnv("metrics.alertmanager.password", "METRICS_ALERTMANAGER_PASSWORD")
+	viper.BindEnv("metrics.alertmanager.token", "METRICS_ALERTMANAGER_TOKEN")
 	viper.BindEnv("logs.elasticsearch.endpoint", "LOGS_ELASTICSEARCH_ENDPOINT")
 	viper.BindEnv("logs.elasticsearch.username", "LOGS_ELASTICSEARCH_USERNAME")
 	viper.BindEnv("logs.elasticsearch.password", "LOGS_ELASTICSEARCH_PASSWORD")
@@ -274,11 +282,9 @@ func initConfig() {
 	viper.BindEnv("traces.jaeger.endpoint", "TRACES_JAEGER_ENDPOINT")
 	viper.BindEnv("traces.jaeger.timeout", "TRACES_JAEGER_TIMEOUT")
 	// Module enablement environment variables
-	viper.BindEnv("sops.enabled", "SOPS_ENABLED")
-	viper.BindEnv("events.enabled", "EVENTS_ENABLED")
-	viper.BindEnv("metrics.enabled", "METRICS_ENABLED")
-	viper.BindEnv("logs.enabled", "LOGS_ENABLED")
-	viper.BindEnv("traces.enabled", "TRACES_ENABLED")
+	for _, f := range modules.All() {
+		viper.BindEnv(f.Section+".enabled", strings.ToUpper(f.Section)+"_ENABLED")
+	}
 
 	// Load main config file first
 	if cfgFile != "" {
@@ -311,83 +317,56 @@ func initConfig() {
 	}
 }
 
-func runServer(cmd *cobra.Command, args []string) {
-	defer logger.Sync()
-
-	// Get log level for debug logging
-	logLevel := viper.GetString("log.level")
-
-	// Load configuration
+// loadConfig builds the configuration from viper, applying environment
+// variable overrides and the module enablement flags of cmd
+func loadConfig(cmd *cobra.Command) (*config.Config, error) {
 	var cfg config.Config
 	if err := viper.Unmarshal(&cfg); err != nil {
-		logger.Fatal("Failed to unmarshal config", zap.Error(err))
+		return nil, err
 	}
 
 	// Apply environment variable overrides
 	// This allows environment variables to override empty values from config file
 	applyEnvOverrides(&cfg)
 
+	// Read secrets from their files and expand ${VAR} references
+	if err := cfg.ResolveSecrets(); err != nil {
+		return nil, err
+	}
+
 	// Module enablement logic: CLI flags take precedence over environment variables
 	// If CLI flag is set, use CLI value; otherwise use environment variable; otherwise use default (false)
 
-	// Sops module
-	if cmd.Flags().Changed("enable-sops") {
-		// CLI flag takes precedence
-		cfg.Sops.Enabled = viper.GetBool("cli.sops.enabled")
-	} else {
-		// Use environment variable or default to false
-		cfg.Sops.Enabled = viper.GetBool("sops.enabled")
-		if !viper.IsSet("sops.enabled") {
-			cfg.Sops.Enabled = false // default
+	for _, f := range modules.All() {
+		enabled := f.Enabled(&cfg)
+		if cmd.Flags().Changed("enable-" + f.Name) {
+			// CLI flag takes precedence
+			*enabled = viper.GetBool("cli." + f.Name + ".enabled")
+		} else {
+			// Use environment variable or default to false
+			*enabled = viper.GetBool(f.Section + ".enabled")
+			if !viper.IsSet(f.Section + ".enabled") {
+				*enabled = false // default
+			}
 		}
 	}
 
-	// Events module
-	if cmd.Flags().Changed("enable-events") {
-		// CLI flag takes precedence
-		cfg.Events.Enabled = viper.GetBool("cli.events.enabled")
-	} else {
-		// Use environment variable or default to false
-		cfg.Events.Enabled = viper.GetBool("events.enabled")
-		if !viper.IsSet("events.enabled") {
-			cfg.Events.Enabled = false // default
-		}
-	}
+	return &cfg, nil
+}
 
-	// Metrics module
-	if cmd.Flags().Changed("enable-metrics") {
-		// CLI flag takes precedence
-		cfg.Metrics.Enabled = viper.GetBool("cli.metrics.enabled")
-	} else {
-		// Use environment variable or default to false
-		cfg.Metrics.Enabled = viper.GetBool("metrics.enabled")
-		if !viper.IsSet("metrics.enabled") {
-			cfg.Metrics.Enabled = false // default
-		}
-	}
+func runServer(cmd *cobra.Command, args []string) {
+	defer logger.Sync()
 
-	// Logs module
-	if cmd.Flags().Changed("enable-logs") {
-		// CLI flag takes precedence
-		cfg.Logs.Enabled = viper.GetBool("cli.logs.enabled")
-	} else {
-		// Use environment variable or default to false
-		cfg.Logs.Enabled = viper.GetBool("logs.enabled")
-		if !viper.IsSet("logs.enabled") {
-			cfg.Logs.Enabled = false // default
-		}
-	}
+	// Get log level for debug logging
+	logLevel := viper.GetString("log.level")
 
-	// Traces module
-	if cmd.Flags().Changed("enable-traces") {
-		// CLI flag takes precedence
-		cfg.Traces.Enabled = viper.GetBool("cli.traces.enabled")
-	} else {
-		// Use environment variable or default to false
-		cfg.Traces.Enabled = viper.GetBool("traces.enabled")
-		if !viper.IsSet("traces.enabled") {
-			cfg.Traces.Enabled = false // default
-		}
+	// Load configuration
+	cfg, err := loadConfig(cmd)
+	if err != nil {
+		logger.Fatal("Failed to load config", zap.Error(err))
+	}
+	if err := cfg.Validate(); err != nil {
+		logger.Warn("Configuration problems found, run 'ops-mcp-server config validate' for details", zap.Error(err))
 	}
 
 	// Get server mode - CLI flag takes precedence over config file
@@ -399,18 +378,17 @@ func runServer(cmd *cobra.Command, args []string) {
 		serverMode = "stdio" // default to stdio mode
 	}
 
-	logger.Info("Starting Ops MCP Server",
+	startFields := []zap.Field{
 		zap.String("log_level", cfg.Log.Level),
 		zap.String("mode", serverMode),
 		zap.String("host", cfg.Server.Host),
 		zap.Int("port", cfg.Server.Port),
 		zap.String("uri", cfg.Server.URI),
-		zap.Bool("sops_enabled", cfg.Sops.Enabled),
-		zap.Bool("events_enabled", cfg.Events.Enabled),
-		zap.Bool("metrics_enabled", cfg.Metrics.Enabled),
-		zap.Bool("logs_enabled", cfg.Logs.Enabled),
-		zap.Bool("traces_enabled", cfg.Traces.Enabled),
-	)
+	}
+	for _, f := range modules.All() {
+		startFields = append(startFields, zap.Bool(f.Name+"_enabled", *f.Enabled(cfg)))
+	}
+	logger.Info("Starting Ops MCP Server", startFields...)
 
 	// Initialize metrics system
 	metrics.Init(logger)
@@ -420,193 +398,87 @@ func runServer(cmd *cobra.Command, args []string) {
 	metrics.SetBuildInfo(versionInfo.Version, versionInfo.GitCommit, versionInfo.BuildDate)
 	
 	// Set module enabled status
-	metrics.Get().SetModuleEnabled("sops", cfg.Sops.Enabled)
-	metrics.Get().SetModuleEnabled("events", cfg.Events.Enabled)
-	metrics.Get().SetModuleEnabled("metrics", cfg.Metrics.Enabled)
-	metrics.Get().SetModuleEnabled("logs", cfg.Logs.Enabled)
-	metrics.Get().SetModuleEnabled("traces", cfg.Traces.Enabled)
+	for _, f := range modules.All() {
+		metrics.Get().SetModuleEnabled(f.Name, *f.Enabled(cfg))
+	}
 	
 	// Start system metrics collector
 	metrics.StartSystemMetricsCollector(logger)
 
-	// Create MCP server
-	mcpServer := server.NewMCPServer("ops-mcp-server", version.BuildVersion)
-
-	// Register modules based on configuration
-	var toolCount int
-	var enabledTools []string
-	var sopsTools []string
-	var eventsTools []string
-	var metricsTools []string
-	var logsTools []string
-	var tracesTools []string
-
-	if cfg.Sops.Enabled {
-		// Create Sops module instance with configuration
-		sopsConfig := &sopsModule.Config{
-			Tools: sopsModule.ToolsConfig{
-				Prefix: cfg.Sops.Tools.Prefix,
-				Suffix: cfg.Sops.Tools.Suffix,
-			},
-		}
-
-		// Add Ops configuration if available
-		if cfg.Sops.Ops != nil {
-			sopsConfig.Endpoint = cfg.Sops.Ops.Endpoint
-			sopsConfig.Token = cfg.Sops.Ops.Token
-		}
-		sopsModuleInstance, err := sopsModule.New(sopsConfig, logger)
-		if err != nil {
-			logger.Fatal("Failed to create SOPS module", zap.Error(err))
-		}
-
-		// Register tools
-		sopsModuleTools := sopsModuleInstance.GetTools()
-		for _, serverTool := range sopsModuleTools {
-			mcpServer.AddTool(serverTool.Tool, serverTool.Handler)
-			enabledTools = append(enabledTools, serverTool.Tool.Name)
-			sopsTools = append(sopsTools, serverTool.Tool.Name)
-			toolCount++
-		}
-
-		logger.Info("SOPS module enabled", zap.Int("tools", len(sopsModuleTools)), zap.Strings("tool_names", sopsTools))
+	// Initialize the audit log of tool calls, stdout carries the protocol in stdio mode
+	if cfg.Audit.Enabled && serverMode == "stdio" && (cfg.Audit.Output == "" || cfg.Audit.Output == "stdout") {
+		logger.Fatal("audit.output must be stderr or a file path in stdio mode")
 	}
-
-	if cfg.Events.Enabled {
-		// Create events module instance with configuration
-		eventsConfig := &eventsModule.Config{
-			PollInterval: 30 * time.Second, // default poll interval
-			Tools: eventsModule.ToolsConfig{
-				Prefix: cfg.Events.Tools.Prefix,
-				Suffix: cfg.Events.Tools.Suffix,
-			},
-		}
-
-		// Add Ops configuration if available
-		if cfg.Events.Ops != nil {
-			eventsConfig.Endpoint = cfg.Events.Ops.Endpoint
-			eventsConfig.Token = cfg.Events.Ops.Token
-		}
-		eventsModuleInstance, err := eventsModule.New(eventsConfig, logger)
-		if err != nil {
-			logger.Fatal("Failed to create events module", zap.Error(err))
-		}
-
-		// Register tools
-		eventsModuleTools := eventsModuleInstance.GetTools()
-		for _, serverTool := range eventsModuleTools {
-			mcpServer.AddTool(serverTool.Tool, serverTool.Handler)
-			enabledTools = append(enabledTools, serverTool.Tool.Name)
-			eventsTools = append(eventsTools, serverTool.Tool.Name)
-			toolCount++
-		}
-
-		logger.Info("Events module enabled", zap.Int("tools", len(eventsModuleTools)), zap.Strings("tool_names", eventsTools))
+	auditLogger, err := audit.Init(cfg.Audit, logger)
+	if err != nil {
+		logger.Fatal("Failed to initialize audit log", zap.Error(err))
 	}
+	defer auditLogger.Close()
 
-	if cfg.Metrics.Enabled {
-		// Create metrics module instance with configuration
-		metricsConfig := &metricsModule.Config{
-			Tools: metricsModule.ToolsConfig{
-				Prefix: cfg.Metrics.Tools.Prefix,
-				Suffix: cfg.Metrics.Tools.Suffix,
-			},
-		}
-
-		// Add Prometheus configuration if available
-		if cfg.Metrics.Prometheus != nil {
-			metricsConfig.Prometheus = &metricsModule.PrometheusConfig{
-				Endpoint: cfg.Metrics.Prometheus.Endpoint,
-				Username: cfg.Metrics.Prometheus.Username,
-				Password: cfg.Metrics.Prometheus.Password,
-				Token:    cfg.Metrics.Prometheus.Token,
-			}
-		}
-
-		metricsModuleInstance, err := metricsModule.New(metricsConfig, logger)
-		if err != nil {
-			logger.Fatal("Failed to create metrics module", zap.Error(err))
-		}
-
-		// Register tools
-		metricsModuleTools := metricsModuleInstance.GetTools()
-		for _, serverTool := range metricsModuleTools {
-			mcpServer.AddTool(serverTool.Tool, serverTool.Handler)
-			enabledTools = append(enabledTools, serverTool.Tool.Name)
-			metricsTools = append(metricsTools, serverTool.Tool.Name)
-			toolCount++
-		}
-
-		logger.Info("Metrics module enabled", zap.Int("tools", len(metricsModuleTools)), zap.Strings("tool_names", metricsTools))
+	// Register modules based on configuration
+	instances, err := modules.Build(cfg, logger)
+	if err != nil {
+		logger.Fatal("Failed to create modules", zap.Error(err))
 	}
 
-	if cfg.Logs.Enabled {
-		// Create logs module instance with configuration
-		logsConfig := &logsModule.Config{
-			Tools: logsModule.ToolsConfig{
-				Prefix: cfg.Logs.Tools.Prefix,
-				Suffix: cfg.Logs.Tools.Suffix,
-			},
-		}
-
-		// Convert elasticsearch config if present
-		if cfg.Logs.Elasticsearch != nil {
-			logsConfig.Elasticsearch = &logsModule.ElasticsearchConfig{
-				Endpoint: cfg.Logs.Elasticsearch.Endpoint,
-				Username: cfg.Logs.Elasticsearch.Username,
-				Password: cfg.Logs.Elasticsearch.Password,
-				APIKey:   cfg.Logs.Elasticsearch.APIKey,
-				Timeout:  cfg.Logs.Elasticsearch.Timeout,
-			}
-		}
-		logsModuleInstance, err := logsModule.New(logsConfig, logger)
+	// Load the API token table, scopes are enforced on tools/list and tools/call
+	tokens, err := auth.NewTokenStore(cfg.Auth.Tokens)
+	if err != nil {
+		logger.Fatal("Failed to load auth tokens", zap.Error(err))
+	}
+	if !cfg.Auth.Enabled {
+		tokens = nil
+	} else if tokens.Len() == 0 && cfg.Server.Token == "" && !cfg.Auth.OAuth.Enabled {
+		logger.Fatal("auth.enabled requires auth.tokens, auth.oauth or server.token")
+	}
+
+	// Module instances are swapped atomically when the configuration is reloaded
+	moduleSet := modules.NewSet(instances)
+
+	// Track in-flight tool calls so a graceful shutdown can wait for them
+	drain := newDrainer(logger)
+
+	// Middlewares run in order: drain tracking, progress reporting, auditing, scope checks, then rate limits.
+	// Auditing comes before the checks so calls they reject are audited too.
+	// Tool, resource and prompt list changes are announced so clients pick up reloaded modules.
+	serverOptions := []server.ServerOption{
+		server.WithToolCapabilities(true),
+		server.WithResourceCapabilities(false, true),
+		server.WithPromptCapabilities(true),
+		server.WithElicitation(),
+		server.WithToolHandlerMiddleware(drain.toolMiddleware),
+		server.WithToolHandlerMiddleware(modules.ProgressMiddleware),
+		server.WithToolHandlerMiddleware(audit.Middleware(moduleSet.ModuleOf)),
+		server.WithToolFilter(auth.ToolFilter(moduleSet.ModuleOf)),
+		server.WithToolHandlerMiddleware(auth.ToolMiddleware(moduleSet.ModuleOf)),
+		server.WithResourceHandlerMiddleware(auth.ResourceMiddleware(moduleSet.ModuleOfResource)),
+		server.WithHooks(auth.ResourceHooks(moduleSet.ModuleOfResource)),
+	}
+	if cfg.Limits.Enabled {
+		limiter, err := limits.New(cfg.Limits)
 		if err != nil {
-			logger.Fatal("Failed to create logs module", zap.Error(err))
+			logger.Fatal("Failed to load limits", zap.Error(err))
 		}
-
-		// Register tools
-		logsModuleTools := logsModuleInstance.GetTools()
-		for _, serverTool := range logsModuleTools {
-			mcpServer.AddTool(serverTool.Tool, serverTool.Handler)
-			enabledTools = append(enabledTools, serverTool.Tool.Name)
-			logsTools = append(logsTools, serverTool.Tool.Name)
-			toolCount++
-		}
-
-		logger.Info("Logs module enabled", zap.Int("tools", len(logsModuleTools)), zap.Strings("tool_names", logsTools))
+		serverOptions = append(serverOptions, server.WithToolHandlerMiddleware(limiter.Middleware(moduleSet.ModuleOf)))
+		logger.Info("Tool call limits enabled", zap.Int("rules", len(cfg.Limits.Rules)))
 	}
 
-	if cfg.Traces.Enabled {
-		// Create Jaeger module instance with configuration
-		tracesConfig := &tracesModule.Config{
-			Tools: tracesModule.ToolsConfig{
-				Prefix: cfg.Traces.Tools.Prefix,
-				Suffix: cfg.Traces.Tools.Suffix,
-			},
-		}
+	// Create MCP server, completions are added per server as they only suggest
+	// values of its enabled modules
+	mcpServer := server.NewMCPServer("ops-mcp-server", version.BuildVersion, slices.Concat(serverOptions, completionOptions(moduleSet, nil))...)
 
-		// Add Jaeger configuration if available
-		if cfg.Traces.Jaeger != nil {
-			tracesConfig.Endpoint = cfg.Traces.Jaeger.Endpoint
-			tracesConfig.Protocol = "HTTP" // default protocol
-			tracesConfig.Port = 16686      // default port
-			tracesConfig.Timeout = cfg.Traces.Jaeger.Timeout
-		}
-		tracesModuleInstance, err := tracesModule.New(tracesConfig, logger)
-		if err != nil {
-			logger.Fatal("Failed to create Jaeger module", zap.Error(err))
-		}
+	// Register tools, resources and prompts, their handlers dispatch to the current module instances
+	syncTools(mcpServer, moduleSet.Tools(nil))
+	resources := newRegisteredResources()
+	resources.sync(mcpServer, moduleSet.Resources(nil), moduleSet.ResourceTemplates(nil))
+	promptNames := syncPrompts(mcpServer, nil, prompts.Build(promptToolLookup(moduleSet, nil)))
 
-		// Register tools
-		tracesModuleTools := tracesModuleInstance.GetTools()
-		for _, serverTool := range tracesModuleTools {
-			mcpServer.AddTool(serverTool.Tool, serverTool.Handler)
-			enabledTools = append(enabledTools, serverTool.Tool.Name)
-			tracesTools = append(tracesTools, serverTool.Tool.Name)
-			toolCount++
-		}
-
-		logger.Info("Traces module enabled", zap.Int("tools", len(tracesModuleTools)), zap.Strings("tool_names", tracesTools))
+	var toolCount int
+	var enabledTools []string
+	for _, instance := range instances {
+		enabledTools = append(enabledTools, instance.ToolNames()...)
+		toolCount += len(instance.Tools)
+		logger.Info("Module enabled", zap.String("module", instance.Name), zap.Int("tools", len(instance.Tools)), zap.Strings("tool_names", instance.ToolNames()))
 	}
 
 	if toolCount == 0 {
@@ -616,40 +488,34 @@ func runServer(cmd *cobra.Command, args []string) {
 		logger.Info("=== Server Initialization Complete ===")
 		logger.Info("Enabled modules and tools:")
 
-		if cfg.Sops.Enabled {
-			logger.Info("⚙️ Sops Module", zap.String("status", "enabled"), zap.Strings("tools", sopsTools))
-		} else {
-			logger.Info("⚙️ Sops Module", zap.String("status", "disabled"))
-		}
-
-		if cfg.Events.Enabled {
-			logger.Info("📡 Events Module", zap.String("status", "enabled"), zap.Strings("tools", eventsTools))
-		} else {
-			logger.Info("📡 Events Module", zap.String("status", "disabled"))
-		}
-
-		if cfg.Metrics.Enabled {
-			logger.Info("📊 Metrics Module", zap.String("status", "enabled"), zap.Strings("tools", metricsTools))
-		} else {
-			logger.Info("📊 Metrics Module", zap.String("status", "disabled"))
-		}
-
-		if cfg.Logs.Enabled {
-			logger.Info("📋 Logs Module", zap.String("status", "enabled"), zap.Strings("tools", logsTools))
-		} else {
-			logger.Info("📋 Logs Module", zap.String("status", "disabled"))
-		}
-
-		if cfg.Traces.Enabled {
-			logger.Info("🔍 Traces Module", zap.String("status", "enabled"), zap.Strings("tools", tracesTools))
-		} else {
-			logger.Info("🔍 Traces Module", zap.String("status", "disabled"))
+		for _, f := range modules.All() {
+			status := "disabled"
+			var tools []string
+			for _, instance := range instances {
+				if instance.Name == f.Name {
+					status = "enabled"
+					tools = instance.ToolNames()
+				}
+			}
+			logger.Info("Module", zap.String("module", f.Name), zap.String("status", status), zap.Strings("tools", tools))
 		}
 
 		logger.Info("All available tools:", zap.Strings("tools", enabledTools))
 		logger.Info("Server initialized", zap.Int("total_tools", toolCount))
 	}
 
+	// Reload modules on config file changes and SIGHUP
+	reloader := newConfigReloader(cmd, cfg, moduleSet, logger)
+	reloader.onReload(func() {
+		syncTools(mcpServer, moduleSet.Tools(nil))
+		resources.sync(mcpServer, moduleSet.Resources(nil), moduleSet.ResourceTemplates(nil))
+		promptNames = syncPrompts(mcpServer, promptNames, prompts.Build(promptToolLookup(moduleSet, nil)))
+	})
+	go reloader.watch()
+
+	// The server token is read per request so a rotated token_file takes effect on reload
+	serverToken := func() string { return reloader.config().Server.Token }
+
 	// Start server based on mode
 	switch serverMode {
 	case "stdio":
@@ -659,58 +525,103 @@ func runServer(cmd *cobra.Command, args []string) {
 		); err != nil {
 			logger.Fatal("Stdio server failed", zap.Error(err))
 		}
-	case "sse":
+	case "sse", "streamable-http", "both":
+		// sse serves only the legacy SSE transport, streamable-http only the
+		// Streamable HTTP transport and both serves them side by side
+		serveSSE := serverMode != "streamable-http"
+		serveStreamable := serverMode != "sse"
+
 		// Create a custom HTTP mux with health check endpoint
 		mux := http.NewServeMux()
 
 		// Get MCP URI from config and normalize it
 		mcpURI := normalizeURI(cfg.Server.URI)
+		sseEndpoint := mcpURI + "/sse"
+		messageEndpoint := mcpURI + "/message"
+		healthEndpoint := mcpURI + "/healthz"
+
+		endpoints := map[string]string{
+			"docs":   mcpURI + "/docs",
+			"health": healthEndpoint,
+		}
+		if serveStreamable {
+			endpoints["mcp"] = mcpURI
+		}
+		if serveSSE {
+			endpoints["sse"] = sseEndpoint
+			endpoints["message"] = messageEndpoint
+		}
 
 		// Add metrics endpoint (before middleware to avoid auth)
 		mux.Handle(mcpURI+"/metrics", metrics.Handler())
 
+		// Validate OAuth access tokens and serve the protected resource metadata,
+		// like the token table only when auth is enabled
+		var oauth *auth.OAuthValidator
+		if cfg.Auth.Enabled && cfg.Auth.OAuth.Enabled {
+			metadataPath := auth.WellKnownProtectedResource + mcpURI
+			oauth, err = auth.NewOAuthValidator(cfg.Auth.OAuth, metadataPath, logger)
+			if err != nil {
+				logger.Fatal("Failed to configure OAuth", zap.Error(err))
+			}
+			mux.Handle(auth.WellKnownProtectedResource, oauth.MetadataHandler())
+			mux.Handle(metadataPath, oauth.MetadataHandler())
+			endpoints["oauth_protected_resource"] = metadataPath
+		}
+
 		// Add health check endpoint with metrics
-		healthEndpoint := mcpURI + "/healthz"
 		mux.Handle(healthEndpoint, metrics.HTTPMetricsMiddleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 			if r.Method != http.MethodGet {
 				http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
 				return
 			}
 
+			// Report not ready while draining so the pod is taken out of rotation
+			status := "ok"
+			statusCode := http.StatusOK
+			if drain.isDraining() {
+				status = "draining"
+				statusCode = http.StatusServiceUnavailable
+			}
+
 			w.Header().Set("Content-Type", "application/json")
-			w.WriteHeader(http.StatusOK)
+			w.WriteHeader(statusCode)
 
 			versionInfo := version.Get()
 
 			// Parse query parameters to show what modules would be enabled
 			enabledModules := parseEnabledModules(r.URL.RawQuery)
 
+			currentCfg := reloader.config()
+			moduleStatus := make(map[string]bool)
+			for _, f := range modules.All() {
+				moduleStatus[f.Name] = *f.Enabled(currentCfg)
+			}
+			toolCount := 0
+			moduleHealth := make(map[string]string)
+			for _, instance := range moduleSet.Instances() {
+				toolCount += len(instance.Tools)
+				moduleHealth[instance.Name] = "ok"
+				if err := instance.Module.HealthCheck(); err != nil {
+					moduleHealth[instance.Name] = err.Error()
+				}
+			}
+
 			healthResponse := map[string]interface{}{
-				"status":     "ok",
+				"status":     status,
 				"service":    "ops-mcp-server",
 				"version":    versionInfo.Version,
 				"build_date": versionInfo.BuildDate,
 				"git_commit": versionInfo.GitCommit,
 				"timestamp":  time.Now().UTC().Format(time.RFC3339),
 				"mode":       serverMode,
-				"endpoints": map[string]string{
-					"mcp":     mcpURI,
-					"sse":     mcpURI + "/sse",
-					"message": mcpURI + "/message",
-					"docs":    mcpURI + "/docs",
-					"health":  healthEndpoint,
-				},
-				"modules": map[string]bool{
-					"sops":    cfg.Sops.Enabled,
-					"events":  cfg.Events.Enabled,
-					"metrics": cfg.Metrics.Enabled,
-					"logs":    cfg.Logs.Enabled,
-					"traces":  cfg.Traces.Enabled,
-				},
+				"endpoints":  endpoints,
+				"modules":         moduleStatus,
+				"modules_health":  moduleHealth,
 				"enabled_modules": enabledModules,
 				"tools_count":     toolCount,
 				"query_parameters": map[string]interface{}{
-					"enabled": "sops,events,metrics,logs,traces (default: all enabled)",
+					"enabled": strings.Join(modules.Names(), ",") + " (default: all enabled)",
 					"example": mcpURI + "?enabled=sops,events",
 				},
 			}
@@ -718,31 +629,46 @@ func runServer(cmd *cobra.Command, args []string) {
 			json.NewEncoder(w).Encode(healthResponse)
 		}), serverMode))
 
+		// Base context of every request, cancelled during shutdown to end long-lived streams
+		streamsCtx, closeStreams := context.WithCancel(context.Background())
+
 		// Create custom HTTP server with optimized timeouts for MCP and TIME_WAIT management
 		httpServer := &http.Server{
-			Addr:    fmt.Sprintf(":%d", cfg.Server.Port),
-			Handler: mux,
+			Addr: fmt.Sprintf(":%d", cfg.Server.Port),
+			// Map verified client certificates to identities for later middleware
+			Handler: auth.ClientCertIdentity(cfg.Server.TLS.ClientIdentity, mux),
 			// Optimized timeouts for MCP server with TIME_WAIT reduction
 			ReadTimeout:       30 * time.Second, // Reduce read timeout for faster connection release
 			WriteTimeout:      30 * time.Second, // Reduce write timeout for faster connection release
 			IdleTimeout:       60 * time.Second, // Reduce idle timeout for faster cleanup of idle connections
 			ReadHeaderTimeout: 5 * time.Second,  // Quick header validation
+			BaseContext: func(net.Listener) context.Context {
+				return streamsCtx
+			},
+		}
+
+		// Legacy SSE options, falling back to the deprecated top-level sse section
+		sseConfig := cfg.Server.SSE
+		if sseConfig == (config.SSEConfig{}) {
+			sseConfig = cfg.SSE
 		}
 
 		// Create SSE server with dynamic base path
-		sseServer := server.NewSSEServer(
-			mcpServer,
+		sseOptions := []server.SSEOption{
 			server.WithDynamicBasePath(func(r *http.Request, sessionID string) string {
 				// Use the configured MCP URI as the base path
 				return mcpURI
 			}),
 			server.WithBaseURL(fmt.Sprintf(":%d", cfg.Server.Port)),
 			server.WithUseFullURLForMessageEndpoint(true),
-		)
+		}
+		if sseConfig.KeepAlive > 0 {
+			sseOptions = append(sseOptions, server.WithKeepAliveInterval(sseConfig.KeepAlive))
+		}
+		sseServer := server.NewSSEServer(mcpServer, sseOptions...)
 
 		// Add SSE and message endpoints using the SSE server handlers with debug logging
-		sseEndpoint := mcpURI + "/sse"
-		messageEndpoint := mcpURI + "/message"
+		var sseConnections atomic.Int64
 
 		// Create wrapped handlers with debug logging and metrics
 		sseHandler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
@@ -755,6 +681,13 @@ func runServer(cmd *cobra.Command, args []string) {
 					zap.Strings("headers", getHeaderStrings(r.Header)),
 				)
 			}
+			if sseConfig.MaxConnections > 0 && sseConnections.Load() >= int64(sseConfig.MaxConnections) {
+				http.Error(w, "Too many SSE connections", http.StatusServiceUnavailable)
+				return
+			}
+			sseConnections.Add(1)
+			defer sseConnections.Add(-1)
+
 			metrics.RecordSSEConnection()
 			startTime := time.Now()
 			sseServer.SSEHandler().ServeHTTP(w, r)
@@ -777,8 +710,17 @@ func runServer(cmd *cobra.Command, args []string) {
 		})
 
 		// Apply authentication middleware and metrics middleware to SSE and message endpoints
-		mux.Handle(sseEndpoint, metrics.HTTPMetricsMiddleware(authMiddleware(cfg.Server.Token)(sseHandler), serverMode))
-		mux.Handle(messageEndpoint, metrics.HTTPMetricsMiddleware(authMiddleware(cfg.Server.Token)(messageHandler), serverMode))
+		if serveSSE {
+			mux.Handle(sseEndpoint, metrics.HTTPMetricsMiddleware(authMiddleware(serverToken, tokens, oauth)(drain.rejectNewSessions(sseHandler)), serverMode))
+			mux.Handle(messageEndpoint, metrics.HTTPMetricsMiddleware(authMiddleware(serverToken, tokens, oauth)(drain.rejectNewSessions(messageHandler)), serverMode))
+		}
+
+		// Module instances are shared by every request and swapped on reload
+		serverCache := newMCPServerCache(moduleSet,
+			streamableHTTPOptions(cfg.Server.StreamableHTTP),
+			serverOptions...,
+		)
+		reloader.onReload(serverCache.reload)
 
 		// Create a custom MCP handler that can parse query parameters
 		mcpHandler := func(w http.ResponseWriter, r *http.Request) {
@@ -800,151 +742,20 @@ func runServer(cmd *cobra.Command, args []string) {
 			// Parse query parameters to determine enabled modules
 			enabledModules := parseEnabledModules(r.URL.RawQuery)
 
-			// Create a new MCP server instance for this request
-			requestMCPServer := server.NewMCPServer("ops-mcp-server", version.BuildVersion)
-
-			// Register modules based on query parameters
-			var toolCount int
-			var enabledTools []string
-
-			if enabledModules["sops"] && cfg.Sops.Enabled {
-				sopsConfig := &sopsModule.Config{
-					Tools: sopsModule.ToolsConfig{
-						Prefix: cfg.Sops.Tools.Prefix,
-						Suffix: cfg.Sops.Tools.Suffix,
-					},
-				}
-				if cfg.Sops.Ops != nil {
-					sopsConfig.Endpoint = cfg.Sops.Ops.Endpoint
-					sopsConfig.Token = cfg.Sops.Ops.Token
-				}
-				sopsModuleInstance, err := sopsModule.New(sopsConfig, logger)
-				if err == nil {
-					sopsModuleTools := sopsModuleInstance.GetTools()
-					for _, serverTool := range sopsModuleTools {
-						requestMCPServer.AddTool(serverTool.Tool, serverTool.Handler)
-						enabledTools = append(enabledTools, serverTool.Tool.Name)
-						toolCount++
-					}
-				}
-			}
-
-			if enabledModules["events"] && cfg.Events.Enabled {
-				eventsConfig := &eventsModule.Config{
-					PollInterval: 30 * time.Second,
-					Tools: eventsModule.ToolsConfig{
-						Prefix: cfg.Events.Tools.Prefix,
-						Suffix: cfg.Events.Tools.Suffix,
-					},
-				}
-				if cfg.Events.Ops != nil {
-					eventsConfig.Endpoint = cfg.Events.Ops.Endpoint
-					eventsConfig.Token = cfg.Events.Ops.Token
-				}
-				eventsModuleInstance, err := eventsModule.New(eventsConfig, logger)
-				if err == nil {
-					eventsModuleTools := eventsModuleInstance.GetTools()
-					for _, serverTool := range eventsModuleTools {
-						requestMCPServer.AddTool(serverTool.Tool, serverTool.Handler)
-						enabledTools = append(enabledTools, serverTool.Tool.Name)
-						toolCount++
-					}
-				}
-			}
-
-			if enabledModules["metrics"] && cfg.Metrics.Enabled {
-				metricsConfig := &metricsModule.Config{
-					Tools: metricsModule.ToolsConfig{
-						Prefix: cfg.Metrics.Tools.Prefix,
-						Suffix: cfg.Metrics.Tools.Suffix,
-					},
-				}
-				if cfg.Metrics.Prometheus != nil {
-					metricsConfig.Prometheus = &metricsModule.PrometheusConfig{
-						Endpoint: cfg.Metrics.Prometheus.Endpoint,
-						Username: cfg.Metrics.Prometheus.Username,
-						Password: cfg.Metrics.Prometheus.Password,
-						Token:    cfg.Metrics.Prometheus.Token,
-					}
-				}
-				metricsModuleInstance, err := metricsModule.New(metricsConfig, logger)
-				if err == nil {
-					metricsModuleTools := metricsModuleInstance.GetTools()
-					for _, serverTool := range metricsModuleTools {
-						requestMCPServer.AddTool(serverTool.Tool, serverTool.Handler)
-						enabledTools = append(enabledTools, serverTool.Tool.Name)
-						toolCount++
-					}
-				}
-			}
-
-			if enabledModules["logs"] && cfg.Logs.Enabled {
-				logsConfig := &logsModule.Config{
-					Tools: logsModule.ToolsConfig{
-						Prefix: cfg.Logs.Tools.Prefix,
-						Suffix: cfg.Logs.Tools.Suffix,
-					},
-				}
-				if cfg.Logs.Elasticsearch != nil {
-					logsConfig.Elasticsearch = &logsModule.ElasticsearchConfig{
-						Endpoint: cfg.Logs.Elasticsearch.Endpoint,
-						Username: cfg.Logs.Elasticsearch.Username,
-						Password: cfg.Logs.Elasticsearch.Password,
-						APIKey:   cfg.Logs.Elasticsearch.APIKey,
-						Timeout:  cfg.Logs.Elasticsearch.Timeout,
-					}
-				}
-				logsModuleInstance, err := logsModule.New(logsConfig, logger)
-				if err == nil {
-					logsModuleTools := logsModuleInstance.GetTools()
-					for _, serverTool := range logsModuleTools {
-						requestMCPServer.AddTool(serverTool.Tool, serverTool.Handler)
-						enabledTools = append(enabledTools, serverTool.Tool.Name)
-						toolCount++
-					}
-				}
-			}
-
-			if enabledModules["traces"] && cfg.Traces.Enabled {
-				tracesConfig := &tracesModule.Config{
-					Tools: tracesModule.ToolsConfig{
-						Prefix: cfg.Traces.Tools.Prefix,
-						Suffix: cfg.Traces.Tools.Suffix,
-					},
-				}
-				if cfg.Traces.Jaeger != nil {
-					tracesConfig.Endpoint = cfg.Traces.Jaeger.Endpoint
-					tracesConfig.Protocol = "HTTP"
-					tracesConfig.Port = 16686
-					tracesConfig.Timeout = cfg.Traces.Jaeger.Timeout
-				}
-				tracesModuleInstance, err := tracesModule.New(tracesConfig, logger)
-				if err == nil {
-					tracesModuleTools := tracesModuleInstance.GetTools()
-					for _, serverTool := range tracesModuleTools {
-						requestMCPServer.AddTool(serverTool.Tool, serverTool.Handler)
-						enabledTools = append(enabledTools, serverTool.Tool.Name)
-						toolCount++
-					}
-				}
-			}
+			// Reuse the MCP server for this combination of modules
+			cached := serverCache.get(enabledModules)
+			toolCount := len(cached.tools)
 
 			// Log the request with enabled modules
 			logger.Info("MCP request with enabled modules",
 				zap.String("query", r.URL.RawQuery),
-				zap.Strings("enabled_modules", getEnabledModuleNames(enabledModules)),
+				zap.Strings("enabled_modules", cached.modules),
 				zap.Int("tools_count", toolCount),
-				zap.Strings("tools", enabledTools))
-
-			// Create Streamable HTTP MCP server for this request
-			streamableServer := server.NewStreamableHTTPServer(
-				requestMCPServer,
-				server.WithHeartbeatInterval(3*time.Second),
-			)
+				zap.Strings("tools", cached.tools))
 
 			// Serve the request
 			startTime := time.Now()
-			streamableServer.ServeHTTP(w, r)
+			cached.handler.ServeHTTP(w, r)
 
 			// Log request completion in debug mode
 			if logLevel == "debug" {
@@ -953,33 +764,88 @@ func runServer(cmd *cobra.Command, args []string) {
 					zap.String("method", r.Method),
 					zap.String("path", r.URL.Path),
 					zap.Duration("duration", duration),
-					zap.Strings("enabled_modules", getEnabledModuleNames(enabledModules)),
+					zap.Strings("enabled_modules", cached.modules),
 					zap.Int("tools_count", toolCount),
 				)
 			}
 		}
 
 		// Mount MCP handler to the mux with authentication and metrics middleware
-		mux.Handle(mcpURI, metrics.HTTPMetricsMiddleware(authMiddleware(cfg.Server.Token)(http.HandlerFunc(mcpHandler)), serverMode))
+		if serveStreamable {
+			mux.Handle(mcpURI, metrics.HTTPMetricsMiddleware(authMiddleware(serverToken, tokens, oauth)(drain.rejectNewSessions(http.HandlerFunc(mcpHandler))), serverMode))
+		}
 
 		// Add docs endpoint with metrics
-		docsHandler := docs.NewHandler(&cfg, logger)
+		docsHandler := docs.NewHandler(moduleSet, logger)
 		mux.Handle(mcpURI+"/docs", metrics.HTTPMetricsMiddleware(http.HandlerFunc(docsHandler.HandleDocs), serverMode))
 
-		// Start SSE server
-		logger.Info("Starting server in SSE mode with health check",
+		// Start HTTP server
+		logger.Info("Starting HTTP server with health check",
+			zap.String("mode", serverMode),
 			zap.String("address", httpServer.Addr),
-			zap.String("health_endpoint", healthEndpoint),
-			zap.String("mcp_endpoint", mcpURI),
-			zap.String("sse_endpoint", sseEndpoint),
-			zap.String("message_endpoint", messageEndpoint),
-			zap.String("docs_endpoint", mcpURI+"/docs"))
-
-		if err := httpServer.ListenAndServe(); err != nil {
-			logger.Fatal("SSE server failed to start", zap.Error(err))
+			zap.Any("endpoints", endpoints))
+
+		// Serve TLS when a certificate is configured, reloading it from disk on rotation
+		var certs *certReloader
+		if cfg.Server.TLS.CertFile != "" || cfg.Server.TLS.KeyFile != "" {
+			certs, err = newCertReloader(cfg.Server.TLS, logger)
+			if err != nil {
+				logger.Fatal("Failed to load TLS certificates", zap.Error(err))
+			}
+			httpServer.TLSConfig = certs.tlsConfig()
+			go certs.watch(streamsCtx.Done())
+			logger.Info("TLS enabled",
+				zap.String("cert_file", cfg.Server.TLS.CertFile),
+				zap.Bool("client_ca", cfg.Server.TLS.ClientCAFile != ""),
+				zap.String("client_identity", cfg.Server.TLS.ClientIdentity))
+		}
+
+		serveErr := make(chan error, 1)
+		go func() {
+			if certs != nil {
+				serveErr <- httpServer.ListenAndServeTLS("", "")
+				return
+			}
+			serveErr <- httpServer.ListenAndServe()
+		}()
+
+		signals := make(chan os.Signal, 2)
+		signal.Notify(signals, syscall.SIGTERM, syscall.SIGINT)
+
+		select {
+		case err := <-serveErr:
+			logger.Fatal("HTTP server failed to start", zap.Error(err))
+		case sig := <-signals:
+			gracePeriod := defaultShutdownGracePeriod
+			if cfg.Server.ShutdownGracePeriod > 0 {
+				gracePeriod = time.Duration(cfg.Server.ShutdownGracePeriod) * time.Second
+			}
+			drainDelay := defaultDrainDelay
+			if cfg.Server.DrainDelay >= 0 {
+				drainDelay = time.Duration(cfg.Server.DrainDelay) * time.Second
+			}
+			logger.Info("Received shutdown signal, draining",
+				zap.String("signal", sig.String()),
+				zap.Duration("grace_period", gracePeriod))
+
+			ctx, cancel := context.WithTimeout(context.Background(), gracePeriod)
+			defer cancel()
+
+			// A second signal skips the remaining grace period
+			go func() {
+				<-signals
+				logger.Warn("Received second shutdown signal, closing connections")
+				cancel()
+			}()
+
+			mcpServers := append([]*server.MCPServer{mcpServer}, serverCache.mcpServers()...)
+			if err := drain.shutdown(ctx, httpServer, drainDelay, mcpServers, closeStreams); err != nil {
+				logger.Warn("Server did not drain within the grace period", zap.Error(err))
+			}
+			logger.Info("Server stopped")
 		}
 	default:
-		logger.Fatal("Invalid server mode", zap.String("mode", serverMode), zap.Strings("valid_modes", []string{"stdio", "sse"}))
+		logger.Fatal("Invalid server mode", zap.String("mode", serverMode), zap.Strings("valid_modes", []string{"stdio", "sse", "streamable-http", "both"}))
 	}
 }
 
@@ -994,15 +860,30 @@ func getHeaderStrings(headers http.Header) []string {
 	return headerStrings
 }
 
-// authMiddleware creates an authentication middleware that validates the server token
-func authMiddleware(expectedToken string) func(http.Handler) http.Handler {
+// authMiddleware creates an authentication middleware that validates the server token,
+// the named tokens of the auth token table and OAuth access tokens, storing the caller
+// identity in the request context
+func authMiddleware(serverToken func() string, tokens *auth.TokenStore, oauth *auth.OAuthValidator) func(http.Handler) http.Handler {
 	return func(next http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 			start := time.Now()
-			
+			expectedToken := serverToken()
+
+			// Point OAuth clients at the protected resource metadata on failures
+			unauthorized := func(message string, err error) {
+				status := http.StatusUnauthorized
+				if oauth != nil {
+					w.Header().Set("WWW-Authenticate", oauth.Challenge(r, err))
+					if errors.Is(err, auth.ErrInsufficientScope) {
+						status = http.StatusForbidden
+					}
+				}
+				http.Error(w, message, status)
+			}
+
 			// Skip authentication if no token is configured
-			if expectedToken == "" {
-				metrics.RecordAuthRequest(true, true)
+			if expectedToken == "" && tokens == nil && oauth == nil {
+				metrics.RecordAuthRequest(true, true, "")
 				next.ServeHTTP(w, r)
 				return
 			}
@@ -1010,41 +891,73 @@ func authMiddleware(expectedToken string) func(http.Handler) http.Handler {
 			// Get token from Authorization header (Bearer token)
 			authHeader := r.Header.Get("Authorization")
 			if authHeader == "" {
-				metrics.RecordAuthRequest(false, false)
+				metrics.RecordAuthRequest(false, false, "")
 				metrics.RecordAuthValidationDuration(time.Since(start))
-				http.Error(w, "Authorization header required", http.StatusUnauthorized)
+				unauthorized("Authorization header required", nil)
 				return
 			}
 
 			// Check for Bearer token format
 			if !strings.HasPrefix(authHeader, "Bearer ") {
-				metrics.RecordAuthRequest(false, false)
+				metrics.RecordAuthRequest(false, false, "")
 				metrics.RecordAuthValidationDuration(time.Since(start))
-				http.Error(w, "Invalid authorization format. Expected 'Bearer <token>'", http.StatusUnauthorized)
+				unauthorized("Invalid authorization format. Expected 'Bearer <token>'", nil)
 				return
 			}
 
 			// Extract token
 			token := strings.TrimPrefix(authHeader, "Bearer ")
 			if token == "" {
-				metrics.RecordAuthRequest(false, false)
+				metrics.RecordAuthRequest(false, false, "")
 				metrics.RecordAuthValidationDuration(time.Since(start))
-				http.Error(w, "Token required", http.StatusUnauthorized)
+				unauthorized("Token required", nil)
 				return
 			}
 
-			// Validate token
-			if token != expectedToken {
-				metrics.RecordAuthRequest(false, false)
+			// Validate token, the server token has no scope restrictions
+			var identity *auth.Identity
+			if expectedToken != "" && subtle.ConstantTimeCompare([]byte(token), []byte(expectedToken)) == 1 {
+				identity = &auth.Identity{Name: "server-token", Source: auth.SourceToken}
+			} else if tokens != nil {
+				named, err := tokens.Authenticate(token)
+				if errors.Is(err, auth.ErrExpiredToken) {
+					metrics.RecordAuthRequest(false, false, named.Name)
+					metrics.RecordAuthValidationDuration(time.Since(start))
+					unauthorized("Token expired", err)
+					return
+				}
+				if err == nil {
+					identity = named.Identity()
+				}
+			}
+
+			// Anything else must be an OAuth access token
+			tokenLabel := ""
+			if identity != nil {
+				tokenLabel = identity.Name
+			} else if oauth != nil {
+				tokenLabel = auth.SourceOAuth
+				var err error
+				identity, err = oauth.Validate(r.Context(), token)
+				if err != nil {
+					metrics.RecordAuthRequest(false, false, tokenLabel)
+					metrics.RecordAuthValidationDuration(time.Since(start))
+					logger.Debug("OAuth access token rejected", zap.Error(err))
+					unauthorized("Invalid token", err)
+					return
+				}
+			}
+			if identity == nil {
+				metrics.RecordAuthRequest(false, false, "")
 				metrics.RecordAuthValidationDuration(time.Since(start))
-				http.Error(w, "Invalid token", http.StatusUnauthorized)
+				unauthorized("Invalid token", auth.ErrInvalidToken)
 				return
 			}
 
 			// Token is valid, proceed to next handler
-			metrics.RecordAuthRequest(true, false)
+			metrics.RecordAuthRequest(true, false, tokenLabel)
 			metrics.RecordAuthValidationDuration(time.Since(start))
-			next.ServeHTTP(w, r)
+			next.ServeHTTP(w, r.WithContext(auth.WithIdentity(r.Context(), identity)))
 		})
 	}
 }
@@ -0,0 +1,193 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"os/signal"
+	"reflect"
+	"sync"
+	"sync/atomic"
+	"syscall"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+	"go.uber.org/zap"
+
+	"github.com/shaowenchen/ops-mcp-server/pkg/config"
+	"github.com/shaowenchen/ops-mcp-server/pkg/metrics"
+	"github.com/shaowenchen/ops-mcp-server/pkg/modules"
+)
+
+// reloadDebounce is how long the config file must stay unchanged before it is reloaded
+const reloadDebounce = 500 * time.Millisecond
+
+// configReloader re-reads the configuration when the config file or a secret
+// file changes, or on SIGHUP. Valid configurations replace the module instances, invalid ones
+// are logged and the running instances keep serving. Viper is not safe for
+// concurrent use, once watch runs it is only accessed from its goroutine.
+type configReloader struct {
+	cmd        *cobra.Command
+	modules    *modules.Set
+	logger     *zap.Logger
+	configFile string
+	current    atomic.Pointer[config.Config]
+
+	mu     sync.Mutex
+	onSwap []func()
+}
+
+// newConfigReloader creates a reloader for the configuration cfg the modules were built from
+func newConfigReloader(cmd *cobra.Command, cfg *config.Config, set *modules.Set, logger *zap.Logger) *configReloader {
+	r := &configReloader{cmd: cmd, modules: set, logger: logger, configFile: viper.ConfigFileUsed()}
+	r.current.Store(cfg)
+	return r
+}
+
+// config returns the configuration currently in effect
+func (r *configReloader) config() *config.Config {
+	return r.current.Load()
+}
+
+// onReload registers fn to run after the module instances were swapped
+func (r *configReloader) onReload(fn func()) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.onSwap = append(r.onSwap, fn)
+}
+
+// watch reloads on SIGHUP and, unless disabled, on changes of the config
+// file and of the files secrets are read from
+func (r *configReloader) watch() {
+	files := &fileWatcher{}
+	if r.config().Server.WatchConfig {
+		files = newFileWatcher(r.logger)
+		files.update(r.watchedFiles())
+	}
+
+	hangups := make(chan os.Signal, 1)
+	signal.Notify(hangups, syscall.SIGHUP)
+
+	// Editors and ConfigMap updates write in several steps, wait for the
+	// file to settle instead of loading a truncated version
+	debounce := time.NewTimer(time.Hour)
+	debounce.Stop()
+	changed := ""
+	for {
+		select {
+		case event := <-files.events():
+			if files.affects(event) {
+				changed = event.Name
+				debounce.Reset(reloadDebounce)
+			}
+		case err := <-files.errors():
+			r.logger.Warn("File watch failed", zap.Error(err))
+		case <-debounce.C:
+			r.logger.Info("Watched file changed, reloading configuration", zap.String("file", changed))
+			r.reloadFile()
+			files.update(r.watchedFiles())
+		case <-hangups:
+			r.logger.Info("Received SIGHUP, reloading configuration")
+			r.reloadFile()
+			files.update(r.watchedFiles())
+		}
+	}
+}
+
+// watchedFiles returns the config file and the files secrets are read from
+func (r *configReloader) watchedFiles() []string {
+	files := r.config().SecretFiles()
+	if r.configFile != "" {
+		files = append(files, r.configFile)
+	}
+	return files
+}
+
+// reloadFile re-reads the config file and reloads from it, keeping the
+// running configuration when the file cannot be parsed
+func (r *configReloader) reloadFile() {
+	if err := viper.ReadInConfig(); err != nil {
+		metrics.RecordConfigReload(false)
+		r.logger.Error("Failed to read config file, keeping the running configuration", zap.Error(err))
+		return
+	}
+	if err := r.reload(); err != nil {
+		r.logger.Error("Failed to reload configuration, keeping the running configuration", zap.Error(err))
+	}
+}
+
+// reload builds the modules from the configuration viper holds and swaps
+// them in. Modules whose config section did not change keep their instance,
+// so a backend that is down only fails the reload when its module changed.
+// Settings that are only read at startup are reported when changed.
+func (r *configReloader) reload() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	cfg, err := loadConfig(r.cmd)
+	if err != nil {
+		metrics.RecordConfigReload(false)
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+	if err := cfg.Validate(); err != nil {
+		metrics.RecordConfigReload(false)
+		return err
+	}
+	previous := r.config()
+	instances, err := modules.Rebuild(r.modules.Instances(), previous, cfg, r.logger)
+	if err != nil {
+		metrics.RecordConfigReload(false)
+		return err
+	}
+
+	for _, section := range restartRequired(previous, cfg) {
+		r.logger.Warn("Configuration change requires a restart to take effect", zap.String("section", section))
+	}
+
+	r.modules.Swap(instances)
+	r.current.Store(cfg)
+	for _, fn := range r.onSwap {
+		fn()
+	}
+
+	var names, tools []string
+	for _, instance := range instances {
+		names = append(names, instance.Name)
+		tools = append(tools, instance.ToolNames()...)
+	}
+	for _, f := range modules.All() {
+		metrics.Get().SetModuleEnabled(f.Name, *f.Enabled(cfg))
+	}
+	metrics.RecordConfigReload(true)
+	r.logger.Info("Configuration reloaded",
+		zap.Strings("modules", names),
+		zap.Int("total_tools", len(tools)),
+		zap.Strings("tools", tools))
+	return nil
+}
+
+// restartRequired returns the config sections that changed between previous
+// and next but are only read at startup
+func restartRequired(previous, next *config.Config) []string {
+	var sections []string
+	// The server token is read per request and may change with its file
+	previousServer, nextServer := previous.Server, next.Server
+	previousServer.Token, previousServer.TokenFile = "", ""
+	nextServer.Token, nextServer.TokenFile = "", ""
+	if !reflect.DeepEqual(previousServer, nextServer) || !reflect.DeepEqual(previous.SSE, next.SSE) {
+		sections = append(sections, "server")
+	}
+	if !reflect.DeepEqual(previous.Auth, next.Auth) {
+		sections = append(sections, "auth")
+	}
+	if !reflect.DeepEqual(previous.Limits, next.Limits) {
+		sections = append(sections, "limits")
+	}
+	if !reflect.DeepEqual(previous.Audit, next.Audit) {
+		sections = append(sections, "audit")
+	}
+	if !reflect.DeepEqual(previous.Log, next.Log) {
+		sections = append(sections, "log")
+	}
+	return sections
+}
@@ -0,0 +1,282 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+	"github.com/shaowenchen/ops-mcp-server/cmd/version"
+	"github.com/shaowenchen/ops-mcp-server/pkg/auth"
+	"github.com/shaowenchen/ops-mcp-server/pkg/config"
+	"github.com/shaowenchen/ops-mcp-server/pkg/modules"
+	"github.com/shaowenchen/ops-mcp-server/pkg/prompts"
+)
+
+// mcpServerCache memoizes one Streamable HTTP server per combination of
+// enabled modules, so module instances and MCP sessions are shared across
+// requests instead of being rebuilt for every HTTP call
+type mcpServerCache struct {
+	mu        sync.Mutex
+	modules   *modules.Set
+	options   []server.ServerOption
+	transport []server.StreamableHTTPOption
+	servers   map[string]*cachedMCPServer
+}
+
+// cachedMCPServer is a Streamable HTTP server exposing a subset of modules
+type cachedMCPServer struct {
+	server    *server.MCPServer
+	handler   *server.StreamableHTTPServer
+	resources *registeredResources
+	prompts   []string
+	enabled   map[string]bool
+	modules   []string
+	tools     []string
+}
+
+// newMCPServerCache creates a cache serving tools from the current module instances
+// over Streamable HTTP servers built with the transport options
+func newMCPServerCache(set *modules.Set, transport []server.StreamableHTTPOption, options ...server.ServerOption) *mcpServerCache {
+	return &mcpServerCache{
+		modules:   set,
+		options:   options,
+		transport: transport,
+		servers:   make(map[string]*cachedMCPServer),
+	}
+}
+
+// get returns the server for the enabled modules, creating it on first use.
+// The returned value is a snapshot, its module and tool lists change on reload.
+func (c *mcpServerCache) get(enabled map[string]bool) cachedMCPServer {
+	// Keyed by the requested modules rather than the instances serving them,
+	// so sessions keep their server when a reload enables or disables modules
+	requested := make(map[string]bool)
+	var names []string
+	for _, name := range modules.Names() {
+		if enabled[name] {
+			requested[name] = true
+			names = append(names, name)
+		}
+	}
+	key := strings.Join(names, ",")
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if cached, ok := c.servers[key]; ok {
+		return *cached
+	}
+
+	mcpServer := server.NewMCPServer("ops-mcp-server", version.BuildVersion, slices.Concat(c.options, completionOptions(c.modules, requested))...)
+	cached := &cachedMCPServer{
+		server:    mcpServer,
+		handler:   server.NewStreamableHTTPServer(mcpServer, c.transport...),
+		resources: newRegisteredResources(),
+		enabled:   requested,
+	}
+	c.sync(cached)
+	c.servers[key] = cached
+	return *cached
+}
+
+// reload updates the tools and resources of every cached server after the
+// module set was swapped, notifying sessions of the changes
+func (c *mcpServerCache) reload() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for _, cached := range c.servers {
+		c.sync(cached)
+	}
+}
+
+// sync brings the tools, resources, prompts and module names of cached up to date
+func (c *mcpServerCache) sync(cached *cachedMCPServer) {
+	syncTools(cached.server, c.modules.Tools(cached.enabled))
+	cached.resources.sync(cached.server, c.modules.Resources(cached.enabled), c.modules.ResourceTemplates(cached.enabled))
+	cached.prompts = syncPrompts(cached.server, cached.prompts, prompts.Build(promptToolLookup(c.modules, cached.enabled)))
+
+	cached.modules = nil
+	cached.tools = nil
+	for _, instance := range c.modules.Instances() {
+		if cached.enabled[instance.Name] {
+			cached.modules = append(cached.modules, instance.Name)
+			cached.tools = append(cached.tools, instance.ToolNames()...)
+		}
+	}
+}
+
+// mcpServers returns every MCP server created so far
+func (c *mcpServerCache) mcpServers() []*server.MCPServer {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	servers := make([]*server.MCPServer, 0, len(c.servers))
+	for _, cached := range c.servers {
+		servers = append(servers, cached.server)
+	}
+	return servers
+}
+
+// syncTools registers tools on mcpServer, replacing changed definitions and
+// removing tools that are gone. mcp-go sends notifications/tools/list_changed
+// to connected sessions only when something was added, changed or removed.
+func syncTools(mcpServer *server.MCPServer, tools []server.ServerTool) {
+	registered := mcpServer.ListTools()
+
+	var changed []server.ServerTool
+	keep := make(map[string]bool, len(tools))
+	for _, tool := range tools {
+		keep[tool.Tool.Name] = true
+		existing, ok := registered[tool.Tool.Name]
+		if !ok || !sameDefinition(existing.Tool, tool.Tool) {
+			changed = append(changed, tool)
+		}
+	}
+	var removed []string
+	for name := range registered {
+		if !keep[name] {
+			removed = append(removed, name)
+		}
+	}
+
+	if len(changed) > 0 {
+		mcpServer.AddTools(changed...)
+	}
+	if len(removed) > 0 {
+		mcpServer.DeleteTools(removed...)
+	}
+}
+
+// sameDefinition reports whether two tool or resource definitions marshal to the same JSON
+func sameDefinition(a, b any) bool {
+	aJSON, errA := json.Marshal(a)
+	bJSON, errB := json.Marshal(b)
+	return errA == nil && errB == nil && bytes.Equal(aJSON, bJSON)
+}
+
+// syncPrompts registers prompts on mcpServer when their names differ from the
+// registered ones, which notifies notifications/prompts/list_changed, and
+// returns the names now registered. Prompt definitions never change, only
+// which workflows have tools available.
+func syncPrompts(mcpServer *server.MCPServer, registered []string, prompts []server.ServerPrompt) []string {
+	names := make([]string, 0, len(prompts))
+	for _, prompt := range prompts {
+		names = append(names, prompt.Prompt.Name)
+	}
+	if slices.Equal(names, registered) {
+		return registered
+	}
+	mcpServer.SetPrompts(prompts...)
+	return names
+}
+
+// promptToolLookup resolves the tool names prompts refer to from the current
+// instances of the enabled modules, hiding tools outside the scope of the caller
+func promptToolLookup(set *modules.Set, enabled map[string]bool) prompts.ToolLookup {
+	return func(ctx context.Context, module, tool string) string {
+		name := set.ToolName(enabled, module, tool)
+		if identity, ok := auth.IdentityFromContext(ctx); ok && name != "" && !identity.Allows(module, name) {
+			return ""
+		}
+		return name
+	}
+}
+
+// registeredResources remembers the resources and templates registered on an
+// MCP server, which mcp-go has no accessor for
+type registeredResources struct {
+	resources map[string]mcp.Resource
+	templates map[string]mcp.ResourceTemplate
+}
+
+// newRegisteredResources creates an empty registry
+func newRegisteredResources() *registeredResources {
+	return &registeredResources{
+		resources: make(map[string]mcp.Resource),
+		templates: make(map[string]mcp.ResourceTemplate),
+	}
+}
+
+// sync registers resources and templates on mcpServer, replacing changed
+// definitions and removing those that are gone, which notifies
+// notifications/resources/list_changed. Resources that stay are announced
+// with notifications/resources/updated, a reload may have pointed them at
+// another backend.
+func (r *registeredResources) sync(mcpServer *server.MCPServer, resources []server.ServerResource, templates []server.ServerResourceTemplate) {
+	var changed []server.ServerResource
+	var unchanged []string
+	keep := make(map[string]bool, len(resources))
+	for _, resource := range resources {
+		keep[resource.Resource.URI] = true
+		existing, ok := r.resources[resource.Resource.URI]
+		if ok && sameDefinition(existing, resource.Resource) {
+			unchanged = append(unchanged, resource.Resource.URI)
+		} else {
+			changed = append(changed, resource)
+		}
+		r.resources[resource.Resource.URI] = resource.Resource
+	}
+	var removed []string
+	for uri := range r.resources {
+		if !keep[uri] {
+			removed = append(removed, uri)
+			delete(r.resources, uri)
+		}
+	}
+
+	if len(changed) > 0 {
+		mcpServer.AddResources(changed...)
+	}
+	if len(removed) > 0 {
+		mcpServer.DeleteResources(removed...)
+	}
+	for _, uri := range unchanged {
+		mcpServer.SendNotificationToAllClients(mcp.MethodNotificationResourceUpdated, map[string]any{"uri": uri})
+	}
+
+	// Templates cannot be deleted one by one, replace them all when any changed
+	next := make(map[string]mcp.ResourceTemplate, len(templates))
+	for _, template := range templates {
+		next[template.Template.URITemplate.Raw()] = template.Template
+	}
+	same := len(next) == len(r.templates)
+	for raw, template := range next {
+		if existing, ok := r.templates[raw]; !ok || !sameDefinition(existing, template) {
+			same = false
+		}
+	}
+	if !same {
+		mcpServer.SetResourceTemplates(templates...)
+		r.templates = next
+	}
+}
+
+// streamableHTTPOptions converts the Streamable HTTP configuration into server options
+func streamableHTTPOptions(cfg config.StreamableHTTPConfig) []server.StreamableHTTPOption {
+	heartbeat := 3 * time.Second
+	if cfg.HeartbeatInterval > 0 {
+		heartbeat = time.Duration(cfg.HeartbeatInterval) * time.Second
+	}
+	options := []server.StreamableHTTPOption{
+		server.WithHeartbeatInterval(heartbeat),
+		server.WithDisableStreaming(cfg.DisableStreaming),
+	}
+	// Sessions are validated against this process unless running stateless,
+	// which needs sticky sessions when running several replicas
+	if cfg.Stateless {
+		options = append(options, server.WithStateLess(true))
+	} else {
+		options = append(options, server.WithStateful(true))
+	}
+	if cfg.SessionIdleTTL > 0 {
+		options = append(options, server.WithSessionIdleTTL(time.Duration(cfg.SessionIdleTTL)*time.Second))
+	}
+	return options
+}
@@ -0,0 +1,109 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"sync/atomic"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+	"go.uber.org/zap"
+)
+
+const (
+	defaultShutdownGracePeriod = 25 * time.Second
+	defaultDrainDelay          = 5 * time.Second
+)
+
+// drainer tracks in-flight tool calls and the draining state shared by
+// /healthz and the session endpoints during graceful shutdown
+type drainer struct {
+	draining atomic.Bool
+	inflight atomic.Int64
+	logger   *zap.Logger
+}
+
+// newDrainer creates a drainer in the serving state
+func newDrainer(logger *zap.Logger) *drainer {
+	return &drainer{logger: logger}
+}
+
+// isDraining reports whether shutdown has started
+func (d *drainer) isDraining() bool {
+	return d.draining.Load()
+}
+
+// toolMiddleware counts in-flight tool calls so shutdown can wait for them
+func (d *drainer) toolMiddleware(next server.ToolHandlerFunc) server.ToolHandlerFunc {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		d.inflight.Add(1)
+		defer d.inflight.Add(-1)
+		return next(ctx, request)
+	}
+}
+
+// rejectNewSessions refuses requests that would open a new session once
+// draining has started, existing sessions keep working until they are closed
+func (d *drainer) rejectNewSessions(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if d.isDraining() && r.Header.Get(server.HeaderKeySessionID) == "" && r.URL.Query().Get("sessionId") == "" {
+			w.Header().Set("Connection", "close")
+			http.Error(w, "Server is shutting down", http.StatusServiceUnavailable)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// shutdown drains httpServer: it flips /healthz to draining, waits drainDelay
+// so load balancers stop routing new sessions here, notifies open sessions,
+// waits for in-flight tool calls and finally closes streams and connections.
+// Everything happens within ctx, once it expires remaining connections are closed.
+func (d *drainer) shutdown(ctx context.Context, httpServer *http.Server, drainDelay time.Duration, mcpServers []*server.MCPServer, closeStreams context.CancelFunc) error {
+	d.draining.Store(true)
+	d.logger.Info("Draining server", zap.Duration("drain_delay", drainDelay))
+
+	select {
+	case <-time.After(drainDelay):
+	case <-ctx.Done():
+	}
+
+	for _, mcpServer := range mcpServers {
+		mcpServer.SendNotificationToAllClients("notifications/message", map[string]any{
+			"level":  mcp.LoggingLevelWarning,
+			"logger": "ops-mcp-server",
+			"data":   "server is shutting down, reconnect to continue",
+		})
+	}
+
+	if err := d.waitIdle(ctx); err != nil {
+		d.logger.Warn("Grace period expired with tool calls in flight", zap.Int64("inflight", d.inflight.Load()))
+	}
+
+	// Long-lived SSE and streamable GET streams never become idle on their
+	// own, cancelling their base context ends them so Shutdown can complete
+	closeStreams()
+
+	if err := httpServer.Shutdown(ctx); err != nil {
+		httpServer.Close()
+		return err
+	}
+	return nil
+}
+
+// waitIdle blocks until no tool call is in flight or ctx expires
+func (d *drainer) waitIdle(ctx context.Context) error {
+	ticker := time.NewTicker(100 * time.Millisecond)
+	defer ticker.Stop()
+
+	for d.inflight.Load() > 0 {
+		select {
+		case <-ticker.C:
+		case <-ctx.Done():
+			return errors.New("timed out waiting for in-flight tool calls")
+		}
+	}
+	return nil
+}
@@ -0,0 +1,148 @@
+package main
+
+import (
+	"bytes"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/shaowenchen/ops-mcp-server/pkg/config"
+	"go.uber.org/zap"
+)
+
+const defaultTLSReloadInterval = 30 * time.Second
+
+// certReloader serves the TLS certificate and client CA pool from disk and
+// reloads them when the files change, so rotated certificates are picked up
+// without a restart
+type certReloader struct {
+	cfg    config.TLSConfig
+	logger *zap.Logger
+
+	mu       sync.RWMutex
+	cert     *tls.Certificate
+	clientCA *x509.CertPool
+	raw      [][]byte
+}
+
+// newCertReloader loads the configured certificate, key and client CA
+func newCertReloader(cfg config.TLSConfig, logger *zap.Logger) (*certReloader, error) {
+	if cfg.CertFile == "" || cfg.KeyFile == "" {
+		return nil, errors.New("tls requires both cert_file and key_file")
+	}
+	if cfg.ClientAuth != "" && cfg.ClientAuth != "require" && cfg.ClientAuth != "optional" {
+		return nil, fmt.Errorf("invalid tls client_auth %q, expected require or optional", cfg.ClientAuth)
+	}
+	r := &certReloader{cfg: cfg, logger: logger}
+	if _, err := r.reload(); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// reload re-reads the files and swaps the certificate and CA pool when their
+// content changed. It reports whether anything was swapped.
+func (r *certReloader) reload() (bool, error) {
+	files := []string{r.cfg.CertFile, r.cfg.KeyFile}
+	if r.cfg.ClientCAFile != "" {
+		files = append(files, r.cfg.ClientCAFile)
+	}
+	raw := make([][]byte, len(files))
+	for i, file := range files {
+		data, err := os.ReadFile(file)
+		if err != nil {
+			return false, fmt.Errorf("failed to read %s: %w", file, err)
+		}
+		raw[i] = data
+	}
+
+	r.mu.RLock()
+	unchanged := len(r.raw) == len(raw)
+	for i := 0; unchanged && i < len(raw); i++ {
+		unchanged = bytes.Equal(r.raw[i], raw[i])
+	}
+	r.mu.RUnlock()
+	if unchanged {
+		return false, nil
+	}
+
+	cert, err := tls.X509KeyPair(raw[0], raw[1])
+	if err != nil {
+		return false, fmt.Errorf("failed to load tls key pair: %w", err)
+	}
+	var clientCA *x509.CertPool
+	if r.cfg.ClientCAFile != "" {
+		clientCA = x509.NewCertPool()
+		if !clientCA.AppendCertsFromPEM(raw[2]) {
+			return false, fmt.Errorf("no certificates found in %s", r.cfg.ClientCAFile)
+		}
+	}
+
+	r.mu.Lock()
+	r.cert = &cert
+	r.clientCA = clientCA
+	r.raw = raw
+	r.mu.Unlock()
+	return true, nil
+}
+
+// watch reloads the files every interval until stop is closed. Failed reloads
+// keep serving the previous certificate.
+func (r *certReloader) watch(stop <-chan struct{}) {
+	interval := defaultTLSReloadInterval
+	if r.cfg.ReloadInterval > 0 {
+		interval = time.Duration(r.cfg.ReloadInterval) * time.Second
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			changed, err := r.reload()
+			if err != nil {
+				r.logger.Error("Failed to reload TLS certificates, keeping previous ones", zap.Error(err))
+			} else if changed {
+				r.logger.Info("Reloaded TLS certificates", zap.String("cert_file", r.cfg.CertFile))
+			}
+		case <-stop:
+			return
+		}
+	}
+}
+
+// clientAuth returns the client certificate policy. A client CA enables
+// verification, client_auth optional only verifies certificates that are sent.
+func (r *certReloader) clientAuth() tls.ClientAuthType {
+	if r.cfg.ClientCAFile == "" {
+		return tls.NoClientCert
+	}
+	if r.cfg.ClientAuth == "optional" {
+		return tls.VerifyClientCertIfGiven
+	}
+	return tls.RequireAndVerifyClientCert
+}
+
+// tlsConfig returns a server TLS config resolving the current certificate
+// and client CA pool on every handshake
+func (r *certReloader) tlsConfig() *tls.Config {
+	clientAuth := r.clientAuth()
+	return &tls.Config{
+		MinVersion: tls.VersionTLS12,
+		GetConfigForClient: func(*tls.ClientHelloInfo) (*tls.Config, error) {
+			r.mu.RLock()
+			defer r.mu.RUnlock()
+			return &tls.Config{
+				MinVersion:   tls.VersionTLS12,
+				Certificates: []tls.Certificate{*r.cert},
+				ClientCAs:    r.clientCA,
+				ClientAuth:   clientAuth,
+				NextProtos:   []string{"h2", "http/1.1"},
+			}, nil
+		},
+	}
+}
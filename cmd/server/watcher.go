@@ -0,0 +1,101 @@
+package main
+
+import (
+	"path/filepath"
+	"strings"
+
+	"github.com/fsnotify/fsnotify"
+	"go.uber.org/zap"
+)
+
+// fileWatcher watches the config file and the files secrets are read from.
+// Their directories are watched rather than the files, because Kubernetes
+// updates ConfigMap and secret mounts by swapping the ..data symlink, which
+// replaces every file at once.
+type fileWatcher struct {
+	watcher *fsnotify.Watcher
+	logger  *zap.Logger
+	files   map[string]bool
+	dirs    map[string]bool
+}
+
+// newFileWatcher creates a watcher, the configuration is only re-read on
+// SIGHUP when the platform cannot watch files
+func newFileWatcher(logger *zap.Logger) *fileWatcher {
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		logger.Warn("Cannot watch the config and secret files, send SIGHUP to reload the configuration", zap.Error(err))
+	}
+	return &fileWatcher{
+		watcher: watcher,
+		logger:  logger,
+		files:   make(map[string]bool),
+		dirs:    make(map[string]bool),
+	}
+}
+
+// events returns the file events, a nil channel that never delivers
+// anything when there is no watcher
+func (w *fileWatcher) events() <-chan fsnotify.Event {
+	if w.watcher == nil {
+		return nil
+	}
+	return w.watcher.Events
+}
+
+// errors returns the watch errors, a nil channel when there is no watcher
+func (w *fileWatcher) errors() <-chan error {
+	if w.watcher == nil {
+		return nil
+	}
+	return w.watcher.Errors
+}
+
+// update watches the directories of files and stops watching the directories
+// no file is read from anymore
+func (w *fileWatcher) update(files []string) {
+	if w.watcher == nil {
+		return
+	}
+
+	w.files = make(map[string]bool)
+	dirs := make(map[string]bool)
+	for _, file := range files {
+		path, err := filepath.Abs(file)
+		if err != nil {
+			continue
+		}
+		w.files[path] = true
+		dirs[filepath.Dir(path)] = true
+	}
+
+	for dir := range w.dirs {
+		if !dirs[dir] {
+			w.watcher.Remove(dir)
+			delete(w.dirs, dir)
+		}
+	}
+	for dir := range dirs {
+		if w.dirs[dir] {
+			continue
+		}
+		if err := w.watcher.Add(dir); err != nil {
+			w.logger.Warn("Cannot watch directory", zap.String("dir", dir), zap.Error(err))
+			continue
+		}
+		w.dirs[dir] = true
+		w.logger.Info("Watching files for changes", zap.String("dir", dir))
+	}
+}
+
+// affects reports whether event may have changed one of the watched files
+func (w *fileWatcher) affects(event fsnotify.Event) bool {
+	if event.Op == fsnotify.Chmod {
+		return false
+	}
+	if w.files[filepath.Clean(event.Name)] {
+		return true
+	}
+	// Kubernetes swaps the ..data symlink of the mount
+	return strings.HasPrefix(filepath.Base(event.Name), "..")
+}
@@ -0,0 +1,302 @@
+package audit
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+	"go.uber.org/zap"
+
+	"github.com/shaowenchen/ops-mcp-server/pkg/auth"
+	"github.com/shaowenchen/ops-mcp-server/pkg/config"
+	"github.com/shaowenchen/ops-mcp-server/pkg/metrics"
+)
+
+const (
+	redacted              = "[REDACTED]"
+	defaultMaxSizeMB      = 100
+	defaultMaxBackups     = 5
+	defaultMaxValueLength = 1024
+	maxErrorLength        = 512
+)
+
+// defaultRedactKeys are argument names, matched case-insensitively as
+// substrings, whose values never reach the audit log
+var defaultRedactKeys = []string{"password", "passwd", "secret", "token", "api_key", "apikey", "authorization", "credential", "private_key"}
+
+// Record is one audited tool invocation, written as a JSON line
+type Record struct {
+	Time           time.Time              `json:"time"`
+	Identity       string                 `json:"identity,omitempty"`
+	IdentitySource string                 `json:"identity_source,omitempty"`
+	SessionID      string                 `json:"session_id,omitempty"`
+	Tool           string                 `json:"tool"`
+	Module         string                 `json:"module"`
+	Arguments      map[string]interface{} `json:"arguments,omitempty"`
+	// ExecutedArguments are the arguments the call ran with when a tool
+	// changed them, like variables the user supplied through elicitation
+	ExecutedArguments map[string]interface{} `json:"executed_arguments,omitempty"`
+	DurationMs        float64                `json:"duration_ms"`
+	ResultBytes       int                    `json:"result_bytes"`
+	Success           bool                   `json:"success"`
+	ErrorClass        string                 `json:"error_class,omitempty"`
+	Error             string                 `json:"error,omitempty"`
+}
+
+// Logger writes audit records to its sink
+type Logger struct {
+	mu             sync.Mutex
+	out            io.Writer
+	closer         io.Closer
+	redactKeys     []string
+	maxValueLength int
+	logger         *zap.Logger
+}
+
+var defaultLogger *Logger
+
+// Init creates the audit logger from cfg and makes it the default used by
+// Log. It returns nil when auditing is disabled.
+func Init(cfg config.AuditConfig, logger *zap.Logger) (*Logger, error) {
+	if !cfg.Enabled {
+		return nil, nil
+	}
+
+	l := &Logger{
+		redactKeys:     append([]string{}, defaultRedactKeys...),
+		maxValueLength: defaultMaxValueLength,
+		logger:         logger,
+	}
+	for _, key := range cfg.RedactKeys {
+		l.redactKeys = append(l.redactKeys, strings.ToLower(key))
+	}
+	if cfg.MaxValueLength > 0 {
+		l.maxValueLength = cfg.MaxValueLength
+	}
+
+	switch cfg.Output {
+	case "", "stdout":
+		l.out = os.Stdout
+	case "stderr":
+		l.out = os.Stderr
+	default:
+		maxSizeMB := cfg.MaxSizeMB
+		if maxSizeMB <= 0 {
+			maxSizeMB = defaultMaxSizeMB
+		}
+		maxBackups := cfg.MaxBackups
+		if maxBackups <= 0 {
+			maxBackups = defaultMaxBackups
+		}
+		file, err := openRotatingFile(cfg.Output, int64(maxSizeMB)*1024*1024, maxBackups)
+		if err != nil {
+			return nil, err
+		}
+		l.out = file
+		l.closer = file
+	}
+
+	defaultLogger = l
+	logger.Info("Audit log enabled", zap.String("output", cfg.Output))
+	return l, nil
+}
+
+// Get returns the default audit logger, nil when auditing is disabled
+func Get() *Logger {
+	return defaultLogger
+}
+
+// Close closes the audit sink
+func (l *Logger) Close() error {
+	if l == nil || l.closer == nil {
+		return nil
+	}
+	return l.closer.Close()
+}
+
+// Middleware records every tool call in the default audit log, including
+// the calls rejected by the scope and limit middlewares registered after it.
+// moduleOf returns the module exposing a tool.
+func Middleware(moduleOf func(tool string) string) server.ToolHandlerMiddleware {
+	return func(next server.ToolHandlerFunc) server.ToolHandlerFunc {
+		return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			if Get() == nil {
+				return next(ctx, request)
+			}
+			start := time.Now()
+			ctx = context.WithValue(ctx, callKey{}, &call{})
+			result, err := next(ctx, request)
+			tool := request.Params.Name
+			Log(ctx, tool, moduleOf(tool), request, result, err, time.Since(start), errorClass(result, err))
+			return result, err
+		}
+	}
+}
+
+type callKey struct{}
+
+// call holds what a tool reports about its call for the audit record
+type call struct {
+	mu                sync.Mutex
+	executedArguments map[string]interface{}
+}
+
+// SetExecutedArguments records the arguments the tool call of ctx runs with,
+// when they differ from the requested ones. They are audited, redacted, next
+// to the requested arguments.
+func SetExecutedArguments(ctx context.Context, args map[string]interface{}) {
+	c, ok := ctx.Value(callKey{}).(*call)
+	if !ok {
+		return
+	}
+	c.mu.Lock()
+	c.executedArguments = args
+	c.mu.Unlock()
+}
+
+// executedArguments returns the arguments recorded by SetExecutedArguments
+func executedArguments(ctx context.Context) map[string]interface{} {
+	c, ok := ctx.Value(callKey{}).(*call)
+	if !ok {
+		return nil
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.executedArguments
+}
+
+// errorClass categorizes a failed call, empty for successful ones. Calls
+// rejected by a limit are classed by its reason, rate or concurrency.
+func errorClass(result *mcp.CallToolResult, err error) string {
+	if err == nil && (result == nil || !result.IsError) {
+		return ""
+	}
+	if result != nil && result.Meta != nil {
+		if reason, ok := result.Meta.AdditionalFields["limitReason"].(string); ok {
+			return reason + "_limited"
+		}
+	}
+	return metrics.ClassifyError(err)
+}
+
+// Log records a tool invocation with the default audit logger. errorClass
+// is the category assigned by the caller, empty for successful calls.
+func Log(ctx context.Context, toolName, moduleName string, request mcp.CallToolRequest, result *mcp.CallToolResult, err error, duration time.Duration, errorClass string) {
+	l := Get()
+	if l == nil {
+		return
+	}
+
+	record := Record{
+		Time:        time.Now().UTC(),
+		Tool:        toolName,
+		Module:      moduleName,
+		Arguments:   l.redactArguments(request.GetArguments()),
+		DurationMs:  float64(duration.Microseconds()) / 1000,
+		ResultBytes: resultSize(result),
+		Success:     err == nil && (result == nil || !result.IsError),
+		ErrorClass:  errorClass,
+	}
+	if executed := executedArguments(ctx); executed != nil {
+		record.ExecutedArguments = l.redactArguments(executed)
+	}
+	if identity, ok := auth.IdentityFromContext(ctx); ok {
+		record.Identity = identity.Name
+		record.IdentitySource = identity.Source
+	}
+	if session := server.ClientSessionFromContext(ctx); session != nil {
+		record.SessionID = session.SessionID()
+	}
+	if err != nil {
+		record.Error = truncate(err.Error(), maxErrorLength)
+	}
+
+	l.write(record)
+}
+
+// write encodes record as one JSON line
+func (l *Logger) write(record Record) {
+	line, err := json.Marshal(record)
+	if err != nil {
+		l.logger.Error("Failed to encode audit record", zap.String("tool", record.Tool), zap.Error(err))
+		return
+	}
+	line = append(line, '\n')
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if _, err := l.out.Write(line); err != nil {
+		l.logger.Error("Failed to write audit record", zap.String("tool", record.Tool), zap.Error(err))
+	}
+}
+
+// redactArguments returns a copy of args with secrets masked and long
+// strings truncated
+func (l *Logger) redactArguments(args map[string]interface{}) map[string]interface{} {
+	if len(args) == 0 {
+		return nil
+	}
+	return l.redactValue("", args).(map[string]interface{})
+}
+
+// redactValue masks value when key names a secret, recursing into maps and lists
+func (l *Logger) redactValue(key string, value interface{}) interface{} {
+	if key != "" && l.isSecret(key) {
+		return redacted
+	}
+	switch v := value.(type) {
+	case map[string]interface{}:
+		out := make(map[string]interface{}, len(v))
+		for k, item := range v {
+			out[k] = l.redactValue(k, item)
+		}
+		return out
+	case []interface{}:
+		out := make([]interface{}, len(v))
+		for i, item := range v {
+			out[i] = l.redactValue("", item)
+		}
+		return out
+	case string:
+		return truncate(v, l.maxValueLength)
+	default:
+		return v
+	}
+}
+
+// isSecret reports whether an argument name looks like it holds a secret
+func (l *Logger) isSecret(key string) bool {
+	key = strings.ToLower(key)
+	for _, secret := range l.redactKeys {
+		if strings.Contains(key, secret) {
+			return true
+		}
+	}
+	return false
+}
+
+// resultSize returns the size of the JSON encoded result
+func resultSize(result *mcp.CallToolResult) int {
+	if result == nil {
+		return 0
+	}
+	data, err := json.Marshal(result)
+	if err != nil {
+		return 0
+	}
+	return len(data)
+}
+
+// truncate shortens s to max bytes, marking the cut
+func truncate(s string, max int) string {
+	if len(s) <= max {
+		return s
+	}
+	return s[:max] + "...(truncated)"
+}
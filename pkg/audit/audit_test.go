@@ -0,0 +1,107 @@
+package audit
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+	"go.uber.org/zap"
+
+	"github.com/shaowenchen/ops-mcp-server/pkg/auth"
+	"github.com/shaowenchen/ops-mcp-server/pkg/config"
+	"github.com/shaowenchen/ops-mcp-server/pkg/limits"
+)
+
+// captureAudit makes a logger writing to the returned buffer the default
+// for the duration of the test
+func captureAudit(t *testing.T) *bytes.Buffer {
+	t.Helper()
+	var out bytes.Buffer
+	previous := defaultLogger
+	defaultLogger = &Logger{out: &out, redactKeys: defaultRedactKeys, maxValueLength: defaultMaxValueLength, logger: zap.NewNop()}
+	t.Cleanup(func() { defaultLogger = previous })
+	return &out
+}
+
+// chain wraps handler with middlewares, the first one outermost as in the server
+func chain(handler server.ToolHandlerFunc, middlewares ...server.ToolHandlerMiddleware) server.ToolHandlerFunc {
+	for i := len(middlewares) - 1; i >= 0; i-- {
+		handler = middlewares[i](handler)
+	}
+	return handler
+}
+
+func moduleOf(tool string) string {
+	return "logs"
+}
+
+func TestMiddlewareAuditsRejectedCalls(t *testing.T) {
+	limiter, err := limits.New(config.LimitsConfig{Enabled: true, Rules: []config.LimitRuleConfig{{Name: "once", Rate: 0.001, Burst: 1}}})
+	if err != nil {
+		t.Fatalf("limits.New: %v", err)
+	}
+	handler := chain(func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		return mcp.NewToolResultText("ok"), nil
+	}, Middleware(moduleOf), auth.ToolMiddleware(moduleOf), limiter.Middleware(moduleOf))
+
+	// The cases run in order, the limiter allows the first call only
+	tests := []struct {
+		name           string
+		identity       *auth.Identity
+		wantSuccess    bool
+		wantErrorClass string
+	}{
+		{"allowed", &auth.Identity{Name: "sre", Source: auth.SourceToken}, true, ""},
+		{"outside the scope", &auth.Identity{Name: "dev", Source: auth.SourceToken, Modules: map[string]bool{"metrics": true}}, false, "auth_error"},
+		{"rate limited", &auth.Identity{Name: "sre", Source: auth.SourceToken}, false, "rate_limited"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			out := captureAudit(t)
+			var request mcp.CallToolRequest
+			request.Params.Name = "search-logs"
+			request.Params.Arguments = map[string]any{"query": "error", "api_key": "hunter2"}
+			handler(auth.WithIdentity(context.Background(), tt.identity), request)
+
+			var record Record
+			if err := json.Unmarshal(out.Bytes(), &record); err != nil {
+				t.Fatalf("audit record %q: %v", out.String(), err)
+			}
+			if record.Identity != tt.identity.Name || record.Tool != "search-logs" || record.Module != "logs" {
+				t.Errorf("record of %s calling %s of %s, want %s calling search-logs of logs", record.Identity, record.Tool, record.Module, tt.identity.Name)
+			}
+			if record.Success != tt.wantSuccess || record.ErrorClass != tt.wantErrorClass {
+				t.Errorf("record success %v with error class %q, want %v with %q", record.Success, record.ErrorClass, tt.wantSuccess, tt.wantErrorClass)
+			}
+			if record.Arguments["api_key"] != redacted {
+				t.Errorf("api_key audited as %v, want it redacted", record.Arguments["api_key"])
+			}
+		})
+	}
+}
+
+func TestMiddlewareAuditsExecutedArguments(t *testing.T) {
+	out := captureAudit(t)
+	handler := Middleware(moduleOf)(func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		SetExecutedArguments(ctx, map[string]interface{}{"query": "error level:warn", "api_key": "hunter2"})
+		return mcp.NewToolResultText("ok"), nil
+	})
+	var request mcp.CallToolRequest
+	request.Params.Name = "search-logs"
+	request.Params.Arguments = map[string]any{"query": "error"}
+	handler(context.Background(), request)
+
+	var record Record
+	if err := json.Unmarshal(out.Bytes(), &record); err != nil {
+		t.Fatalf("audit record %q: %v", out.String(), err)
+	}
+	if record.Arguments["query"] != "error" {
+		t.Errorf("requested query audited as %v, want error", record.Arguments["query"])
+	}
+	if record.ExecutedArguments["query"] != "error level:warn" || record.ExecutedArguments["api_key"] != redacted {
+		t.Errorf("executed arguments audited as %v, want the query run and the api_key redacted", record.ExecutedArguments)
+	}
+}
@@ -0,0 +1,80 @@
+package auth
+
+import (
+	"context"
+	"crypto/x509"
+	"net/http"
+)
+
+// Identity sources
+const (
+	SourceClientCert = "client-cert"
+	SourceToken      = "token"
+)
+
+// Identity is the authenticated caller of a request
+type Identity struct {
+	// Name identifies the caller in logs, metrics and policies
+	Name string
+	// Source tells how the caller was authenticated
+	Source string
+	// Modules and Tools restrict what the caller may list and call,
+	// nil allows everything
+	Modules map[string]bool
+	Tools   map[string]bool
+}
+
+// Allows reports whether the identity may use tool of module
+func (i *Identity) Allows(module, tool string) bool {
+	if !i.AllowsModule(module) {
+		return false
+	}
+	if i.Tools != nil && !i.Tools[tool] {
+		return false
+	}
+	return true
+}
+
+// AllowsModule reports whether the identity may use module, as resources
+// of a module are not restricted by tool
+func (i *Identity) AllowsModule(module string) bool {
+	return i.Modules == nil || i.Modules[module]
+}
+
+type identityKey struct{}
+
+// WithIdentity returns a copy of ctx carrying identity
+func WithIdentity(ctx context.Context, identity *Identity) context.Context {
+	return context.WithValue(ctx, identityKey{}, identity)
+}
+
+// IdentityFromContext returns the identity stored in ctx, if any
+func IdentityFromContext(ctx context.Context) (*Identity, bool) {
+	identity, ok := ctx.Value(identityKey{}).(*Identity)
+	return identity, ok && identity != nil
+}
+
+// ClientCertIdentity maps the verified client certificate of each request to
+// an identity. mapping is "cn" for the subject common name or "subject" for
+// the full distinguished name, any other value leaves requests untouched.
+func ClientCertIdentity(mapping string, next http.Handler) http.Handler {
+	if mapping != "cn" && mapping != "subject" {
+		return next
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.TLS != nil && len(r.TLS.VerifiedChains) > 0 && len(r.TLS.VerifiedChains[0]) > 0 {
+			if name := subjectName(r.TLS.VerifiedChains[0][0], mapping); name != "" {
+				r = r.WithContext(WithIdentity(r.Context(), &Identity{Name: name, Source: SourceClientCert}))
+			}
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// subjectName returns the part of the certificate subject selected by mapping
+func subjectName(cert *x509.Certificate, mapping string) string {
+	if mapping == "subject" {
+		return cert.Subject.String()
+	}
+	return cert.Subject.CommonName
+}
@@ -0,0 +1,315 @@
+package auth
+
+import (
+	"context"
+	"crypto"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/shaowenchen/ops-mcp-server/pkg/config"
+	"go.uber.org/zap"
+)
+
+// SourceOAuth is the identity source of callers authenticated with an OAuth access token
+const SourceOAuth = "oauth"
+
+const (
+	// WellKnownProtectedResource is the path of the OAuth protected resource metadata (RFC 9728)
+	WellKnownProtectedResource = "/.well-known/oauth-protected-resource"
+
+	defaultJWKSRefreshInterval = time.Hour
+	minJWKSRefreshInterval     = 10 * time.Second
+	clockSkew                  = 30 * time.Second
+)
+
+// ErrInsufficientScope is returned for valid access tokens missing a required scope
+var ErrInsufficientScope = errors.New("insufficient scope")
+
+// OAuthValidator validates JWT access tokens issued by the configured
+// authorization server and serves the protected resource metadata
+type OAuthValidator struct {
+	cfg          config.OAuthConfig
+	metadataPath string
+	logger       *zap.Logger
+	httpClient   *http.Client
+
+	mu          sync.RWMutex
+	keys        map[string]crypto.PublicKey
+	fetchedAt   time.Time
+	lastAttempt time.Time
+}
+
+// NewOAuthValidator creates a validator for cfg. metadataPath is the path the
+// protected resource metadata is served on, it is referenced in challenges.
+func NewOAuthValidator(cfg config.OAuthConfig, metadataPath string, logger *zap.Logger) (*OAuthValidator, error) {
+	if cfg.Issuer == "" {
+		return nil, errors.New("oauth requires issuer")
+	}
+	if cfg.Audience == "" {
+		return nil, errors.New("oauth requires audience")
+	}
+	if cfg.Resource == "" {
+		cfg.Resource = cfg.Audience
+	}
+	if cfg.UsernameClaim == "" {
+		cfg.UsernameClaim = "sub"
+	}
+	if cfg.GroupsClaim == "" {
+		cfg.GroupsClaim = "groups"
+	}
+
+	v := &OAuthValidator{
+		cfg:          cfg,
+		metadataPath: metadataPath,
+		logger:       logger,
+		httpClient:   &http.Client{Timeout: 10 * time.Second},
+	}
+	// The authorization server may come up later, keys are fetched again on demand
+	if err := v.refresh(context.Background()); err != nil {
+		logger.Warn("Failed to fetch JWKS, will retry on first request", zap.String("issuer", cfg.Issuer), zap.Error(err))
+	}
+	return v, nil
+}
+
+// Validate verifies the access token and returns the identity of its subject
+// with modules mapped from the groups claim
+func (v *OAuthValidator) Validate(ctx context.Context, token string) (*Identity, error) {
+	header, claims, signed, signature, err := parseJWT(token)
+	if err != nil {
+		return nil, err
+	}
+	key, err := v.key(ctx, header.Kid)
+	if err != nil {
+		return nil, err
+	}
+	if err := verifySignature(header.Alg, key, signed, signature); err != nil {
+		return nil, fmt.Errorf("invalid signature: %w", err)
+	}
+
+	now := time.Now()
+	if claims.Issuer != v.cfg.Issuer {
+		return nil, fmt.Errorf("unexpected issuer %q", claims.Issuer)
+	}
+	if !contains(claims.Audience, v.cfg.Audience) {
+		return nil, errors.New("token audience does not include this server")
+	}
+	if claims.ExpiresAt.IsZero() || now.After(claims.ExpiresAt.Add(clockSkew)) {
+		return nil, errors.New("token expired")
+	}
+	if !claims.NotBefore.IsZero() && now.Add(clockSkew).Before(claims.NotBefore) {
+		return nil, errors.New("token not valid yet")
+	}
+
+	scopes := stringList(claims.Raw["scp"])
+	if scope, ok := claims.Raw["scope"].(string); ok {
+		scopes = append(scopes, strings.Fields(scope)...)
+	}
+	for _, required := range v.cfg.RequiredScopes {
+		if !contains(scopes, required) {
+			return nil, fmt.Errorf("%w: %s", ErrInsufficientScope, required)
+		}
+	}
+
+	name, _ := claims.Raw[v.cfg.UsernameClaim].(string)
+	if name == "" {
+		name = claims.Subject
+	}
+	identity := &Identity{Name: name, Source: SourceOAuth}
+
+	// Without group mappings every module is allowed, with mappings only the
+	// modules of the caller's groups are
+	if len(v.cfg.Groups) > 0 {
+		groups := stringList(claims.Raw[v.cfg.GroupsClaim])
+		identity.Modules = make(map[string]bool)
+		for _, group := range v.cfg.Groups {
+			if contains(groups, group.Name) {
+				for _, module := range group.Modules {
+					identity.Modules[module] = true
+				}
+			}
+		}
+	}
+	return identity, nil
+}
+
+// Challenge returns the WWW-Authenticate header value for a failed request,
+// pointing clients at the protected resource metadata
+func (v *OAuthValidator) Challenge(r *http.Request, err error) string {
+	scheme := "http"
+	if r.TLS != nil {
+		scheme = "https"
+	}
+	challenge := fmt.Sprintf(`Bearer resource_metadata="%s://%s%s"`, scheme, r.Host, v.metadataPath)
+	switch {
+	case errors.Is(err, ErrInsufficientScope):
+		challenge += fmt.Sprintf(`, error="insufficient_scope", scope="%s"`, strings.Join(v.cfg.RequiredScopes, " "))
+	case err != nil:
+		challenge += `, error="invalid_token"`
+	}
+	return challenge
+}
+
+// MetadataHandler serves the OAuth protected resource metadata
+func (v *OAuthValidator) MetadataHandler() http.Handler {
+	metadata := map[string]interface{}{
+		"resource":                 v.cfg.Resource,
+		"authorization_servers":    []string{v.cfg.Issuer},
+		"bearer_methods_supported": []string{"header"},
+	}
+	if len(v.cfg.RequiredScopes) > 0 {
+		metadata["scopes_supported"] = v.cfg.RequiredScopes
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(metadata)
+	})
+}
+
+// key returns the verification key for kid, refreshing the JWKS when the key
+// is unknown or the cached set is older than the refresh interval
+func (v *OAuthValidator) key(ctx context.Context, kid string) (crypto.PublicKey, error) {
+	refreshInterval := defaultJWKSRefreshInterval
+	if v.cfg.RefreshInterval > 0 {
+		refreshInterval = time.Duration(v.cfg.RefreshInterval) * time.Second
+	}
+
+	key, stale := v.lookup(kid, refreshInterval)
+	if key != nil && !stale {
+		return key, nil
+	}
+	if err := v.refresh(ctx); err != nil {
+		if key != nil {
+			v.logger.Warn("Failed to refresh JWKS, using cached keys", zap.Error(err))
+			return key, nil
+		}
+		return nil, err
+	}
+	if key, _ = v.lookup(kid, refreshInterval); key == nil {
+		return nil, fmt.Errorf("unknown signing key %q", kid)
+	}
+	return key, nil
+}
+
+// lookup returns the cached key for kid and whether the cache is stale. A
+// token without kid matches when the set has a single key.
+func (v *OAuthValidator) lookup(kid string, refreshInterval time.Duration) (crypto.PublicKey, bool) {
+	v.mu.RLock()
+	defer v.mu.RUnlock()
+
+	stale := time.Since(v.fetchedAt) > refreshInterval
+	if key, ok := v.keys[kid]; ok {
+		return key, stale
+	}
+	if kid == "" && len(v.keys) == 1 {
+		for _, key := range v.keys {
+			return key, stale
+		}
+	}
+	return nil, stale
+}
+
+// refresh downloads the JWKS, at most once per minJWKSRefreshInterval
+func (v *OAuthValidator) refresh(ctx context.Context) error {
+	v.mu.Lock()
+	if time.Since(v.lastAttempt) < minJWKSRefreshInterval {
+		v.mu.Unlock()
+		return nil
+	}
+	v.lastAttempt = time.Now()
+	v.mu.Unlock()
+
+	jwksURL := v.cfg.JWKSURL
+	if jwksURL == "" {
+		discovered, err := v.discoverJWKSURL(ctx)
+		if err != nil {
+			return err
+		}
+		jwksURL = discovered
+	}
+
+	var jwks struct {
+		Keys []jwk `json:"keys"`
+	}
+	if err := v.getJSON(ctx, jwksURL, &jwks); err != nil {
+		return fmt.Errorf("failed to fetch jwks: %w", err)
+	}
+
+	keys := make(map[string]crypto.PublicKey, len(jwks.Keys))
+	for _, k := range jwks.Keys {
+		if k.Use != "" && k.Use != "sig" {
+			continue
+		}
+		key, err := k.publicKey()
+		if err != nil {
+			v.logger.Warn("Skipping unusable JWKS key", zap.String("kid", k.Kid), zap.Error(err))
+			continue
+		}
+		keys[k.Kid] = key
+	}
+
+	v.mu.Lock()
+	v.keys = keys
+	v.fetchedAt = time.Now()
+	v.mu.Unlock()
+
+	v.logger.Debug("Fetched JWKS", zap.String("url", jwksURL), zap.Int("keys", len(keys)))
+	return nil
+}
+
+// discoverJWKSURL reads jwks_uri from the issuer's OpenID Connect or OAuth
+// authorization server metadata
+func (v *OAuthValidator) discoverJWKSURL(ctx context.Context) (string, error) {
+	issuer := strings.TrimSuffix(v.cfg.Issuer, "/")
+	var lastErr error
+	for _, path := range []string{"/.well-known/openid-configuration", "/.well-known/oauth-authorization-server"} {
+		var metadata struct {
+			JWKSURI string `json:"jwks_uri"`
+		}
+		if err := v.getJSON(ctx, issuer+path, &metadata); err != nil {
+			lastErr = err
+			continue
+		}
+		if metadata.JWKSURI != "" {
+			return metadata.JWKSURI, nil
+		}
+	}
+	return "", fmt.Errorf("failed to discover jwks_uri from issuer %s: %v", v.cfg.Issuer, lastErr)
+}
+
+// getJSON fetches url and decodes the JSON response into out
+func (v *OAuthValidator) getJSON(ctx context.Context, url string, out interface{}) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "application/json")
+	resp, err := v.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("GET %s returned status %d", url, resp.StatusCode)
+	}
+	return json.NewDecoder(resp.Body).Decode(out)
+}
+
+// contains reports whether list contains value
+func contains(list []string, value string) bool {
+	for _, item := range list {
+		if item == value {
+			return true
+		}
+	}
+	return false
+}
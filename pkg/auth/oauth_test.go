@@ -0,0 +1,491 @@
+package auth
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/shaowenchen/ops-mcp-server/pkg/config"
+	"go.uber.org/zap"
+)
+
+const (
+	testIssuer   = "https://issuer.example.com"
+	testAudience = "https://mcp.example.com"
+)
+
+// testJWKS is an authorization server serving its signing keys from a local
+// JWKS endpoint
+type testJWKS struct {
+	server *httptest.Server
+	rsaKey *rsa.PrivateKey
+	ecKey  *ecdsa.PrivateKey
+
+	mu      sync.Mutex
+	keys    []jwk
+	fetches int
+}
+
+// newTestJWKS starts a JWKS endpoint serving an RSA key "rsa-1" and an EC
+// P-256 key "ec-1"
+func newTestJWKS(t *testing.T) *testJWKS {
+	t.Helper()
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("generate rsa key: %v", err)
+	}
+	ecKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("generate ec key: %v", err)
+	}
+	j := &testJWKS{rsaKey: rsaKey, ecKey: ecKey}
+	j.keys = []jwk{rsaJWK("rsa-1", &rsaKey.PublicKey), ecJWK("ec-1", &ecKey.PublicKey)}
+	j.server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		j.mu.Lock()
+		defer j.mu.Unlock()
+		j.fetches++
+		json.NewEncoder(w).Encode(map[string]interface{}{"keys": j.keys})
+	}))
+	t.Cleanup(j.server.Close)
+	return j
+}
+
+// setKeys replaces the served keys
+func (j *testJWKS) setKeys(keys ...jwk) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	j.keys = keys
+}
+
+// fetchCount returns how many times the JWKS was fetched
+func (j *testJWKS) fetchCount() int {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	return j.fetches
+}
+
+// validator returns a validator of the JWKS, changed by configure
+func (j *testJWKS) validator(t *testing.T, configure func(*config.OAuthConfig)) *OAuthValidator {
+	t.Helper()
+	cfg := config.OAuthConfig{
+		Enabled:  true,
+		Issuer:   testIssuer,
+		JWKSURL:  j.server.URL,
+		Audience: testAudience,
+	}
+	if configure != nil {
+		configure(&cfg)
+	}
+	v, err := NewOAuthValidator(cfg, WellKnownProtectedResource, zap.NewNop())
+	if err != nil {
+		t.Fatalf("NewOAuthValidator: %v", err)
+	}
+	return v
+}
+
+func rsaJWK(kid string, key *rsa.PublicKey) jwk {
+	return jwk{
+		Kty: "RSA",
+		Kid: kid,
+		Use: "sig",
+		N:   base64.RawURLEncoding.EncodeToString(key.N.Bytes()),
+		E:   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(key.E)).Bytes()),
+	}
+}
+
+func ecJWK(kid string, key *ecdsa.PublicKey) jwk {
+	return jwk{
+		Kty: "EC",
+		Kid: kid,
+		Use: "sig",
+		Crv: "P-256",
+		X:   base64.RawURLEncoding.EncodeToString(key.X.FillBytes(make([]byte, 32))),
+		Y:   base64.RawURLEncoding.EncodeToString(key.Y.FillBytes(make([]byte, 32))),
+	}
+}
+
+// validClaims returns the claims of a token accepted by the validator
+func validClaims() map[string]interface{} {
+	now := time.Now()
+	return map[string]interface{}{
+		"iss": testIssuer,
+		"sub": "alice",
+		"aud": []string{testAudience},
+		"iat": now.Unix(),
+		"exp": now.Add(time.Hour).Unix(),
+	}
+}
+
+// encodeSegment returns the base64url encoded JSON of value
+func encodeSegment(t *testing.T, value interface{}) string {
+	t.Helper()
+	data, err := json.Marshal(value)
+	if err != nil {
+		t.Fatalf("marshal %v: %v", value, err)
+	}
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+// sign returns a compact JWT of claims signed with key for alg and kid
+func sign(t *testing.T, alg, kid string, key crypto.Signer, claims map[string]interface{}) string {
+	t.Helper()
+	signed := encodeSegment(t, map[string]string{"alg": alg, "kid": kid, "typ": "JWT"}) + "." + encodeSegment(t, claims)
+	digest := sha256.Sum256([]byte(signed))
+
+	var signature []byte
+	var err error
+	switch k := key.(type) {
+	case *rsa.PrivateKey:
+		if strings.HasPrefix(alg, "PS") {
+			signature, err = rsa.SignPSS(rand.Reader, k, crypto.SHA256, digest[:], nil)
+		} else {
+			signature, err = rsa.SignPKCS1v15(rand.Reader, k, crypto.SHA256, digest[:])
+		}
+	case *ecdsa.PrivateKey:
+		var r, s *big.Int
+		r, s, err = ecdsa.Sign(rand.Reader, k, digest[:])
+		if err == nil {
+			signature = append(r.FillBytes(make([]byte, 32)), s.FillBytes(make([]byte, 32))...)
+		}
+	}
+	if err != nil {
+		t.Fatalf("sign %s: %v", alg, err)
+	}
+	return signed + "." + base64.RawURLEncoding.EncodeToString(signature)
+}
+
+func TestValidateAcceptsValidToken(t *testing.T) {
+	j := newTestJWKS(t)
+	v := j.validator(t, nil)
+
+	tests := []struct {
+		alg string
+		kid string
+		key crypto.Signer
+	}{
+		{"RS256", "rsa-1", j.rsaKey},
+		{"PS256", "rsa-1", j.rsaKey},
+		{"ES256", "ec-1", j.ecKey},
+	}
+	for _, tt := range tests {
+		t.Run(tt.alg, func(t *testing.T) {
+			identity, err := v.Validate(context.Background(), sign(t, tt.alg, tt.kid, tt.key, validClaims()))
+			if err != nil {
+				t.Fatalf("Validate: %v", err)
+			}
+			want := &Identity{Name: "alice", Source: SourceOAuth}
+			if !reflect.DeepEqual(identity, want) {
+				t.Errorf("identity = %+v, want %+v", identity, want)
+			}
+		})
+	}
+}
+
+func TestValidateChecksClaims(t *testing.T) {
+	j := newTestJWKS(t)
+	v := j.validator(t, nil)
+	now := time.Now()
+
+	tests := []struct {
+		name    string
+		claims  map[string]interface{}
+		wantErr string
+	}{
+		{"wrong issuer", map[string]interface{}{"iss": "https://other.example.com"}, "unexpected issuer"},
+		{"wrong audience", map[string]interface{}{"aud": "https://other.example.com"}, "audience"},
+		{"missing audience", map[string]interface{}{"aud": nil}, "audience"},
+		{"expired", map[string]interface{}{"exp": now.Add(-time.Hour).Unix()}, "expired"},
+		{"expired beyond clock skew", map[string]interface{}{"exp": now.Add(-clockSkew - 5*time.Second).Unix()}, "expired"},
+		{"expired within clock skew", map[string]interface{}{"exp": now.Add(-clockSkew + 5*time.Second).Unix()}, ""},
+		{"missing expiry", map[string]interface{}{"exp": nil}, "expired"},
+		{"future nbf", map[string]interface{}{"nbf": now.Add(time.Hour).Unix()}, "not valid yet"},
+		{"nbf beyond clock skew", map[string]interface{}{"nbf": now.Add(clockSkew + 5*time.Second).Unix()}, "not valid yet"},
+		{"nbf within clock skew", map[string]interface{}{"nbf": now.Add(clockSkew - 5*time.Second).Unix()}, ""},
+		{"audience string", map[string]interface{}{"aud": testAudience}, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			claims := validClaims()
+			for name, value := range tt.claims {
+				if value == nil {
+					delete(claims, name)
+				} else {
+					claims[name] = value
+				}
+			}
+			_, err := v.Validate(context.Background(), sign(t, "RS256", "rsa-1", j.rsaKey, claims))
+			checkError(t, err, tt.wantErr)
+		})
+	}
+}
+
+func TestValidateRequiresScopes(t *testing.T) {
+	j := newTestJWKS(t)
+	v := j.validator(t, func(cfg *config.OAuthConfig) {
+		cfg.RequiredScopes = []string{"mcp:tools", "mcp:read"}
+	})
+
+	tests := []struct {
+		name      string
+		scopes    map[string]interface{}
+		wantScope bool
+	}{
+		{"scp list", map[string]interface{}{"scp": []string{"mcp:read", "mcp:tools"}}, false},
+		{"scope string", map[string]interface{}{"scope": "openid mcp:tools mcp:read"}, false},
+		{"scp and scope", map[string]interface{}{"scp": "mcp:tools", "scope": "mcp:read"}, false},
+		{"scp missing a scope", map[string]interface{}{"scp": []string{"mcp:read"}}, true},
+		{"scope missing a scope", map[string]interface{}{"scope": "openid mcp:tools"}, true},
+		{"no scopes", nil, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			claims := validClaims()
+			for name, value := range tt.scopes {
+				claims[name] = value
+			}
+			_, err := v.Validate(context.Background(), sign(t, "RS256", "rsa-1", j.rsaKey, claims))
+			if got := errors.Is(err, ErrInsufficientScope); got != tt.wantScope {
+				t.Errorf("Validate error = %v, want insufficient scope %v", err, tt.wantScope)
+			}
+			if !tt.wantScope && err != nil {
+				t.Errorf("Validate: %v", err)
+			}
+		})
+	}
+}
+
+func TestValidateRefreshesJWKSForUnknownKid(t *testing.T) {
+	j := newTestJWKS(t)
+	v := j.validator(t, nil)
+	if got := j.fetchCount(); got != 1 {
+		t.Fatalf("JWKS fetched %d times on creation, want 1", got)
+	}
+
+	// The authorization server rotates to a new key
+	rotated, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("generate rsa key: %v", err)
+	}
+	j.setKeys(rsaJWK("rsa-2", &rotated.PublicKey))
+	v.mu.Lock()
+	v.lastAttempt = time.Now().Add(-minJWKSRefreshInterval)
+	v.mu.Unlock()
+
+	if _, err := v.Validate(context.Background(), sign(t, "RS256", "rsa-2", rotated, validClaims())); err != nil {
+		t.Fatalf("Validate with the rotated key: %v", err)
+	}
+	if got := j.fetchCount(); got != 2 {
+		t.Errorf("JWKS fetched %d times, want a refresh for the unknown kid", got)
+	}
+	// Known keys are served from the cache
+	if _, err := v.Validate(context.Background(), sign(t, "RS256", "rsa-2", rotated, validClaims())); err != nil {
+		t.Fatalf("Validate with the cached key: %v", err)
+	}
+	if got := j.fetchCount(); got != 2 {
+		t.Errorf("JWKS fetched %d times, want the cached key used", got)
+	}
+}
+
+func TestValidateThrottlesJWKSRefresh(t *testing.T) {
+	j := newTestJWKS(t)
+	v := j.validator(t, nil)
+
+	unknown, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("generate rsa key: %v", err)
+	}
+	for i := 0; i < 3; i++ {
+		_, err := v.Validate(context.Background(), sign(t, "RS256", "unknown", unknown, validClaims()))
+		checkError(t, err, "unknown signing key")
+	}
+	if got := j.fetchCount(); got != 1 {
+		t.Errorf("JWKS fetched %d times, want no refresh within %s of the last one", got, minJWKSRefreshInterval)
+	}
+
+	// Past the throttle interval the next unknown kid refreshes again
+	v.mu.Lock()
+	v.lastAttempt = time.Now().Add(-minJWKSRefreshInterval)
+	v.mu.Unlock()
+	_, err = v.Validate(context.Background(), sign(t, "RS256", "unknown", unknown, validClaims()))
+	checkError(t, err, "unknown signing key")
+	if got := j.fetchCount(); got != 2 {
+		t.Errorf("JWKS fetched %d times, want a refresh after %s", got, minJWKSRefreshInterval)
+	}
+}
+
+func TestValidateRejectsAlgorithmMismatch(t *testing.T) {
+	j := newTestJWKS(t)
+	v := j.validator(t, nil)
+	claims := encodeSegment(t, validClaims())
+
+	publicKey, err := x509.MarshalPKIXPublicKey(&j.rsaKey.PublicKey)
+	if err != nil {
+		t.Fatalf("marshal public key: %v", err)
+	}
+	// HS256 signed with the public key, the classic algorithm confusion
+	hsSigned := encodeSegment(t, map[string]string{"alg": "HS256", "kid": "rsa-1"}) + "." + claims
+	mac := hmac.New(sha256.New, publicKey)
+	mac.Write([]byte(hsSigned))
+
+	tests := []struct {
+		name  string
+		token string
+	}{
+		{"none", encodeSegment(t, map[string]string{"alg": "none", "kid": "rsa-1"}) + "." + claims + "."},
+		{"HS256 with the RSA public key", hsSigned + "." + base64.RawURLEncoding.EncodeToString(mac.Sum(nil))},
+		{"ES256 header on an RSA key", retag(t, sign(t, "RS256", "rsa-1", j.rsaKey, validClaims()), "ES256", "rsa-1")},
+		{"RS256 header on an EC key", retag(t, sign(t, "ES256", "ec-1", j.ecKey, validClaims()), "RS256", "ec-1")},
+		{"RS256 signature on an EC kid", sign(t, "RS256", "ec-1", j.rsaKey, validClaims())},
+		{"ES256 signature on an RSA kid", sign(t, "ES256", "rsa-1", j.ecKey, validClaims())},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := v.Validate(context.Background(), tt.token)
+			checkError(t, err, "invalid signature")
+		})
+	}
+}
+
+// retag replaces the header of token, keeping its claims and signature
+func retag(t *testing.T, token, alg, kid string) string {
+	t.Helper()
+	parts := strings.Split(token, ".")
+	parts[0] = encodeSegment(t, map[string]string{"alg": alg, "kid": kid})
+	return strings.Join(parts, ".")
+}
+
+func TestValidateRejectsTamperedToken(t *testing.T) {
+	j := newTestJWKS(t)
+	v := j.validator(t, nil)
+
+	tests := []struct {
+		name   string
+		tamper func(parts []string)
+	}{
+		{"claims", func(parts []string) {
+			claims := validClaims()
+			claims["sub"] = "mallory"
+			parts[1] = encodeSegment(t, claims)
+		}},
+		{"signature", func(parts []string) {
+			signature, _ := base64.RawURLEncoding.DecodeString(parts[2])
+			signature[len(signature)/2] ^= 0x01
+			parts[2] = base64.RawURLEncoding.EncodeToString(signature)
+		}},
+	}
+	for _, tt := range tests {
+		for _, signer := range []struct {
+			alg, kid string
+			key      crypto.Signer
+		}{{"RS256", "rsa-1", j.rsaKey}, {"ES256", "ec-1", j.ecKey}} {
+			t.Run(tt.name+" "+signer.alg, func(t *testing.T) {
+				parts := strings.Split(sign(t, signer.alg, signer.kid, signer.key, validClaims()), ".")
+				tt.tamper(parts)
+				_, err := v.Validate(context.Background(), strings.Join(parts, "."))
+				checkError(t, err, "invalid signature")
+			})
+		}
+	}
+}
+
+func TestValidateMapsGroupsToModules(t *testing.T) {
+	j := newTestJWKS(t)
+	groups := []config.OAuthGroupConfig{
+		{Name: "sre", Modules: []string{"metrics", "logs", "sops"}},
+		{Name: "dev", Modules: []string{"logs", "traces"}},
+	}
+
+	tests := []struct {
+		name        string
+		configure   func(*config.OAuthConfig)
+		claims      map[string]interface{}
+		wantName    string
+		wantModules map[string]bool
+	}{
+		{
+			name:        "without group mappings every module is allowed",
+			claims:      map[string]interface{}{"groups": []string{"sre"}},
+			wantName:    "alice",
+			wantModules: nil,
+		},
+		{
+			name:        "modules of every matching group",
+			configure:   func(cfg *config.OAuthConfig) { cfg.Groups = groups },
+			claims:      map[string]interface{}{"groups": []string{"dev", "sre", "other"}},
+			wantName:    "alice",
+			wantModules: map[string]bool{"metrics": true, "logs": true, "sops": true, "traces": true},
+		},
+		{
+			name:        "single group as a string",
+			configure:   func(cfg *config.OAuthConfig) { cfg.Groups = groups },
+			claims:      map[string]interface{}{"groups": "dev"},
+			wantName:    "alice",
+			wantModules: map[string]bool{"logs": true, "traces": true},
+		},
+		{
+			name:        "no matching group allows no module",
+			configure:   func(cfg *config.OAuthConfig) { cfg.Groups = groups },
+			claims:      map[string]interface{}{"groups": []string{"other"}},
+			wantName:    "alice",
+			wantModules: map[string]bool{},
+		},
+		{
+			name: "custom claims",
+			configure: func(cfg *config.OAuthConfig) {
+				cfg.Groups = groups
+				cfg.GroupsClaim = "roles"
+				cfg.UsernameClaim = "email"
+			},
+			claims:      map[string]interface{}{"roles": []string{"sre"}, "groups": []string{"dev"}, "email": "alice@example.com"},
+			wantName:    "alice@example.com",
+			wantModules: map[string]bool{"metrics": true, "logs": true, "sops": true},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			v := j.validator(t, tt.configure)
+			claims := validClaims()
+			for name, value := range tt.claims {
+				claims[name] = value
+			}
+			identity, err := v.Validate(context.Background(), sign(t, "RS256", "rsa-1", j.rsaKey, claims))
+			if err != nil {
+				t.Fatalf("Validate: %v", err)
+			}
+			want := &Identity{Name: tt.wantName, Source: SourceOAuth, Modules: tt.wantModules}
+			if !reflect.DeepEqual(identity, want) {
+				t.Errorf("identity = %+v, want %+v", identity, want)
+			}
+		})
+	}
+}
+
+// checkError fails unless err contains wantErr, or is nil when wantErr is empty
+func checkError(t *testing.T, err error, wantErr string) {
+	t.Helper()
+	switch {
+	case wantErr == "" && err != nil:
+		t.Errorf("unexpected error: %v", err)
+	case wantErr != "" && err == nil:
+		t.Errorf("no error, want %q", wantErr)
+	case wantErr != "" && !strings.Contains(err.Error(), wantErr):
+		t.Errorf("error = %v, want %q", err, wantErr)
+	}
+}
@@ -0,0 +1,88 @@
+package auth
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// ToolFilter hides tools outside the scope of the caller from tools/list.
+// moduleOf returns the module exposing a tool.
+func ToolFilter(moduleOf func(tool string) string) server.ToolFilterFunc {
+	return func(ctx context.Context, tools []mcp.Tool) []mcp.Tool {
+		identity, ok := IdentityFromContext(ctx)
+		if !ok {
+			return tools
+		}
+		allowed := make([]mcp.Tool, 0, len(tools))
+		for _, tool := range tools {
+			if identity.Allows(moduleOf(tool.Name), tool.Name) {
+				allowed = append(allowed, tool)
+			}
+		}
+		return allowed
+	}
+}
+
+// ToolMiddleware rejects tool calls outside the scope of the caller
+func ToolMiddleware(moduleOf func(tool string) string) server.ToolHandlerMiddleware {
+	return func(next server.ToolHandlerFunc) server.ToolHandlerFunc {
+		return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			if identity, ok := IdentityFromContext(ctx); ok && !identity.Allows(moduleOf(request.Params.Name), request.Params.Name) {
+				return nil, fmt.Errorf("%s is not allowed to call tool %s", identity.Name, request.Params.Name)
+			}
+			return next(ctx, request)
+		}
+	}
+}
+
+// ResourceHooks hides resources and resource templates outside the modules
+// of the caller from resources/list and resources/templates/list, as
+// ToolFilter does for tools. moduleOf returns the module exposing a resource
+// URI or raw URI template.
+func ResourceHooks(moduleOf func(uri string) string) *server.Hooks {
+	hooks := &server.Hooks{}
+	hooks.AddAfterListResources(func(ctx context.Context, id any, message *mcp.ListResourcesRequest, result *mcp.ListResourcesResult) {
+		identity, ok := IdentityFromContext(ctx)
+		if !ok || result == nil {
+			return
+		}
+		allowed := make([]mcp.Resource, 0, len(result.Resources))
+		for _, resource := range result.Resources {
+			if identity.AllowsModule(moduleOf(resource.URI)) {
+				allowed = append(allowed, resource)
+			}
+		}
+		result.Resources = allowed
+	})
+	hooks.AddAfterListResourceTemplates(func(ctx context.Context, id any, message *mcp.ListResourceTemplatesRequest, result *mcp.ListResourceTemplatesResult) {
+		identity, ok := IdentityFromContext(ctx)
+		if !ok || result == nil {
+			return
+		}
+		allowed := make([]mcp.ResourceTemplate, 0, len(result.ResourceTemplates))
+		for _, template := range result.ResourceTemplates {
+			if template.URITemplate != nil && identity.AllowsModule(moduleOf(template.URITemplate.Raw())) {
+				allowed = append(allowed, template)
+			}
+		}
+		result.ResourceTemplates = allowed
+	})
+	return hooks
+}
+
+// ResourceMiddleware rejects resource reads outside the modules of the
+// caller. moduleOf returns the module exposing a resource URI. Tool
+// restrictions do not apply, resources only expose backend catalogs.
+func ResourceMiddleware(moduleOf func(uri string) string) server.ResourceHandlerMiddleware {
+	return func(next server.ResourceHandlerFunc) server.ResourceHandlerFunc {
+		return func(ctx context.Context, request mcp.ReadResourceRequest) ([]mcp.ResourceContents, error) {
+			if identity, ok := IdentityFromContext(ctx); ok && !identity.AllowsModule(moduleOf(request.Params.URI)) {
+				return nil, fmt.Errorf("%s is not allowed to read resource %s", identity.Name, request.Params.URI)
+			}
+			return next(ctx, request)
+		}
+	}
+}
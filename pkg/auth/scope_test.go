@@ -0,0 +1,103 @@
+package auth
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// resourceModules maps resource URIs and raw URI templates to their module
+var resourceModules = map[string]string{
+	"ops://metrics/names":      "metrics",
+	"ops://logs/indices":       "logs",
+	"ops://sops/{id}":          "sops",
+	"ops://metrics/rules{?ds}": "metrics",
+}
+
+func moduleOfResource(uri string) string {
+	return resourceModules[uri]
+}
+
+// newResourceServer creates a server with the resources and templates of
+// resourceModules, listed through ResourceHooks
+func newResourceServer() *server.MCPServer {
+	s := server.NewMCPServer("test", "1.0.0",
+		server.WithResourceCapabilities(false, true),
+		server.WithHooks(ResourceHooks(moduleOfResource)))
+	read := func(ctx context.Context, request mcp.ReadResourceRequest) ([]mcp.ResourceContents, error) {
+		return nil, nil
+	}
+	s.AddResource(mcp.NewResource("ops://metrics/names", "metric names"), read)
+	s.AddResource(mcp.NewResource("ops://logs/indices", "log indices"), read)
+	s.AddResourceTemplate(mcp.NewResourceTemplate("ops://sops/{id}", "SOPS procedure"), read)
+	s.AddResourceTemplate(mcp.NewResourceTemplate("ops://metrics/rules{?ds}", "alerting rules"), read)
+	return s
+}
+
+// list sends a list request of method and returns the listed URIs
+func list(t *testing.T, s *server.MCPServer, ctx context.Context, method string) []string {
+	t.Helper()
+	response := s.HandleMessage(ctx, json.RawMessage(`{"jsonrpc":"2.0","id":1,"method":"`+method+`"}`))
+	data, err := json.Marshal(response)
+	if err != nil {
+		t.Fatalf("%s response: %v", method, err)
+	}
+	var decoded struct {
+		Result struct {
+			Resources []struct {
+				URI string `json:"uri"`
+			} `json:"resources"`
+			ResourceTemplates []struct {
+				URITemplate string `json:"uriTemplate"`
+			} `json:"resourceTemplates"`
+		} `json:"result"`
+	}
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		t.Fatalf("%s response %s: %v", method, data, err)
+	}
+	uris := []string{}
+	for _, resource := range decoded.Result.Resources {
+		uris = append(uris, resource.URI)
+	}
+	for _, template := range decoded.Result.ResourceTemplates {
+		uris = append(uris, template.URITemplate)
+	}
+	return uris
+}
+
+func TestResourceHooksHideModulesOutOfScope(t *testing.T) {
+	s := newResourceServer()
+	tests := []struct {
+		name          string
+		identity      *Identity
+		wantResources int
+		wantTemplates int
+	}{
+		{"anonymous", nil, 2, 2},
+		{"unrestricted", &Identity{Name: "sre", Source: SourceToken}, 2, 2},
+		{"metrics only", &Identity{Name: "dev", Source: SourceToken, Modules: map[string]bool{"metrics": true}}, 1, 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			if tt.identity != nil {
+				ctx = WithIdentity(ctx, tt.identity)
+			}
+			resources := list(t, s, ctx, "resources/list")
+			templates := list(t, s, ctx, "resources/templates/list")
+			if len(resources) != tt.wantResources || len(templates) != tt.wantTemplates {
+				t.Fatalf("listed %v and %v, want %d resources and %d templates", resources, templates, tt.wantResources, tt.wantTemplates)
+			}
+			if tt.identity != nil && tt.identity.Modules != nil {
+				for _, uri := range append(resources, templates...) {
+					if moduleOfResource(uri) != "metrics" {
+						t.Errorf("%s listed to a caller without its module", uri)
+					}
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,122 @@
+package auth
+
+import (
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/shaowenchen/ops-mcp-server/pkg/config"
+)
+
+var (
+	// ErrInvalidToken is returned for tokens that match no configured token
+	ErrInvalidToken = errors.New("invalid token")
+	// ErrExpiredToken is returned for tokens past their expiry
+	ErrExpiredToken = errors.New("token expired")
+)
+
+// Token is a named API token with its scopes
+type Token struct {
+	Name      string
+	Modules   map[string]bool
+	Tools     map[string]bool
+	ExpiresAt time.Time
+	hash      []byte
+}
+
+// Identity returns the identity of callers authenticated with the token
+func (t *Token) Identity() *Identity {
+	return &Identity{Name: t.Name, Source: SourceToken, Modules: t.Modules, Tools: t.Tools}
+}
+
+// TokenStore validates bearer tokens against the configured token table
+type TokenStore struct {
+	tokens []*Token
+}
+
+// NewTokenStore builds a token store from the auth configuration
+func NewTokenStore(configs []config.TokenConfig) (*TokenStore, error) {
+	store := &TokenStore{}
+	names := make(map[string]bool)
+	for i, cfg := range configs {
+		if cfg.Name == "" {
+			return nil, fmt.Errorf("auth token %d: name is required", i)
+		}
+		if names[cfg.Name] {
+			return nil, fmt.Errorf("auth token %q: duplicate name", cfg.Name)
+		}
+		names[cfg.Name] = true
+
+		hash, err := hex.DecodeString(strings.TrimPrefix(cfg.Hash, "sha256:"))
+		if err != nil || len(hash) != sha256.Size {
+			return nil, fmt.Errorf("auth token %q: hash must be a hex encoded sha256", cfg.Name)
+		}
+		for _, existing := range store.tokens {
+			if subtle.ConstantTimeCompare(existing.hash, hash) == 1 {
+				return nil, fmt.Errorf("auth token %q: same hash as %q", cfg.Name, existing.Name)
+			}
+		}
+
+		token := &Token{
+			Name:    cfg.Name,
+			Modules: toSet(cfg.Modules),
+			Tools:   toSet(cfg.Tools),
+			hash:    hash,
+		}
+		if cfg.ExpiresAt != "" {
+			token.ExpiresAt, err = time.Parse(time.RFC3339, cfg.ExpiresAt)
+			if err != nil {
+				return nil, fmt.Errorf("auth token %q: invalid expires_at: %w", cfg.Name, err)
+			}
+		}
+		store.tokens = append(store.tokens, token)
+	}
+	return store, nil
+}
+
+// Len returns the number of configured tokens
+func (s *TokenStore) Len() int {
+	return len(s.tokens)
+}
+
+// Authenticate returns the token matching the presented secret. Expired
+// tokens are returned together with ErrExpiredToken so callers can log them.
+func (s *TokenStore) Authenticate(secret string) (*Token, error) {
+	sum := sha256.Sum256([]byte(secret))
+	var match *Token
+	// Compare against every token so timing does not reveal the match position
+	for _, token := range s.tokens {
+		if subtle.ConstantTimeCompare(sum[:], token.hash) == 1 {
+			match = token
+		}
+	}
+	if match == nil {
+		return nil, ErrInvalidToken
+	}
+	if !match.ExpiresAt.IsZero() && time.Now().After(match.ExpiresAt) {
+		return match, ErrExpiredToken
+	}
+	return match, nil
+}
+
+// HashToken returns the value to put in the hash field of a token
+func HashToken(secret string) string {
+	sum := sha256.Sum256([]byte(secret))
+	return "sha256:" + hex.EncodeToString(sum[:])
+}
+
+// toSet converts a list to a set, an empty list yields nil meaning no restriction
+func toSet(values []string) map[string]bool {
+	if len(values) == 0 {
+		return nil
+	}
+	set := make(map[string]bool, len(values))
+	for _, value := range values {
+		set[value] = true
+	}
+	return set
+}
@@ -1,8 +1,15 @@
 package config
 
-import "time"
+import (
+	"reflect"
+	"strings"
+	"time"
+)
 
-// Config represents the complete server configuration
+// Config represents the complete server configuration. Fields tagged
+// secret:"true" are masked by MaskSecrets and can be read from the file
+// named by their <key>_file sibling, deprecated:"true" marks fields as
+// deprecated in the JSON Schema.
 type Config struct {
 	Log     LogConfig     `mapstructure:"log" json:"log" yaml:"log"`
 	Server  ServerConfig  `mapstructure:"server" json:"server" yaml:"server"`
@@ -11,14 +18,38 @@ type Config struct {
 	Metrics MetricsConfig `mapstructure:"metrics" json:"metrics" yaml:"metrics"`
 	Logs    LogsConfig    `mapstructure:"logs" json:"logs" yaml:"logs"`
 	Traces  TracesConfig  `mapstructure:"traces" json:"traces" yaml:"traces"`
-	SSE     SSEConfig     `mapstructure:"sse" json:"sse" yaml:"sse"`
+	SSE     SSEConfig     `mapstructure:"sse" json:"sse" yaml:"sse" deprecated:"true"` // Deprecated: use Server.SSE
 	Auth    AuthConfig    `mapstructure:"auth" json:"auth" yaml:"auth"`
+	Limits  LimitsConfig  `mapstructure:"limits" json:"limits" yaml:"limits"`
+	Audit   AuditConfig   `mapstructure:"audit" json:"audit" yaml:"audit"`
+}
+
+// Section returns the value of the top-level section with the given config
+// key, e.g. "metrics", nil when there is no such section
+func (c *Config) Section(key string) interface{} {
+	v := reflect.ValueOf(c).Elem()
+	t := v.Type()
+	for i := 0; i < v.NumField(); i++ {
+		if strings.Split(t.Field(i).Tag.Get("yaml"), ",")[0] == key {
+			return v.Field(i).Interface()
+		}
+	}
+	return nil
 }
 
 // ToolsConfig contains tools configuration
 type ToolsConfig struct {
 	Prefix string `mapstructure:"prefix" json:"prefix" yaml:"prefix"`
 	Suffix string `mapstructure:"suffix" json:"suffix" yaml:"suffix"`
+	// Overrides disable, rename or redescribe tools, keyed by their default name
+	Overrides map[string]ToolOverride `mapstructure:"overrides" json:"overrides,omitempty" yaml:"overrides,omitempty"`
+}
+
+// ToolOverride changes a single tool of a module, unset fields keep the default
+type ToolOverride struct {
+	Enabled     *bool  `mapstructure:"enabled" json:"enabled,omitempty" yaml:"enabled,omitempty"`
+	Name        string `mapstructure:"name" json:"name,omitempty" yaml:"name,omitempty"`
+	Description string `mapstructure:"description" json:"description,omitempty" yaml:"description,omitempty"`
 }
 
 // LogConfig contains logging configuration
@@ -28,17 +59,43 @@ type LogConfig struct {
 
 // ServerConfig contains server configuration
 type ServerConfig struct {
-	Host  string `mapstructure:"host" json:"host" yaml:"host"`
-	Port  int    `mapstructure:"port" json:"port" yaml:"port"`
-	Mode  string `mapstructure:"mode" json:"mode" yaml:"mode"`
-	URI   string `mapstructure:"uri" json:"uri" yaml:"uri"`
-	Token string `mapstructure:"token" json:"token" yaml:"token"`
+	Host                string               `mapstructure:"host" json:"host" yaml:"host"`
+	Port                int                  `mapstructure:"port" json:"port" yaml:"port"`
+	Mode                string               `mapstructure:"mode" json:"mode" yaml:"mode"`
+	URI                 string               `mapstructure:"uri" json:"uri" yaml:"uri"`
+	Token               string               `mapstructure:"token" json:"token" yaml:"token" secret:"true"`
+	TokenFile           string               `mapstructure:"token_file" json:"token_file,omitempty" yaml:"token_file,omitempty"`
+	ShutdownGracePeriod int                  `mapstructure:"shutdown_grace_period" json:"shutdown_grace_period" yaml:"shutdown_grace_period"`
+	DrainDelay          int                  `mapstructure:"drain_delay" json:"drain_delay" yaml:"drain_delay"`
+	WatchConfig         bool                 `mapstructure:"watch_config" json:"watch_config" yaml:"watch_config"`
+	StreamableHTTP      StreamableHTTPConfig `mapstructure:"streamable_http" json:"streamable_http" yaml:"streamable_http"`
+	SSE                 SSEConfig            `mapstructure:"sse" json:"sse" yaml:"sse"`
+	TLS                 TLSConfig            `mapstructure:"tls" json:"tls" yaml:"tls"`
+}
+
+// TLSConfig contains TLS and mutual TLS configuration for the HTTP listener
+type TLSConfig struct {
+	CertFile       string `mapstructure:"cert_file" json:"cert_file" yaml:"cert_file"`
+	KeyFile        string `mapstructure:"key_file" json:"key_file" yaml:"key_file"`
+	ClientCAFile   string `mapstructure:"client_ca_file" json:"client_ca_file" yaml:"client_ca_file"`
+	ClientAuth     string `mapstructure:"client_auth" json:"client_auth" yaml:"client_auth"`
+	ClientIdentity string `mapstructure:"client_identity" json:"client_identity" yaml:"client_identity"`
+	ReloadInterval int    `mapstructure:"reload_interval" json:"reload_interval" yaml:"reload_interval"`
+}
+
+// StreamableHTTPConfig contains Streamable HTTP transport configuration
+type StreamableHTTPConfig struct {
+	Stateless         bool `mapstructure:"stateless" json:"stateless" yaml:"stateless"`
+	HeartbeatInterval int  `mapstructure:"heartbeat_interval" json:"heartbeat_interval" yaml:"heartbeat_interval"`
+	SessionIdleTTL    int  `mapstructure:"session_idle_ttl" json:"session_idle_ttl" yaml:"session_idle_ttl"`
+	DisableStreaming  bool `mapstructure:"disable_streaming" json:"disable_streaming" yaml:"disable_streaming"`
 }
 
 // EventsOpsConfig contains Ops backend configuration for events
 type EventsOpsConfig struct {
-	Endpoint string `mapstructure:"endpoint" json:"endpoint" yaml:"endpoint"`
-	Token    string `mapstructure:"token" json:"token" yaml:"token"`
+	Endpoint  string `mapstructure:"endpoint" json:"endpoint" yaml:"endpoint"`
+	Token     string `mapstructure:"token" json:"token" yaml:"token" secret:"true"`
+	TokenFile string `mapstructure:"token_file" json:"token_file,omitempty" yaml:"token_file,omitempty"`
 }
 
 // EventsConfig contains events module configuration
@@ -50,11 +107,24 @@ type EventsConfig struct {
 
 // PrometheusConfig contains Prometheus configuration for metrics
 type PrometheusConfig struct {
-	Endpoint string `mapstructure:"endpoint" json:"endpoint" yaml:"endpoint"`
-	Username string `mapstructure:"username" json:"username" yaml:"username"`
-	Password string `mapstructure:"password" json:"password" yaml:"password"`
-	Token    string `mapstructure:"token" json:"token" yaml:"token"`
-	Timeout  int    `mapstructure:"timeout" json:"timeout" yaml:"timeout"`
+	Endpoint     string `mapstructure:"endpoint" json:"endpoint" yaml:"endpoint"`
+	Username     string `mapstructure:"username" json:"username" yaml:"username"`
+	Password     string `mapstructure:"password" json:"password" yaml:"password" secret:"true"`
+	PasswordFile string `mapstructure:"password_file" json:"password_file,omitempty" yaml:"password_file,omitempty"`
+	Token        string `mapstructure:"token" json:"token" yaml:"token" secret:"true"`
+	TokenFile    string `mapstructure:"token_file" json:"token_file,omitempty" yaml:"token_file,omitempty"`
+	Timeout      int    `mapstructure:"timeout" json:"timeout" yaml:"timeout"`
+}
+
+// AlertmanagerConfig contains Alertmanager configuration for metrics
+type AlertmanagerConfig struct {
+	Endpoint     string `mapstructure:"endpoint" json:"endpoint" yaml:"endpoint"`
+	Username     string `mapstructure:"username" json:"username" yaml:"username"`
+	Password     string `mapstructure:"password" json:"password" yaml:"password" secret:"true"`
+	PasswordFile string `mapstructure:"password_file" json:"password_file,omitempty" yaml:"password_file,omitempty"`
+	Token        string `mapstructure:"token" json:"token" yaml:"token" secret:"true"`
+	TokenFile    string `mapstructure:"token_file" json:"token_file,omitempty" yaml:"token_file,omitempty"`
+	Timeout      int    `mapstructure:"timeout" json:"timeout" yaml:"timeout"`
 }
 
 // MetricsConfig contains metrics module configuration
@@ -62,6 +132,11 @@ type MetricsConfig struct {
 	Enabled    bool              `mapstructure:"enabled" json:"enabled" yaml:"enabled"`
 	Tools      ToolsConfig       `mapstructure:"tools" json:"tools" yaml:"tools"`
 	Prometheus *PrometheusConfig `mapstructure:"prometheus" json:"prometheus" yaml:"prometheus"`
+	// Datasources are named Prometheus instances selected with the datasource tool argument
+	Datasources       map[string]*PrometheusConfig `mapstructure:"datasources" json:"datasources,omitempty" yaml:"datasources,omitempty"`
+	DefaultDatasource string                       `mapstructure:"default_datasource" json:"default_datasource,omitempty" yaml:"default_datasource,omitempty"`
+	// Alertmanager enables the alert group and silence tools
+	Alertmanager *AlertmanagerConfig `mapstructure:"alertmanager" json:"alertmanager,omitempty" yaml:"alertmanager,omitempty"`
 }
 
 // LogsConfig contains logs module configuration
@@ -69,15 +144,20 @@ type LogsConfig struct {
 	Enabled       bool                     `mapstructure:"enabled" json:"enabled" yaml:"enabled"`
 	Tools         ToolsConfig              `mapstructure:"tools" json:"tools" yaml:"tools"`
 	Elasticsearch *LogsElasticsearchConfig `mapstructure:"elasticsearch" json:"elasticsearch" yaml:"elasticsearch"`
+	// Datasources are named Elasticsearch clusters selected with the datasource tool argument
+	Datasources       map[string]*LogsElasticsearchConfig `mapstructure:"datasources" json:"datasources,omitempty" yaml:"datasources,omitempty"`
+	DefaultDatasource string                              `mapstructure:"default_datasource" json:"default_datasource,omitempty" yaml:"default_datasource,omitempty"`
 }
 
 // LogsElasticsearchConfig contains elasticsearch backend configuration for logs
 type LogsElasticsearchConfig struct {
-	Endpoint string `mapstructure:"endpoint" json:"endpoint" yaml:"endpoint"`
-	Username string `mapstructure:"username" json:"username" yaml:"username"`
-	Password string `mapstructure:"password" json:"password" yaml:"password"`
-	APIKey   string `mapstructure:"api_key" json:"api_key" yaml:"api_key"`
-	Timeout  int    `mapstructure:"timeout" json:"timeout" yaml:"timeout"`
+	Endpoint     string `mapstructure:"endpoint" json:"endpoint" yaml:"endpoint"`
+	Username     string `mapstructure:"username" json:"username" yaml:"username"`
+	Password     string `mapstructure:"password" json:"password" yaml:"password" secret:"true"`
+	PasswordFile string `mapstructure:"password_file" json:"password_file,omitempty" yaml:"password_file,omitempty"`
+	APIKey       string `mapstructure:"api_key" json:"api_key" yaml:"api_key" secret:"true"`
+	APIKeyFile   string `mapstructure:"api_key_file" json:"api_key_file,omitempty" yaml:"api_key_file,omitempty"`
+	Timeout      int    `mapstructure:"timeout" json:"timeout" yaml:"timeout"`
 }
 
 // JaegerConfig contains Jaeger backend configuration for traces
@@ -95,8 +175,11 @@ type TracesConfig struct {
 
 // OpsConfig contains Ops backend configuration for Sops
 type OpsConfig struct {
-	Endpoint string `mapstructure:"endpoint" json:"endpoint" yaml:"endpoint"`
-	Token    string `mapstructure:"token" json:"token" yaml:"token"`
+	Endpoint  string `mapstructure:"endpoint" json:"endpoint" yaml:"endpoint"`
+	Token     string `mapstructure:"token" json:"token" yaml:"token" secret:"true"`
+	TokenFile string `mapstructure:"token_file" json:"token_file,omitempty" yaml:"token_file,omitempty"`
+	// Namespace is the namespace of the pipelines, ops-system when not set
+	Namespace string `mapstructure:"namespace" json:"namespace,omitempty" yaml:"namespace,omitempty"`
 }
 
 // SopsConfig contains Sops module configuration
@@ -104,9 +187,12 @@ type SopsConfig struct {
 	Enabled bool        `mapstructure:"enabled" json:"enabled" yaml:"enabled"`
 	Tools   ToolsConfig `mapstructure:"tools" json:"tools" yaml:"tools"`
 	Ops     *OpsConfig  `mapstructure:"ops" json:"ops" yaml:"ops"`
+	// Dangerous lists the SOPS IDs the user must confirm before they run,
+	// in addition to pipelines labeled ops/dangerous=true
+	Dangerous []string `mapstructure:"dangerous" json:"dangerous,omitempty" yaml:"dangerous,omitempty"`
 }
 
-// SSEConfig contains SSE configuration
+// SSEConfig contains legacy SSE transport configuration
 type SSEConfig struct {
 	KeepAlive      time.Duration `mapstructure:"keepAlive" json:"keepAlive" yaml:"keepAlive"`
 	MaxConnections int           `mapstructure:"maxConnections" json:"maxConnections" yaml:"maxConnections"`
@@ -114,5 +200,72 @@ type SSEConfig struct {
 
 // AuthConfig contains authentication configuration
 type AuthConfig struct {
-	Enabled bool `mapstructure:"enabled" json:"enabled" yaml:"enabled"`
+	Enabled bool          `mapstructure:"enabled" json:"enabled" yaml:"enabled"`
+	Tokens  []TokenConfig `mapstructure:"tokens" json:"tokens" yaml:"tokens"`
+	OAuth   OAuthConfig   `mapstructure:"oauth" json:"oauth" yaml:"oauth"`
+}
+
+// OAuthConfig contains OAuth 2.1 resource server configuration. Access tokens
+// are JWTs validated against the issuer's JWKS, Resource is advertised in the
+// protected resource metadata and defaults to Audience.
+type OAuthConfig struct {
+	Enabled         bool               `mapstructure:"enabled" json:"enabled" yaml:"enabled"`
+	Issuer          string             `mapstructure:"issuer" json:"issuer" yaml:"issuer"`
+	JWKSURL         string             `mapstructure:"jwks_url" json:"jwks_url" yaml:"jwks_url"`
+	Audience        string             `mapstructure:"audience" json:"audience" yaml:"audience"`
+	Resource        string             `mapstructure:"resource" json:"resource" yaml:"resource"`
+	RequiredScopes  []string           `mapstructure:"required_scopes" json:"required_scopes" yaml:"required_scopes"`
+	UsernameClaim   string             `mapstructure:"username_claim" json:"username_claim" yaml:"username_claim"`
+	GroupsClaim     string             `mapstructure:"groups_claim" json:"groups_claim" yaml:"groups_claim"`
+	Groups          []OAuthGroupConfig `mapstructure:"groups" json:"groups" yaml:"groups"`
+	RefreshInterval int                `mapstructure:"refresh_interval" json:"refresh_interval" yaml:"refresh_interval"`
+}
+
+// OAuthGroupConfig maps a group claim value to the modules its members may use
+type OAuthGroupConfig struct {
+	Name    string   `mapstructure:"name" json:"name" yaml:"name"`
+	Modules []string `mapstructure:"modules" json:"modules" yaml:"modules"`
+}
+
+// TokenConfig contains a named API token and the modules and tools it may use.
+// Hash is the hex encoded SHA-256 of the token, optionally prefixed with "sha256:".
+// Empty Modules or Tools allow everything, ExpiresAt is an RFC 3339 timestamp.
+type TokenConfig struct {
+	Name      string   `mapstructure:"name" json:"name" yaml:"name"`
+	Hash      string   `mapstructure:"hash" json:"hash" yaml:"hash"`
+	Modules   []string `mapstructure:"modules" json:"modules" yaml:"modules"`
+	Tools     []string `mapstructure:"tools" json:"tools" yaml:"tools"`
+	ExpiresAt string   `mapstructure:"expires_at" json:"expires_at" yaml:"expires_at"`
+}
+
+// LimitsConfig contains rate limit and concurrency rules for tool calls
+type LimitsConfig struct {
+	Enabled bool              `mapstructure:"enabled" json:"enabled" yaml:"enabled"`
+	Rules   []LimitRuleConfig `mapstructure:"rules" json:"rules" yaml:"rules"`
+}
+
+// LimitRuleConfig limits the tool calls matching Identity, Module and Tool,
+// empty values match everything. Per splits the limit into separate buckets
+// by "identity", "module" and/or "tool", otherwise matching calls share one.
+// Rate is in calls per second, zero Rate or MaxConcurrent disables that limit.
+type LimitRuleConfig struct {
+	Name          string   `mapstructure:"name" json:"name" yaml:"name"`
+	Identity      string   `mapstructure:"identity" json:"identity" yaml:"identity"`
+	Module        string   `mapstructure:"module" json:"module" yaml:"module"`
+	Tool          string   `mapstructure:"tool" json:"tool" yaml:"tool"`
+	Per           []string `mapstructure:"per" json:"per" yaml:"per"`
+	Rate          float64  `mapstructure:"rate" json:"rate" yaml:"rate"`
+	Burst         int      `mapstructure:"burst" json:"burst" yaml:"burst"`
+	MaxConcurrent int      `mapstructure:"max_concurrent" json:"max_concurrent" yaml:"max_concurrent"`
+}
+
+// AuditConfig contains the tool call audit log configuration. Output is
+// "stdout", "stderr" or a file path, files are rotated once they reach MaxSizeMB.
+type AuditConfig struct {
+	Enabled        bool     `mapstructure:"enabled" json:"enabled" yaml:"enabled"`
+	Output         string   `mapstructure:"output" json:"output" yaml:"output"`
+	MaxSizeMB      int      `mapstructure:"max_size_mb" json:"max_size_mb" yaml:"max_size_mb"`
+	MaxBackups     int      `mapstructure:"max_backups" json:"max_backups" yaml:"max_backups"`
+	RedactKeys     []string `mapstructure:"redact_keys" json:"redact_keys" yaml:"redact_keys"`
+	MaxValueLength int      `mapstructure:"max_value_length" json:"max_value_length" yaml:"max_value_length"`
 }
@@ -0,0 +1,49 @@
+package config
+
+import "reflect"
+
+// Masked replaces the value of secrets that are set
+const Masked = "******"
+
+// MaskSecrets replaces every non-empty field tagged secret:"true" with Masked
+// so the configuration can be printed or logged
+func (c *Config) MaskSecrets() {
+	maskValue(reflect.ValueOf(c).Elem())
+}
+
+// maskValue walks v, masking secret string fields of structs
+func maskValue(v reflect.Value) {
+	switch v.Kind() {
+	case reflect.Ptr, reflect.Interface:
+		if !v.IsNil() {
+			maskValue(v.Elem())
+		}
+	case reflect.Struct:
+		t := v.Type()
+		for i := 0; i < v.NumField(); i++ {
+			field := v.Field(i)
+			if !t.Field(i).IsExported() {
+				continue
+			}
+			if t.Field(i).Tag.Get("secret") == "true" && field.Kind() == reflect.String {
+				if field.String() != "" {
+					field.SetString(Masked)
+				}
+				continue
+			}
+			maskValue(field)
+		}
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < v.Len(); i++ {
+			maskValue(v.Index(i))
+		}
+	case reflect.Map:
+		for _, key := range v.MapKeys() {
+			// Map values are not addressable, mask a copy and store it back
+			value := reflect.New(v.Type().Elem()).Elem()
+			value.Set(v.MapIndex(key))
+			maskValue(value)
+			v.SetMapIndex(key, value)
+		}
+	}
+}
@@ -0,0 +1,91 @@
+package config
+
+import (
+	"reflect"
+	"strings"
+	"time"
+)
+
+// enums lists the allowed values of string fields, keyed by their dotted path
+var enums = map[string][]string{
+	"log.level":                  {"debug", "info", "warn", "error"},
+	"server.mode":                Modes,
+	"server.tls.client_auth":     {"require", "optional"},
+	"server.tls.client_identity": {"cn", "subject"},
+}
+
+// Schema returns a JSON Schema (draft 2020-12) describing Config, generated
+// from the yaml tags of the config structs for editor completion and validation
+func Schema() map[string]interface{} {
+	schema := schemaFor(reflect.TypeOf(Config{}), "")
+	schema["$schema"] = "https://json-schema.org/draft/2020-12/schema"
+	schema["title"] = "ops-mcp-server configuration"
+	return schema
+}
+
+// schemaFor returns the schema of t found at the dotted path
+func schemaFor(t reflect.Type, path string) map[string]interface{} {
+	if t == reflect.TypeOf(time.Duration(0)) {
+		return map[string]interface{}{
+			"type":        "string",
+			"description": "Duration such as 30s or 5m",
+		}
+	}
+
+	switch t.Kind() {
+	case reflect.Ptr:
+		return schemaFor(t.Elem(), path)
+	case reflect.Struct:
+		properties := make(map[string]interface{})
+		for i := 0; i < t.NumField(); i++ {
+			field := t.Field(i)
+			name := strings.Split(field.Tag.Get("yaml"), ",")[0]
+			if !field.IsExported() || name == "" || name == "-" {
+				continue
+			}
+			fieldPath := name
+			if path != "" {
+				fieldPath = path + "." + name
+			}
+			property := schemaFor(field.Type, fieldPath)
+			if field.Tag.Get("secret") == "true" {
+				property["writeOnly"] = true
+			}
+			if field.Tag.Get("deprecated") == "true" {
+				property["deprecated"] = true
+			}
+			properties[name] = property
+		}
+		return map[string]interface{}{
+			"type":                 "object",
+			"properties":           properties,
+			"additionalProperties": false,
+		}
+	case reflect.Slice, reflect.Array:
+		return map[string]interface{}{
+			"type":  "array",
+			"items": schemaFor(t.Elem(), path),
+		}
+	case reflect.Map:
+		return map[string]interface{}{
+			"type":                 "object",
+			"additionalProperties": schemaFor(t.Elem(), path),
+		}
+	case reflect.Bool:
+		return map[string]interface{}{"type": "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return map[string]interface{}{"type": "integer"}
+	case reflect.Float32, reflect.Float64:
+		return map[string]interface{}{"type": "number"}
+	case reflect.String:
+		schema := map[string]interface{}{"type": "string"}
+		if values, ok := enums[path]; ok {
+			// Empty values select the default
+			schema["enum"] = append([]string{""}, values...)
+		}
+		return schema
+	default:
+		return map[string]interface{}{}
+	}
+}
@@ -0,0 +1,141 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"reflect"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// envReference matches ${VAR} references to environment variables
+var envReference = regexp.MustCompile(`\$\{([A-Za-z_][A-Za-z0-9_]*)\}`)
+
+// ResolveSecrets expands ${VAR} references in every field tagged
+// secret:"true" and in its <key>_file sibling, then reads the secrets whose
+// file is set. Only the ${VAR} form is expanded, so passwords containing a
+// plain $ need no escaping. Files are read on every call, which lets a
+// reload pick up rotated tokens.
+func (c *Config) ResolveSecrets() error {
+	var errs []error
+	walkSecrets(reflect.ValueOf(c).Elem(), "", func(path string, secret, file reflect.Value) {
+		value, err := expandEnv(secret.String())
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", path, err))
+			return
+		}
+		secret.SetString(value)
+		if !file.IsValid() {
+			return
+		}
+
+		filePath, err := expandEnv(file.String())
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s_file: %w", path, err))
+			return
+		}
+		file.SetString(filePath)
+		if filePath == "" {
+			return
+		}
+		if value != "" {
+			key := path[strings.LastIndex(path, ".")+1:]
+			errs = append(errs, fmt.Errorf("%s: set either %s or %s_file, not both", path, key, key))
+			return
+		}
+		data, err := os.ReadFile(filePath)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s_file: %w", path, err))
+			return
+		}
+		secret.SetString(strings.TrimSpace(string(data)))
+	})
+	return errors.Join(errs...)
+}
+
+// SecretFiles returns the files secrets are read from, sorted and without duplicates
+func (c *Config) SecretFiles() []string {
+	seen := make(map[string]bool)
+	var files []string
+	walkSecrets(reflect.ValueOf(c).Elem(), "", func(path string, secret, file reflect.Value) {
+		if file.IsValid() && file.String() != "" && !seen[file.String()] {
+			seen[file.String()] = true
+			files = append(files, file.String())
+		}
+	})
+	sort.Strings(files)
+	return files
+}
+
+// expandEnv replaces ${VAR} references in s with the value of the environment variable
+func expandEnv(s string) (string, error) {
+	var missing []string
+	expanded := envReference.ReplaceAllStringFunc(s, func(reference string) string {
+		name := envReference.FindStringSubmatch(reference)[1]
+		value, ok := os.LookupEnv(name)
+		if !ok {
+			missing = append(missing, name)
+		}
+		return value
+	})
+	if len(missing) > 0 {
+		return "", fmt.Errorf("environment variable %s is not set", strings.Join(missing, ", "))
+	}
+	return expanded, nil
+}
+
+// walkSecrets calls fn with every settable secret string field found in v
+// at the dotted path, and its <key>_file sibling, which is the zero Value
+// when the struct has none
+func walkSecrets(v reflect.Value, path string, fn func(path string, secret, file reflect.Value)) {
+	switch v.Kind() {
+	case reflect.Ptr, reflect.Interface:
+		if !v.IsNil() {
+			walkSecrets(v.Elem(), path, fn)
+		}
+	case reflect.Struct:
+		t := v.Type()
+		for i := 0; i < v.NumField(); i++ {
+			field := v.Field(i)
+			name := strings.Split(t.Field(i).Tag.Get("yaml"), ",")[0]
+			if !t.Field(i).IsExported() || name == "" || name == "-" {
+				continue
+			}
+			fieldPath := name
+			if path != "" {
+				fieldPath = path + "." + name
+			}
+			if t.Field(i).Tag.Get("secret") == "true" && field.Kind() == reflect.String {
+				fn(fieldPath, field, fieldByYAMLName(v, name+"_file"))
+				continue
+			}
+			walkSecrets(field, fieldPath, fn)
+		}
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < v.Len(); i++ {
+			walkSecrets(v.Index(i), fmt.Sprintf("%s[%d]", path, i), fn)
+		}
+	case reflect.Map:
+		for _, key := range v.MapKeys() {
+			// Map values are not addressable, walk a copy and store it back
+			value := reflect.New(v.Type().Elem()).Elem()
+			value.Set(v.MapIndex(key))
+			walkSecrets(value, fmt.Sprintf("%s.%v", path, key), fn)
+			v.SetMapIndex(key, value)
+		}
+	}
+}
+
+// fieldByYAMLName returns the string field of the struct v with the yaml key
+// name, the zero Value when there is none
+func fieldByYAMLName(v reflect.Value, name string) reflect.Value {
+	t := v.Type()
+	for i := 0; i < v.NumField(); i++ {
+		if strings.Split(t.Field(i).Tag.Get("yaml"), ",")[0] == name && v.Field(i).Kind() == reflect.String {
+			return v.Field(i)
+		}
+	}
+	return reflect.Value{}
+}
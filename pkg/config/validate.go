@@ -0,0 +1,198 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"sort"
+	"strings"
+)
+
+// Modes lists the valid server modes
+var Modes = []string{"stdio", "sse", "streamable-http", "both"}
+
+// Validate checks values that would otherwise only fail at runtime, such as
+// unknown modes, missing or malformed backend endpoints and negative
+// timeouts. It reports every problem found, joined into one error.
+func (c *Config) Validate() error {
+	var errs []error
+	check := func(ok bool, format string, args ...interface{}) {
+		if !ok {
+			errs = append(errs, fmt.Errorf(format, args...))
+		}
+	}
+
+	check(oneOf(c.Log.Level, "", "debug", "info", "warn", "error"), "log.level: unknown level %q, expected debug, info, warn or error", c.Log.Level)
+
+	// Server
+	check(oneOf(c.Server.Mode, append([]string{""}, Modes...)...), "server.mode: unknown mode %q, expected stdio, sse, streamable-http or both", c.Server.Mode)
+	if c.Server.Mode != "" && c.Server.Mode != "stdio" {
+		check(c.Server.Port > 0 && c.Server.Port <= 65535, "server.port: %d is not a valid port", c.Server.Port)
+	}
+	check(c.Server.ShutdownGracePeriod >= 0, "server.shutdown_grace_period: must not be negative")
+	check(c.Server.StreamableHTTP.HeartbeatInterval >= 0, "server.streamable_http.heartbeat_interval: must not be negative")
+	check(c.Server.StreamableHTTP.SessionIdleTTL >= 0, "server.streamable_http.session_idle_ttl: must not be negative")
+	check(c.Server.SSE.KeepAlive >= 0, "server.sse.keepAlive: must not be negative")
+	check(c.Server.SSE.MaxConnections >= 0, "server.sse.maxConnections: must not be negative")
+
+	tls := c.Server.TLS
+	check((tls.CertFile == "") == (tls.KeyFile == ""), "server.tls: cert_file and key_file must be set together")
+	check(tls.ClientCAFile == "" || tls.CertFile != "", "server.tls.client_ca_file: requires cert_file and key_file")
+	check(oneOf(tls.ClientAuth, "", "require", "optional"), "server.tls.client_auth: unknown value %q, expected require or optional", tls.ClientAuth)
+	check(oneOf(tls.ClientIdentity, "", "cn", "subject"), "server.tls.client_identity: unknown value %q, expected cn or subject", tls.ClientIdentity)
+	check(tls.ClientIdentity == "" || tls.ClientCAFile != "", "server.tls.client_identity: requires client_ca_file")
+	check(tls.ReloadInterval >= 0, "server.tls.reload_interval: must not be negative")
+
+	// Modules, backends are only required when the module is enabled
+	if c.Sops.Enabled {
+		var endpoint string
+		if c.Sops.Ops != nil {
+			endpoint = c.Sops.Ops.Endpoint
+		}
+		errs = append(errs, checkBackend("sops.ops", endpoint, 0)...)
+	}
+	if c.Events.Enabled {
+		var endpoint string
+		if c.Events.Ops != nil {
+			endpoint = c.Events.Ops.Endpoint
+		}
+		errs = append(errs, checkBackend("events.ops", endpoint, 0)...)
+	}
+	if c.Metrics.Enabled {
+		var unnamed *backend
+		if c.Metrics.Prometheus != nil {
+			unnamed = &backend{c.Metrics.Prometheus.Endpoint, c.Metrics.Prometheus.Timeout}
+		}
+		named := make(map[string]backend)
+		for name, ds := range c.Metrics.Datasources {
+			if ds != nil {
+				named[name] = backend{ds.Endpoint, ds.Timeout}
+			}
+		}
+		errs = append(errs, checkDatasources("metrics", "prometheus", unnamed, named, c.Metrics.DefaultDatasource)...)
+		if c.Metrics.Alertmanager != nil {
+			errs = append(errs, checkBackend("metrics.alertmanager", c.Metrics.Alertmanager.Endpoint, c.Metrics.Alertmanager.Timeout)...)
+		}
+	}
+	if c.Logs.Enabled {
+		var unnamed *backend
+		if c.Logs.Elasticsearch != nil {
+			unnamed = &backend{c.Logs.Elasticsearch.Endpoint, c.Logs.Elasticsearch.Timeout}
+		}
+		named := make(map[string]backend)
+		for name, ds := range c.Logs.Datasources {
+			if ds != nil {
+				named[name] = backend{ds.Endpoint, ds.Timeout}
+			}
+		}
+		errs = append(errs, checkDatasources("logs", "elasticsearch", unnamed, named, c.Logs.DefaultDatasource)...)
+	}
+	if c.Traces.Enabled {
+		var endpoint string
+		var timeout int
+		if c.Traces.Jaeger != nil {
+			endpoint, timeout = c.Traces.Jaeger.Endpoint, c.Traces.Jaeger.Timeout
+		}
+		errs = append(errs, checkBackend("traces.jaeger", endpoint, timeout)...)
+	}
+
+	// Auth
+	if c.Auth.Enabled {
+		check(len(c.Auth.Tokens) > 0 || c.Auth.OAuth.Enabled || c.Server.Token != "", "auth.enabled: requires auth.tokens, auth.oauth or server.token")
+	}
+	if c.Auth.OAuth.Enabled {
+		check(c.Auth.Enabled, "auth.oauth.enabled: requires auth.enabled")
+		// Tokens are always checked against the issuer and audience, jwks_url
+		// only skips discovering the keys from the issuer
+		check(c.Auth.OAuth.Issuer != "", "auth.oauth.issuer: required when oauth is enabled")
+		check(c.Auth.OAuth.Audience != "", "auth.oauth.audience: required when oauth is enabled")
+		if c.Auth.OAuth.JWKSURL != "" {
+			check(validURL(c.Auth.OAuth.JWKSURL), "auth.oauth.jwks_url: %q is not an http(s) URL", c.Auth.OAuth.JWKSURL)
+		}
+		check(c.Auth.OAuth.RefreshInterval >= 0, "auth.oauth.refresh_interval: must not be negative")
+	}
+
+	// Audit, stdout carries the protocol in stdio mode
+	if c.Audit.Enabled {
+		check(c.Server.Mode != "stdio" || (c.Audit.Output != "" && c.Audit.Output != "stdout"), "audit.output: must be stderr or a file path in stdio mode")
+		check(c.Audit.MaxSizeMB >= 0 && c.Audit.MaxBackups >= 0 && c.Audit.MaxValueLength >= 0, "audit: max_size_mb, max_backups and max_value_length must not be negative")
+	}
+
+	return errors.Join(errs...)
+}
+
+// checkBackend validates the endpoint and timeout of the backend configured at path
+func checkBackend(path, endpoint string, timeout int) []error {
+	var errs []error
+	if endpoint == "" {
+		errs = append(errs, fmt.Errorf("%s.endpoint: required when the module is enabled", path))
+	} else if !validURL(endpoint) {
+		errs = append(errs, fmt.Errorf("%s.endpoint: %q is not an http(s) URL", path, endpoint))
+	}
+	if timeout < 0 {
+		errs = append(errs, fmt.Errorf("%s.timeout: must not be negative", path))
+	}
+	return errs
+}
+
+// backend is the endpoint and timeout of a configured backend
+type backend struct {
+	endpoint string
+	timeout  int
+}
+
+// checkDatasources validates the backends of a module that accepts an
+// unnamed backend at module.key, served as datasource "default", and named
+// ones under module.datasources
+func checkDatasources(module, key string, unnamed *backend, named map[string]backend, defaultName string) []error {
+	var errs []error
+	// The unnamed backend is ignored when it has no endpoint and named ones exist
+	if unnamed != nil && unnamed.endpoint == "" && len(named) > 0 {
+		unnamed = nil
+	}
+	if unnamed == nil && len(named) == 0 {
+		return []error{fmt.Errorf("%s.%s.endpoint: required when the module is enabled, or configure %s.datasources", module, key, module)}
+	}
+
+	names := make([]string, 0, len(named))
+	for name := range named {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	if unnamed != nil {
+		errs = append(errs, checkBackend(module+"."+key, unnamed.endpoint, unnamed.timeout)...)
+	}
+	for _, name := range names {
+		errs = append(errs, checkBackend(module+".datasources."+name, named[name].endpoint, named[name].timeout)...)
+	}
+
+	_, hasDefault := named["default"]
+	if unnamed != nil && hasDefault {
+		errs = append(errs, fmt.Errorf("%s.datasources.default: conflicts with %s.%s, which is served as datasource default", module, module, key))
+	}
+	if defaultName != "" {
+		// Viper lowercases map keys but not values
+		_, ok := named[strings.ToLower(defaultName)]
+		if !ok && !(unnamed != nil && strings.ToLower(defaultName) == "default") {
+			errs = append(errs, fmt.Errorf("%s.default_datasource: %q is not a configured datasource", module, defaultName))
+		}
+	}
+	return errs
+}
+
+// validURL reports whether s is an absolute http or https URL
+func validURL(s string) bool {
+	u, err := url.Parse(s)
+	return err == nil && (u.Scheme == "http" || u.Scheme == "https") && u.Host != ""
+}
+
+// oneOf reports whether value is one of values
+func oneOf(value string, values ...string) bool {
+	for _, v := range values {
+		if value == v {
+			return true
+		}
+	}
+	return false
+}
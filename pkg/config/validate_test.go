@@ -0,0 +1,59 @@
+package config
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestValidateOAuth(t *testing.T) {
+	tests := []struct {
+		name    string
+		auth    AuthConfig
+		wantErr []string
+	}{
+		{
+			name: "issuer and audience",
+			auth: AuthConfig{Enabled: true, OAuth: OAuthConfig{Enabled: true, Issuer: "https://idp.example.com", Audience: "ops-mcp-server"}},
+		},
+		{
+			name:    "jwks_url without issuer",
+			auth:    AuthConfig{Enabled: true, OAuth: OAuthConfig{Enabled: true, JWKSURL: "https://idp.example.com/jwks", Audience: "ops-mcp-server"}},
+			wantErr: []string{"auth.oauth.issuer"},
+		},
+		{
+			name:    "missing audience",
+			auth:    AuthConfig{Enabled: true, OAuth: OAuthConfig{Enabled: true, Issuer: "https://idp.example.com"}},
+			wantErr: []string{"auth.oauth.audience"},
+		},
+		{
+			name:    "oauth without auth",
+			auth:    AuthConfig{OAuth: OAuthConfig{Enabled: true, Issuer: "https://idp.example.com", Audience: "ops-mcp-server"}},
+			wantErr: []string{"auth.oauth.enabled: requires auth.enabled"},
+		},
+		{
+			name:    "malformed jwks_url",
+			auth:    AuthConfig{Enabled: true, OAuth: OAuthConfig{Enabled: true, Issuer: "https://idp.example.com", Audience: "ops-mcp-server", JWKSURL: "idp/jwks"}},
+			wantErr: []string{"auth.oauth.jwks_url"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := Config{Auth: tt.auth}
+			err := cfg.Validate()
+			if len(tt.wantErr) == 0 {
+				if err != nil {
+					t.Fatalf("Validate: %v", err)
+				}
+				return
+			}
+			if err == nil {
+				t.Fatalf("Validate accepted the config, want %v", tt.wantErr)
+			}
+			for _, want := range tt.wantErr {
+				if !strings.Contains(err.Error(), want) {
+					t.Errorf("Validate error %q does not report %q", err, want)
+				}
+			}
+		})
+	}
+}
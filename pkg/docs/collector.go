@@ -2,31 +2,25 @@ package docs
 
 import (
 	"encoding/json"
-	"time"
 
 	"github.com/mark3labs/mcp-go/mcp"
 
 	"github.com/shaowenchen/ops-mcp-server/cmd/version"
-	"github.com/shaowenchen/ops-mcp-server/pkg/config"
-	eventsModule "github.com/shaowenchen/ops-mcp-server/pkg/modules/events"
-	logsModule "github.com/shaowenchen/ops-mcp-server/pkg/modules/logs"
-	metricsModule "github.com/shaowenchen/ops-mcp-server/pkg/modules/metrics"
-	sopsModule "github.com/shaowenchen/ops-mcp-server/pkg/modules/sops"
-	tracesModule "github.com/shaowenchen/ops-mcp-server/pkg/modules/traces"
+	"github.com/shaowenchen/ops-mcp-server/pkg/modules"
 	"go.uber.org/zap"
 )
 
 // Collector collects tool information from all enabled modules
 type Collector struct {
-	config *config.Config
-	logger *zap.Logger
+	modules *modules.Set
+	logger  *zap.Logger
 }
 
-// NewCollector creates a new docs collector
-func NewCollector(cfg *config.Config, logger *zap.Logger) *Collector {
+// NewCollector creates a new docs collector for the current module instances
+func NewCollector(set *modules.Set, logger *zap.Logger) *Collector {
 	return &Collector{
-		config: cfg,
-		logger: logger,
+		modules: set,
+		logger:  logger,
 	}
 }
 
@@ -38,44 +32,11 @@ func (c *Collector) CollectToolsInfo() ToolsInfoResponse {
 
 	versionInfo := version.Get()
 
-	// Collect tools from SOPS module
-	if c.config.Sops.Enabled {
-		enabledModules = append(enabledModules, "sops")
-		sopsTools := c.collectSOPSTools()
-		tools = append(tools, sopsTools...)
-		totalTools += len(sopsTools)
-	}
-
-	// Collect tools from Events module
-	if c.config.Events.Enabled {
-		enabledModules = append(enabledModules, "events")
-		eventsTools := c.collectEventsTools()
-		tools = append(tools, eventsTools...)
-		totalTools += len(eventsTools)
-	}
-
-	// Collect tools from Metrics module
-	if c.config.Metrics.Enabled {
-		enabledModules = append(enabledModules, "metrics")
-		metricsTools := c.collectMetricsTools()
-		tools = append(tools, metricsTools...)
-		totalTools += len(metricsTools)
-	}
-
-	// Collect tools from Logs module
-	if c.config.Logs.Enabled {
-		enabledModules = append(enabledModules, "logs")
-		logsTools := c.collectLogsTools()
-		tools = append(tools, logsTools...)
-		totalTools += len(logsTools)
-	}
-
-	// Collect tools from Traces module
-	if c.config.Traces.Enabled {
-		enabledModules = append(enabledModules, "traces")
-		tracesTools := c.collectTracesTools()
-		tools = append(tools, tracesTools...)
-		totalTools += len(tracesTools)
+	for _, instance := range c.modules.Instances() {
+		enabledModules = append(enabledModules, instance.Name)
+		moduleTools := c.collectModuleTools(instance)
+		tools = append(tools, moduleTools...)
+		totalTools += len(moduleTools)
 	}
 
 	return ToolsInfoResponse{
@@ -87,187 +48,20 @@ func (c *Collector) CollectToolsInfo() ToolsInfoResponse {
 	}
 }
 
-// collectSOPSTools collects tools from SOPS module
-func (c *Collector) collectSOPSTools() []ToolInfo {
-	var tools []ToolInfo
-	
-	sopsConfig := &sopsModule.Config{
-		Tools: sopsModule.ToolsConfig{
-			Prefix: c.config.Sops.Tools.Prefix,
-			Suffix: c.config.Sops.Tools.Suffix,
-		},
-	}
-	if c.config.Sops.Ops != nil {
-		sopsConfig.Endpoint = c.config.Sops.Ops.Endpoint
-		sopsConfig.Token = c.config.Sops.Ops.Token
-	}
-	
-	sopsModuleInstance, err := sopsModule.New(sopsConfig, c.logger)
-	if err != nil {
-		c.logger.Error("Failed to create SOPS module for docs", zap.Error(err))
-		return tools
-	}
-	
-	sopsTools := sopsModuleInstance.GetTools()
-	for _, serverTool := range sopsTools {
-		toolInfo := ToolInfo{
-			Name:        serverTool.Tool.Name,
-			Description: serverTool.Tool.Description,
-			Parameters:  convertToolParameters(extractInputSchemaMap(serverTool.Tool)),
-			Module:      "sops",
-		}
-		tools = append(tools, toolInfo)
-	}
-	
-	return tools
-}
-
-// collectEventsTools collects tools from Events module
-func (c *Collector) collectEventsTools() []ToolInfo {
-	var tools []ToolInfo
-	
-	eventsConfig := &eventsModule.Config{
-		PollInterval: 30 * time.Second,
-		Tools: eventsModule.ToolsConfig{
-			Prefix: c.config.Events.Tools.Prefix,
-			Suffix: c.config.Events.Tools.Suffix,
-		},
-	}
-	if c.config.Events.Ops != nil {
-		eventsConfig.Endpoint = c.config.Events.Ops.Endpoint
-		eventsConfig.Token = c.config.Events.Ops.Token
-	}
-	
-	eventsModuleInstance, err := eventsModule.New(eventsConfig, c.logger)
-	if err != nil {
-		c.logger.Error("Failed to create Events module for docs", zap.Error(err))
-		return tools
-	}
-	
-	eventsTools := eventsModuleInstance.GetTools()
-	for _, serverTool := range eventsTools {
-		toolInfo := ToolInfo{
-			Name:        serverTool.Tool.Name,
-			Description: serverTool.Tool.Description,
-			Parameters:  convertToolParameters(extractInputSchemaMap(serverTool.Tool)),
-			Module:      "events",
-		}
-		tools = append(tools, toolInfo)
-	}
-	
-	return tools
-}
-
-// collectMetricsTools collects tools from Metrics module
-func (c *Collector) collectMetricsTools() []ToolInfo {
+// collectModuleTools collects tools from a single module
+func (c *Collector) collectModuleTools(instance *modules.Instance) []ToolInfo {
 	var tools []ToolInfo
-	
-	metricsConfig := &metricsModule.Config{
-		Tools: metricsModule.ToolsConfig{
-			Prefix: c.config.Metrics.Tools.Prefix,
-			Suffix: c.config.Metrics.Tools.Suffix,
-		},
-	}
-	if c.config.Metrics.Prometheus != nil {
-		metricsConfig.Prometheus = &metricsModule.PrometheusConfig{
-			Endpoint: c.config.Metrics.Prometheus.Endpoint,
-		}
-	}
-	
-	metricsModuleInstance, err := metricsModule.New(metricsConfig, c.logger)
-	if err != nil {
-		c.logger.Error("Failed to create Metrics module for docs", zap.Error(err))
-		return tools
-	}
-	
-	metricsTools := metricsModuleInstance.GetTools()
-	for _, serverTool := range metricsTools {
-		toolInfo := ToolInfo{
-			Name:        serverTool.Tool.Name,
-			Description: serverTool.Tool.Description,
-			Parameters:  convertToolParameters(extractInputSchemaMap(serverTool.Tool)),
-			Module:      "metrics",
-		}
-		tools = append(tools, toolInfo)
-	}
-	
-	return tools
-}
 
-// collectLogsTools collects tools from Logs module
-func (c *Collector) collectLogsTools() []ToolInfo {
-	var tools []ToolInfo
-	
-	logsConfig := &logsModule.Config{
-		Tools: logsModule.ToolsConfig{
-			Prefix: c.config.Logs.Tools.Prefix,
-			Suffix: c.config.Logs.Tools.Suffix,
-		},
-	}
-	if c.config.Logs.Elasticsearch != nil {
-		logsConfig.Elasticsearch = &logsModule.ElasticsearchConfig{
-			Endpoint: c.config.Logs.Elasticsearch.Endpoint,
-			Username: c.config.Logs.Elasticsearch.Username,
-			Password: c.config.Logs.Elasticsearch.Password,
-			APIKey:   c.config.Logs.Elasticsearch.APIKey,
-			Timeout:  c.config.Logs.Elasticsearch.Timeout,
-		}
-	}
-	
-	logsModuleInstance, err := logsModule.New(logsConfig, c.logger)
-	if err != nil {
-		c.logger.Error("Failed to create Logs module for docs", zap.Error(err))
-		return tools
-	}
-	
-	logsTools := logsModuleInstance.GetTools()
-	for _, serverTool := range logsTools {
+	for _, serverTool := range instance.Tools {
 		toolInfo := ToolInfo{
 			Name:        serverTool.Tool.Name,
 			Description: serverTool.Tool.Description,
 			Parameters:  convertToolParameters(extractInputSchemaMap(serverTool.Tool)),
-			Module:      "logs",
+			Module:      instance.Name,
 		}
 		tools = append(tools, toolInfo)
 	}
-	
-	return tools
-}
 
-// collectTracesTools collects tools from Traces module
-func (c *Collector) collectTracesTools() []ToolInfo {
-	var tools []ToolInfo
-	
-	tracesConfig := &tracesModule.Config{
-		Tools: tracesModule.ToolsConfig{
-			Prefix: c.config.Traces.Tools.Prefix,
-			Suffix: c.config.Traces.Tools.Suffix,
-		},
-	}
-	if c.config.Traces.Jaeger != nil {
-		tracesConfig.Endpoint = c.config.Traces.Jaeger.Endpoint
-		tracesConfig.Protocol = "HTTP"
-		tracesConfig.Port = 16686
-		tracesConfig.Timeout = c.config.Traces.Jaeger.Timeout
-	}
-	
-	tracesModuleInstance, err := tracesModule.New(tracesConfig, c.logger)
-	if err != nil {
-		c.logger.Error("Failed to create Traces module for docs", zap.Error(err))
-		return tools
-	}
-	
-	tracesTools := tracesModuleInstance.GetTools()
-	for _, serverTool := range tracesTools {
-		toolInfo := ToolInfo{
-			Name:        serverTool.Tool.Name,
-			Description: serverTool.Tool.Description,
-			Parameters:  convertToolParameters(extractInputSchemaMap(serverTool.Tool)),
-			Module:      "traces",
-		}
-		tools = append(tools, toolInfo)
-	}
-	
 	return tools
 }
 
@@ -4,7 +4,7 @@ import (
 	"encoding/json"
 	"net/http"
 
-	"github.com/shaowenchen/ops-mcp-server/pkg/config"
+	"github.com/shaowenchen/ops-mcp-server/pkg/modules"
 	"go.uber.org/zap"
 )
 
@@ -15,9 +15,9 @@ type Handler struct {
 }
 
 // NewHandler creates a new docs handler
-func NewHandler(cfg *config.Config, logger *zap.Logger) *Handler {
+func NewHandler(set *modules.Set, logger *zap.Logger) *Handler {
 	return &Handler{
-		collector: NewCollector(cfg, logger),
+		collector: NewCollector(set, logger),
 		logger:    logger,
 	}
 }
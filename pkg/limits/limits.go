@@ -0,0 +1,246 @@
+package limits
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+	"golang.org/x/time/rate"
+
+	"github.com/shaowenchen/ops-mcp-server/pkg/auth"
+	"github.com/shaowenchen/ops-mcp-server/pkg/config"
+	"github.com/shaowenchen/ops-mcp-server/pkg/metrics"
+)
+
+// anonymous is the identity key of calls without an authenticated caller
+const anonymous = "anonymous"
+
+// concurrencyRetryAfter is the retry hint for calls rejected by a concurrency cap
+const concurrencyRetryAfter = time.Second
+
+// sweepInterval is how often a rule evicts its idle buckets
+const sweepInterval = time.Minute
+
+// rule is a configured limit with its buckets and in-flight counters
+type rule struct {
+	config.LimitRuleConfig
+
+	mu        sync.Mutex
+	buckets   map[string]*bucket
+	inflight  map[string]int
+	lastSweep time.Time
+}
+
+// bucket is the token bucket of a key and when it was last used
+type bucket struct {
+	limiter  *rate.Limiter
+	lastUsed time.Time
+}
+
+// grant is what a call took from a rule: a rate token and a concurrency
+// slot, each only when the rule limits it
+type grant struct {
+	rule        *rule
+	key         string
+	reservation *rate.Reservation
+	concurrent  bool
+}
+
+// Limiter enforces rate limits and concurrency caps on tool calls
+type Limiter struct {
+	rules []*rule
+}
+
+// New creates a limiter from the limits configuration
+func New(cfg config.LimitsConfig) (*Limiter, error) {
+	l := &Limiter{}
+	for i, rc := range cfg.Rules {
+		if rc.Name == "" {
+			rc.Name = fmt.Sprintf("rule-%d", i)
+		}
+		for _, per := range rc.Per {
+			if per != "identity" && per != "module" && per != "tool" {
+				return nil, fmt.Errorf("limit rule %q: invalid per %q, expected identity, module or tool", rc.Name, per)
+			}
+		}
+		if rc.Rate < 0 || rc.Burst < 0 || rc.MaxConcurrent < 0 {
+			return nil, fmt.Errorf("limit rule %q: rate, burst and max_concurrent must not be negative", rc.Name)
+		}
+		if rc.Rate > 0 && rc.Burst == 0 {
+			rc.Burst = int(math.Max(1, math.Ceil(rc.Rate)))
+		}
+		l.rules = append(l.rules, &rule{
+			LimitRuleConfig: rc,
+			buckets:         make(map[string]*bucket),
+			inflight:        make(map[string]int),
+		})
+	}
+	return l, nil
+}
+
+// Middleware applies the limits to every tool call. moduleOf returns the
+// module exposing a tool. Rejected calls return a tool error with
+// a retry-after hint instead of reaching the backend.
+func (l *Limiter) Middleware(moduleOf func(tool string) string) server.ToolHandlerMiddleware {
+	return func(next server.ToolHandlerFunc) server.ToolHandlerFunc {
+		return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			tool := request.Params.Name
+			module := moduleOf(tool)
+			identity := anonymous
+			if id, ok := auth.IdentityFromContext(ctx); ok {
+				identity = id.Name
+			}
+
+			release, result := l.acquire(identity, module, tool)
+			if result != nil {
+				return result, nil
+			}
+			defer release()
+			return next(ctx, request)
+		}
+	}
+}
+
+// acquire checks every matching rule. On success it returns a function
+// releasing the concurrency slots, on rejection a tool error result. A
+// rejected call gives back what the rules checked before took, so it does
+// not count against their limits.
+func (l *Limiter) acquire(identity, module, tool string) (func(), *mcp.CallToolResult) {
+	now := time.Now()
+	var grants []*grant
+	for _, r := range l.rules {
+		if !r.matches(identity, module, tool) {
+			continue
+		}
+		g, retryAfter, reason := r.take(r.key(identity, module, tool), now)
+		if reason != "" {
+			for _, g := range grants {
+				g.cancel(now)
+			}
+			metrics.RecordMCPToolRejected(tool, module, r.Name, reason)
+			return nil, rejection(tool, r.Name, reason, retryAfter)
+		}
+		grants = append(grants, g)
+	}
+	return func() {
+		for _, g := range grants {
+			g.release()
+		}
+	}, nil
+}
+
+// matches reports whether the rule applies to the call
+func (r *rule) matches(identity, module, tool string) bool {
+	return (r.Identity == "" || r.Identity == identity) &&
+		(r.Module == "" || r.Module == module) &&
+		(r.Tool == "" || r.Tool == tool)
+}
+
+// key returns the bucket key of the call according to Per
+func (r *rule) key(identity, module, tool string) string {
+	parts := make([]string, 0, len(r.Per))
+	for _, per := range r.Per {
+		switch per {
+		case "identity":
+			parts = append(parts, identity)
+		case "module":
+			parts = append(parts, module)
+		case "tool":
+			parts = append(parts, tool)
+		}
+	}
+	return strings.Join(parts, "/")
+}
+
+// take consumes a token and a concurrency slot for key at now. On rejection
+// it returns how long to wait and the reason, "rate" or "concurrency".
+func (r *rule) take(key string, now time.Time) (*grant, time.Duration, string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	g := &grant{rule: r, key: key}
+
+	if r.MaxConcurrent > 0 && r.inflight[key] >= r.MaxConcurrent {
+		return nil, concurrencyRetryAfter, "concurrency"
+	}
+
+	if r.Rate > 0 {
+		r.sweep(now)
+		b, ok := r.buckets[key]
+		if !ok {
+			b = &bucket{limiter: rate.NewLimiter(rate.Limit(r.Rate), r.Burst)}
+			r.buckets[key] = b
+		}
+		b.lastUsed = now
+		reservation := b.limiter.ReserveN(now, 1)
+		if delay := reservation.DelayFrom(now); delay > 0 {
+			reservation.CancelAt(now)
+			return nil, delay, "rate"
+		}
+		g.reservation = reservation
+	}
+
+	if r.MaxConcurrent > 0 {
+		r.inflight[g.key]++
+		g.concurrent = true
+	}
+	return g, 0, ""
+}
+
+// release frees the concurrency slot of the grant
+func (g *grant) release() {
+	if !g.concurrent {
+		return
+	}
+	g.rule.mu.Lock()
+	defer g.rule.mu.Unlock()
+	if g.rule.inflight[g.key]--; g.rule.inflight[g.key] <= 0 {
+		delete(g.rule.inflight, g.key)
+	}
+}
+
+// cancel gives back the rate token and the concurrency slot of the grant
+// of a call rejected by another rule
+func (g *grant) cancel(now time.Time) {
+	if g.reservation != nil {
+		g.reservation.CancelAt(now)
+	}
+	g.release()
+}
+
+// sweep evicts the buckets idle long enough to have refilled, which behave
+// like new ones, so keys of callers that went away do not accumulate. It
+// runs at most once per sweepInterval and must be called with r.mu held.
+func (r *rule) sweep(now time.Time) {
+	if now.Sub(r.lastSweep) < sweepInterval {
+		return
+	}
+	r.lastSweep = now
+	refill := time.Duration(float64(r.Burst) / r.Rate * float64(time.Second))
+	for key, b := range r.buckets {
+		if now.Sub(b.lastUsed) >= refill {
+			delete(r.buckets, key)
+		}
+	}
+}
+
+// rejection builds the tool error returned for a rejected call, carrying the
+// retry-after hint both in the text and in the result metadata
+func rejection(tool, ruleName, reason string, retryAfter time.Duration) *mcp.CallToolResult {
+	seconds := math.Ceil(retryAfter.Seconds()*10) / 10
+	message := fmt.Sprintf("rate limit exceeded for tool %s (rule %s), retry after %.1fs", tool, ruleName, seconds)
+	if reason == "concurrency" {
+		message = fmt.Sprintf("too many concurrent calls of tool %s (rule %s), retry after %.1fs", tool, ruleName, seconds)
+	}
+	result := mcp.NewToolResultError(message)
+	result.Meta = mcp.NewMetaFromMap(map[string]any{
+		"retryAfterSeconds": seconds,
+		"limitRule":         ruleName,
+		"limitReason":       reason,
+	})
+	return result
+}
@@ -0,0 +1,118 @@
+package limits
+
+import (
+	"testing"
+	"time"
+
+	"github.com/shaowenchen/ops-mcp-server/pkg/config"
+)
+
+// newRule returns the single rule of a limiter configured with rc
+func newRule(t *testing.T, rc config.LimitRuleConfig) *rule {
+	t.Helper()
+	l, err := New(config.LimitsConfig{Enabled: true, Rules: []config.LimitRuleConfig{rc}})
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	return l.rules[0]
+}
+
+func TestTakeLimitsRate(t *testing.T) {
+	r := newRule(t, config.LimitRuleConfig{Rate: 1, Burst: 2, Per: []string{"identity"}})
+	now := time.Now()
+
+	for i := 0; i < 2; i++ {
+		if _, _, reason := r.take("alice", now); reason != "" {
+			t.Fatalf("call %d rejected for %s, want it within the burst", i+1, reason)
+		}
+	}
+	_, retryAfter, reason := r.take("alice", now)
+	if reason != "rate" {
+		t.Fatalf("call beyond the burst rejected for %q, want rate", reason)
+	}
+	if retryAfter <= 0 || retryAfter > time.Second {
+		t.Errorf("retry after %s, want the time to the next token", retryAfter)
+	}
+	if _, _, reason := r.take("bob", now); reason != "" {
+		t.Errorf("call of another identity rejected for %s, want a bucket per identity", reason)
+	}
+	if _, _, reason := r.take("alice", now.Add(time.Second)); reason != "" {
+		t.Errorf("call after a refill rejected for %s", reason)
+	}
+}
+
+func TestTakeEvictsIdleBuckets(t *testing.T) {
+	// A bucket refills in burst / rate = 200s
+	r := newRule(t, config.LimitRuleConfig{Rate: 0.01, Burst: 2, Per: []string{"identity"}})
+	start := time.Now()
+
+	r.take("alice", start)
+	r.take("alice", start)
+	r.take("bob", start.Add(150*time.Second))
+	r.take("carol", start.Add(240*time.Second))
+
+	if _, ok := r.buckets["alice"]; ok {
+		t.Error("bucket of alice kept, want it evicted once refilled")
+	}
+	for _, key := range []string{"bob", "carol"} {
+		if _, ok := r.buckets[key]; !ok {
+			t.Errorf("bucket of %s evicted before it refilled", key)
+		}
+	}
+
+	// Eviction only drops full buckets, one in use keeps its tokens
+	r.take("bob", start.Add(241*time.Second))
+	later := start.Add(241*time.Second + sweepInterval)
+	r.take("bob", later)
+	if _, _, reason := r.take("bob", later); reason != "rate" {
+		t.Errorf("bucket of bob refilled by a sweep, call rejected for %q", reason)
+	}
+}
+
+func TestTakeLimitsConcurrency(t *testing.T) {
+	r := newRule(t, config.LimitRuleConfig{MaxConcurrent: 1})
+	now := time.Now()
+
+	g, _, reason := r.take("", now)
+	if reason != "" {
+		t.Fatalf("first call rejected for %s", reason)
+	}
+	if _, retryAfter, reason := r.take("", now); reason != "concurrency" || retryAfter != concurrencyRetryAfter {
+		t.Errorf("concurrent call rejected for %q after %s, want concurrency after %s", reason, retryAfter, concurrencyRetryAfter)
+	}
+	g.release()
+	if _, _, reason := r.take("", now); reason != "" {
+		t.Errorf("call after release rejected for %s", reason)
+	}
+	if len(r.buckets) != 0 {
+		t.Errorf("%d buckets created without a rate", len(r.buckets))
+	}
+}
+
+func TestAcquireRefundsRejectedCalls(t *testing.T) {
+	l, err := New(config.LimitsConfig{Enabled: true, Rules: []config.LimitRuleConfig{
+		{Name: "per-identity", Rate: 0.001, Burst: 2, MaxConcurrent: 5, Per: []string{"identity"}},
+		{Name: "logs", Module: "logs", MaxConcurrent: 1},
+	}})
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+
+	release, result := l.acquire("alice", "logs", "search-logs")
+	if result != nil {
+		t.Fatalf("first call rejected: %v", result.Content)
+	}
+	// The logs cap rejects the second call after the identity rule let it through
+	if _, result := l.acquire("alice", "logs", "search-logs"); result == nil {
+		t.Fatal("call beyond the logs concurrency cap allowed")
+	}
+	if inflight := l.rules[0].inflight["alice"]; inflight != 1 {
+		t.Errorf("%d calls in flight for alice, want the rejected one released", inflight)
+	}
+	release()
+
+	// The rejected call did not spend the second token of the burst
+	if _, result := l.acquire("alice", "metrics", "query-metrics"); result != nil {
+		t.Errorf("call within the burst rejected: %v", result.Content)
+	}
+}
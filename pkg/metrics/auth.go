@@ -4,8 +4,9 @@ import (
 	"time"
 )
 
-// RecordAuthRequest records an authentication request
-func RecordAuthRequest(success bool, skipped bool) {
+// RecordAuthRequest records an authentication request. token is the name of
+// the presented token, empty when no known token was presented.
+func RecordAuthRequest(success bool, skipped bool, token string) {
 	m := Get()
 	if m == nil {
 		return
@@ -18,7 +19,7 @@ func RecordAuthRequest(success bool, skipped bool) {
 		status = "success"
 	}
 
-	m.AuthRequestsTotal.WithLabelValues(status).Inc()
+	m.AuthRequestsTotal.WithLabelValues(status, token).Inc()
 }
 
 // RecordAuthValidationDuration records authentication validation duration
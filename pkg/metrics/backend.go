@@ -12,10 +12,12 @@ const (
 	BackendElasticsearch BackendType = "elasticsearch"
 	BackendJaeger       BackendType = "jaeger"
 	BackendOps          BackendType = "ops"
+	BackendAlertmanager BackendType = "alertmanager"
 )
 
-// RecordBackendRequest records a backend service request
-func RecordBackendRequest(backend BackendType, duration time.Duration, success bool) {
+// RecordBackendRequest records a request to the backend instance, the
+// datasource name for modules with several instances of a backend
+func RecordBackendRequest(backend BackendType, instance string, duration time.Duration, success bool) {
 	m := Get()
 	if m == nil {
 		return
@@ -26,15 +28,15 @@ func RecordBackendRequest(backend BackendType, duration time.Duration, success b
 		status = "success"
 	}
 
-	m.BackendRequestsTotal.WithLabelValues(string(backend), status).Inc()
-	m.BackendRequestDuration.WithLabelValues(string(backend)).Observe(duration.Seconds())
+	m.BackendRequestsTotal.WithLabelValues(string(backend), instance, status).Inc()
+	m.BackendRequestDuration.WithLabelValues(string(backend), instance).Observe(duration.Seconds())
 }
 
-// RecordBackendError records a backend service error
-func RecordBackendError(backend BackendType, errorType string) {
+// RecordBackendError records an error of the backend instance
+func RecordBackendError(backend BackendType, instance string, errorType string) {
 	m := Get()
 	if m != nil {
-		m.BackendErrorsTotal.WithLabelValues(string(backend), errorType).Inc()
+		m.BackendErrorsTotal.WithLabelValues(string(backend), instance, errorType).Inc()
 	}
 }
 
@@ -0,0 +1,13 @@
+package metrics
+
+// RecordConfigReload records a configuration reload attempt
+func RecordConfigReload(success bool) {
+	m := Get()
+	if m != nil {
+		status := "success"
+		if !success {
+			status = "failure"
+		}
+		m.ConfigReloadsTotal.WithLabelValues(status).Inc()
+	}
+}
@@ -24,6 +24,7 @@ type Metrics struct {
 	MCPToolCallsTotal       *prometheus.CounterVec
 	MCPToolCallDuration     *prometheus.HistogramVec
 	MCPToolErrorsTotal      *prometheus.CounterVec
+	MCPToolRejectedTotal    *prometheus.CounterVec
 
 	// Module metrics
 	ModuleEnabled           *prometheus.GaugeVec
@@ -38,6 +39,9 @@ type Metrics struct {
 	AuthRequestsTotal       *prometheus.CounterVec
 	AuthValidationDuration  prometheus.Histogram
 
+	// Config metrics
+	ConfigReloadsTotal      *prometheus.CounterVec
+
 	// System metrics
 	ProcessGoroutines        prometheus.Gauge
 	ProcessMemoryBytes       *prometheus.GaugeVec
@@ -156,6 +160,14 @@ func Init(logger *zap.Logger) *Metrics {
 		[]string{"tool_name", "module", "error_type"},
 	)
 
+	m.MCPToolRejectedTotal = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "ops_mcp_server_mcp_tool_rejected_total",
+			Help: "Total number of MCP tool calls rejected by rate limits or concurrency caps",
+		},
+		[]string{"tool_name", "module", "rule", "reason"},
+	)
+
 	// Module metrics
 	m.ModuleEnabled = promauto.NewGaugeVec(
 		prometheus.GaugeOpts{
@@ -179,7 +191,7 @@ func Init(logger *zap.Logger) *Metrics {
 			Name: "ops_mcp_server_backend_requests_total",
 			Help: "Total number of backend service requests",
 		},
-		[]string{"backend", "status"},
+		[]string{"backend", "instance", "status"},
 	)
 
 	m.BackendRequestDuration = promauto.NewHistogramVec(
@@ -188,7 +200,7 @@ func Init(logger *zap.Logger) *Metrics {
 			Help:    "Backend service request duration in seconds",
 			Buckets: prometheus.DefBuckets,
 		},
-		[]string{"backend"},
+		[]string{"backend", "instance"},
 	)
 
 	m.BackendErrorsTotal = promauto.NewCounterVec(
@@ -196,7 +208,7 @@ func Init(logger *zap.Logger) *Metrics {
 			Name: "ops_mcp_server_backend_errors_total",
 			Help: "Total number of backend service errors",
 		},
-		[]string{"backend", "error_type"},
+		[]string{"backend", "instance", "error_type"},
 	)
 
 	// Auth metrics
@@ -205,7 +217,7 @@ func Init(logger *zap.Logger) *Metrics {
 			Name: "ops_mcp_server_auth_requests_total",
 			Help: "Total number of authentication requests",
 		},
-		[]string{"status"},
+		[]string{"status", "token"},
 	)
 
 	m.AuthValidationDuration = promauto.NewHistogram(
@@ -216,6 +228,15 @@ func Init(logger *zap.Logger) *Metrics {
 		},
 	)
 
+	// Config metrics
+	m.ConfigReloadsTotal = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "ops_mcp_server_config_reloads_total",
+			Help: "Total number of configuration reloads",
+		},
+		[]string{"status"},
+	)
+
 	// System metrics
 	m.ProcessGoroutines = promauto.NewGauge(
 		prometheus.GaugeOpts{
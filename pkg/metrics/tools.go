@@ -28,6 +28,15 @@ func RecordMCPToolError(toolName, module, errorType string) {
 	}
 }
 
+// RecordMCPToolRejected records an MCP tool call rejected by a limit rule,
+// reason is "rate" or "concurrency"
+func RecordMCPToolRejected(toolName, module, rule, reason string) {
+	m := Get()
+	if m != nil {
+		m.MCPToolRejectedTotal.WithLabelValues(toolName, module, rule, reason).Inc()
+	}
+}
+
 // RecordModuleRequest records a module request
 func RecordModuleRequest(moduleName string) {
 	m := Get()
@@ -2,6 +2,7 @@ package metrics
 
 import (
 	"context"
+	"errors"
 	"strings"
 	"time"
 
@@ -28,25 +29,34 @@ func WrapToolHandler(handler func(context.Context, mcp.CallToolRequest) (*mcp.Ca
 
 		// Record error if any (either handler returned error or result has IsError=true)
 		if err != nil || (result != nil && result.IsError) {
-			errorType := "unknown"
-			if err.Error() != "" {
-				// Try to categorize error
-				errStr := strings.ToLower(err.Error())
-				if strings.Contains(errStr, "not found") {
-					errorType = "not_found"
-				} else if strings.Contains(errStr, "timeout") || strings.Contains(errStr, "deadline") {
-					errorType = "timeout"
-				} else if strings.Contains(errStr, "unauthorized") || strings.Contains(errStr, "forbidden") {
-					errorType = "auth_error"
-				} else if strings.Contains(errStr, "invalid") {
-					errorType = "invalid_input"
-				} else if strings.Contains(errStr, "connection") || strings.Contains(errStr, "network") {
-					errorType = "network_error"
-				}
-			}
-			RecordMCPToolError(toolName, moduleName, errorType)
+			RecordMCPToolError(toolName, moduleName, ClassifyError(err))
 		}
 
 		return result, err
 	}
 }
+
+// ClassifyError categorizes a tool error for metrics and the audit log
+func ClassifyError(err error) string {
+	errorType := "unknown"
+	if errors.Is(err, context.Canceled) {
+		// The client cancelled the call with notifications/cancelled or went away
+		return "cancelled"
+	}
+	if err != nil && err.Error() != "" {
+		// Try to categorize error
+		errStr := strings.ToLower(err.Error())
+		if strings.Contains(errStr, "not found") {
+			errorType = "not_found"
+		} else if strings.Contains(errStr, "timeout") || strings.Contains(errStr, "deadline") {
+			errorType = "timeout"
+		} else if strings.Contains(errStr, "unauthorized") || strings.Contains(errStr, "forbidden") || strings.Contains(errStr, "not allowed") {
+			errorType = "auth_error"
+		} else if strings.Contains(errStr, "invalid") {
+			errorType = "invalid_input"
+		} else if strings.Contains(errStr, "connection") || strings.Contains(errStr, "network") {
+			errorType = "network_error"
+		}
+	}
+	return errorType
+}
@@ -0,0 +1,118 @@
+package modules
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// CompletionTTL is how long values fetched from a backend for completions
+// are reused, so typing an argument does not query the backend on every key
+const CompletionTTL = 30 * time.Second
+
+// maxCompletionValues is the most values a completion may return
+const maxCompletionValues = 100
+
+// maxCompletionEntries is the most keys a completion cache holds, the ones
+// fetched the longest ago are dropped first
+const maxCompletionEntries = 256
+
+// CompletionCache caches completion candidates by key for a short time
+type CompletionCache struct {
+	ttl     time.Duration
+	mu      sync.Mutex
+	entries map[string]completionEntry
+}
+
+// completionEntry holds the candidates fetched at a point in time
+type completionEntry struct {
+	values  []string
+	fetched time.Time
+}
+
+// NewCompletionCache creates a cache keeping values for ttl
+func NewCompletionCache(ttl time.Duration) *CompletionCache {
+	return &CompletionCache{ttl: ttl, entries: make(map[string]completionEntry)}
+}
+
+// Get returns the values cached under key, calling fetch when they are
+// missing or expired. Failed fetches are not cached. Storing values drops
+// the expired entries and, beyond maxCompletionEntries, the oldest ones.
+func (c *CompletionCache) Get(ctx context.Context, key string, fetch func(ctx context.Context) ([]string, error)) ([]string, error) {
+	c.mu.Lock()
+	entry, ok := c.entries[key]
+	c.mu.Unlock()
+	if ok && time.Since(entry.fetched) < c.ttl {
+		return entry.values, nil
+	}
+
+	values, err := fetch(ctx)
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	c.mu.Lock()
+	c.entries[key] = completionEntry{values: values, fetched: now}
+	c.evict(now)
+	c.mu.Unlock()
+	return values, nil
+}
+
+// evict drops the expired entries, then the oldest ones while the cache
+// holds more than maxCompletionEntries. The caller holds c.mu.
+func (c *CompletionCache) evict(now time.Time) {
+	for key, entry := range c.entries {
+		if now.Sub(entry.fetched) >= c.ttl {
+			delete(c.entries, key)
+		}
+	}
+	if len(c.entries) <= maxCompletionEntries {
+		return
+	}
+	keys := make([]string, 0, len(c.entries))
+	for key := range c.entries {
+		keys = append(keys, key)
+	}
+	sort.Slice(keys, func(i, j int) bool { return c.entries[keys[i]].fetched.Before(c.entries[keys[j]].fetched) })
+	for _, key := range keys[:len(keys)-maxCompletionEntries] {
+		delete(c.entries, key)
+	}
+}
+
+// Complete returns the candidates matching what was typed so far, those
+// starting with it first, then those containing it, ignoring case
+func Complete(candidates []string, typed string) *mcp.Completion {
+	typed = strings.ToLower(typed)
+	seen := make(map[string]bool)
+	var prefixed, contained []string
+	for _, candidate := range candidates {
+		if seen[candidate] {
+			continue
+		}
+		seen[candidate] = true
+		lower := strings.ToLower(candidate)
+		switch {
+		case strings.HasPrefix(lower, typed):
+			prefixed = append(prefixed, candidate)
+		case strings.Contains(lower, typed):
+			contained = append(contained, candidate)
+		}
+	}
+	sort.Strings(prefixed)
+	sort.Strings(contained)
+
+	values := append(prefixed, contained...)
+	completion := &mcp.Completion{Values: values, Total: len(values)}
+	if len(values) > maxCompletionValues {
+		completion.Values = values[:maxCompletionValues]
+		completion.HasMore = true
+	}
+	if completion.Values == nil {
+		completion.Values = []string{}
+	}
+	return completion
+}
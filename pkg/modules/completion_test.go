@@ -0,0 +1,40 @@
+package modules
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+)
+
+func fetchValues(values ...string) func(ctx context.Context) ([]string, error) {
+	return func(ctx context.Context) ([]string, error) {
+		return values, nil
+	}
+}
+
+func TestCompletionCacheDropsExpiredEntries(t *testing.T) {
+	cache := NewCompletionCache(10 * time.Millisecond)
+	cache.Get(context.Background(), "operations/web", fetchValues("GET /"))
+	time.Sleep(20 * time.Millisecond)
+	cache.Get(context.Background(), "services", fetchValues("web"))
+
+	if _, ok := cache.entries["operations/web"]; ok || len(cache.entries) != 1 {
+		t.Errorf("cache holds %d entries, want the expired one dropped", len(cache.entries))
+	}
+}
+
+func TestCompletionCacheIsBounded(t *testing.T) {
+	cache := NewCompletionCache(time.Hour)
+	for i := 0; i < maxCompletionEntries+10; i++ {
+		cache.Get(context.Background(), fmt.Sprintf("operations/%d", i), fetchValues("GET /"))
+	}
+
+	if len(cache.entries) != maxCompletionEntries {
+		t.Errorf("cache holds %d entries, want at most %d", len(cache.entries), maxCompletionEntries)
+	}
+	last := fmt.Sprintf("operations/%d", maxCompletionEntries+9)
+	if _, ok := cache.entries[last]; !ok {
+		t.Errorf("latest entry %s dropped", last)
+	}
+}
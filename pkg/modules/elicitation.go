@@ -0,0 +1,21 @@
+package modules
+
+import (
+	"context"
+
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// ElicitationSession returns the session of the request when its client
+// declared support for elicitation
+func ElicitationSession(ctx context.Context) (server.SessionWithElicitation, bool) {
+	session := server.ClientSessionFromContext(ctx)
+	elicitation, ok := session.(server.SessionWithElicitation)
+	if !ok {
+		return nil, false
+	}
+	if info, ok := session.(server.SessionWithClientInfo); ok && info.GetClientCapabilities().Elicitation == nil {
+		return nil, false
+	}
+	return elicitation, true
+}
@@ -0,0 +1,57 @@
+package events
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+)
+
+// completionPageSize is how many event types are fetched for completion
+const completionPageSize = "1000"
+
+// CompleteArgument suggests the subject patterns of the event types listed by the API
+func (m *Module) CompleteArgument(ctx context.Context, name string, args map[string]string) ([]string, error) {
+	if name != "subject_pattern" || m.config.Endpoint == "" {
+		return nil, nil
+	}
+	return m.completions.Get(ctx, "subjects", m.fetchEventTypes)
+}
+
+// fetchEventTypes lists the event type subjects
+func (m *Module) fetchEventTypes(ctx context.Context) ([]string, error) {
+	resp, err := m.makeRequest(ctx, "GET", "/api/v1/events?page=1&page_size="+completionPageSize, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response body: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("list events API returned status %d", resp.StatusCode)
+	}
+	return parseEventTypes(body)
+}
+
+// parseEventTypes reads the event type subjects of a list events response
+// from event_types, data or a plain array depending on the API version
+func parseEventTypes(body []byte) ([]string, error) {
+	var subjects []string
+	if err := json.Unmarshal(body, &subjects); err == nil {
+		return subjects, nil
+	}
+	var response map[string]json.RawMessage
+	if err := json.Unmarshal(body, &response); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal response: %w", err)
+	}
+	for _, key := range []string{"event_types", "data"} {
+		if err := json.Unmarshal(response[key], &subjects); err == nil {
+			return subjects, nil
+		}
+	}
+	return nil, nil
+}
@@ -13,6 +13,8 @@ import (
 
 	"github.com/mark3labs/mcp-go/mcp"
 	"github.com/mark3labs/mcp-go/server"
+	"github.com/shaowenchen/ops-mcp-server/pkg/config"
+	"github.com/shaowenchen/ops-mcp-server/pkg/modules"
 	"go.uber.org/zap"
 )
 
@@ -20,6 +22,8 @@ import (
 type ToolsConfig struct {
 	Prefix string `mapstructure:"prefix" json:"prefix" yaml:"prefix"`
 	Suffix string `mapstructure:"suffix" json:"suffix" yaml:"suffix"`
+	// Overrides change individual tools, keyed by their default name
+	Overrides map[string]config.ToolOverride `mapstructure:"overrides" json:"overrides" yaml:"overrides"`
 }
 
 // Config contains events module configuration
@@ -35,6 +39,9 @@ type Module struct {
 	config     *Config
 	logger     *zap.Logger
 	httpClient *http.Client
+	tools      EventsToolsConfig
+	// completions caches the event subjects for completion
+	completions *modules.CompletionCache
 }
 
 // New creates a new events module
@@ -60,6 +67,7 @@ func New(config *Config, logger *zap.Logger) (*Module, error) {
 			Transport: transport,
 			Timeout:   60 * time.Second, // Event queries may need more time
 		},
+		completions: modules.NewCompletionCache(modules.CompletionTTL),
 	}
 
 	m.logger.Info("Events module created",
@@ -69,6 +77,11 @@ func New(config *Config, logger *zap.Logger) (*Module, error) {
 		zap.Bool("ops_configured", config.Endpoint != ""),
 	)
 
+	m.tools = GetDefaultToolsConfig()
+	if err := modules.ApplyToolOverrides("events", m.tools.toolConfigs(), m.config.Tools.Overrides, m.BuildToolName, m.logger); err != nil {
+		return nil, err
+	}
+
 	return m, nil
 }
 
@@ -121,7 +134,9 @@ func (m *Module) makeRequestWithFullURL(ctx context.Context, method, url string,
 		req.Header.Set("Authorization", "Bearer "+m.config.Token)
 	}
 
+	waiting := modules.ProgressFromContext(ctx).Wait("Waiting for the events API")
 	resp, err := m.httpClient.Do(req)
+	waiting()
 	if err != nil {
 		m.logger.Error("Events API Request Failed",
 			zap.String("method", method),
@@ -315,14 +330,15 @@ func (m *Module) fetchEventsFromAPI(ctx context.Context, req EventsListRequest)
 
 // GetTools returns MCP tools for events (pods, deployments, nodes, etc.)
 func (m *Module) GetTools() []server.ServerTool {
-	// Get default tool configuration
-	toolsConfig := GetDefaultToolsConfig()
-
-	// Tool configuration can be modified based on config file or other conditions
-	// For example: disable certain tools based on m.config
-	// toolsConfig.PodEvents.Enabled = false
+	return m.BuildTools(m.tools)
+}
 
-	return m.BuildTools(toolsConfig)
+// HealthCheck reports whether the events endpoint is configured
+func (m *Module) HealthCheck() error {
+	if m.config.Endpoint == "" {
+		return fmt.Errorf("events endpoint not configured - please set events.ops.endpoint in config")
+	}
+	return nil
 }
 
 // Tool handlers
@@ -405,17 +421,19 @@ func (m *Module) handleListEvents(ctx context.Context, request mcp.CallToolReque
 		return nil, fmt.Errorf("failed to read response body: %w", err)
 	}
 
-	// Log response summary
+	eventTypes, err := parseEventTypes(body)
+	if err != nil {
+		return nil, err
+	}
 
-	// Return the raw response from the API
-	return &mcp.CallToolResult{
-		Content: []mcp.Content{
-			mcp.TextContent{
-				Type: "text",
-				Text: string(body),
-			},
-		},
-	}, nil
+	// Return the typed event types along with the raw response from the API
+	return modules.StructuredResult(EventTypesResponse{
+		EventTypes: eventTypes,
+		Count:      len(eventTypes),
+		Page:       page,
+		PageSize:   pageSize,
+		Search:     search,
+	}, string(body))
 }
 
 func (m *Module) handleGetEvents(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
@@ -467,19 +485,5 @@ func (m *Module) handleGetEvents(ctx context.Context, request mcp.CallToolReques
 		return nil, fmt.Errorf("failed to fetch events: %w", err)
 	}
 
-	// Log response summary
-
-	data, err := json.Marshal(response)
-	if err != nil {
-		return nil, fmt.Errorf("failed to marshal response: %w", err)
-	}
-
-	return &mcp.CallToolResult{
-		Content: []mcp.Content{
-			mcp.TextContent{
-				Type: "text",
-				Text: string(data),
-			},
-		},
-	}, nil
+	return modules.StructuredResult(response, "")
 }
@@ -0,0 +1,37 @@
+package events
+
+import (
+	"time"
+
+	"github.com/shaowenchen/ops-mcp-server/pkg/config"
+	"github.com/shaowenchen/ops-mcp-server/pkg/modules"
+	"go.uber.org/zap"
+)
+
+func init() {
+	modules.Register(modules.Factory{
+		Name:    "events",
+		Section: "events",
+		Enabled: func(cfg *config.Config) *bool { return &cfg.Events.Enabled },
+		New: func(cfg *config.Config, logger *zap.Logger) (modules.Module, error) {
+			return New(ConfigFrom(cfg), logger)
+		},
+	})
+}
+
+// ConfigFrom builds the events module configuration from the server configuration
+func ConfigFrom(cfg *config.Config) *Config {
+	eventsConfig := &Config{
+		PollInterval: 30 * time.Second, // default poll interval
+		Tools: ToolsConfig{
+			Prefix:    cfg.Events.Tools.Prefix,
+			Suffix:    cfg.Events.Tools.Suffix,
+			Overrides: cfg.Events.Tools.Overrides,
+		},
+	}
+	if cfg.Events.Ops != nil {
+		eventsConfig.Endpoint = cfg.Events.Ops.Endpoint
+		eventsConfig.Token = cfg.Events.Ops.Token
+	}
+	return eventsConfig
+}
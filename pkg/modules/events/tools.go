@@ -4,14 +4,11 @@ import (
 	"github.com/mark3labs/mcp-go/mcp"
 	"github.com/mark3labs/mcp-go/server"
 	"github.com/shaowenchen/ops-mcp-server/pkg/metrics"
+	"github.com/shaowenchen/ops-mcp-server/pkg/modules"
 )
 
 // ToolConfig defines configuration for a single tool
-type ToolConfig struct {
-	Enabled     bool   // Whether the tool is enabled
-	Name        string // Tool name
-	Description string // Tool description
-}
+type ToolConfig = modules.ToolConfig
 
 // EventsToolsConfig defines configuration for all tools
 type EventsToolsConfig struct {
@@ -80,6 +77,7 @@ func (m *Module) buildListEventsToolDefinition(config ToolConfig) mcp.Tool {
 		mcp.WithString("search", mcp.Description("Search term to filter event types (optional)")),
 		mcp.WithString("page_size", mcp.Description("Number of event types to return (default: 10)")),
 		mcp.WithString("page", mcp.Description("Page number for pagination (default: 1)")),
+		modules.OutputSchema[EventTypesResponse](nil),
 	)
 }
 
@@ -90,5 +88,18 @@ func (m *Module) buildGetEventsToolDefinition(config ToolConfig) mcp.Tool {
 		mcp.WithString("page_size", mcp.Description("Number of events per page (default: 10)")),
 		mcp.WithString("page", mcp.Description("Page number for pagination (default: 1)")),
 		mcp.WithString("start_time", mcp.Description("Start time for filtering events (timestamp, eg, 1758928888000)")),
+		modules.OutputSchema[EventsListResponse](nil),
 	)
 }
+
+// toolConfigs returns every tool of the configuration
+func (c *EventsToolsConfig) toolConfigs() []*ToolConfig {
+	return []*ToolConfig{&c.ListEvents, &c.GetEvents}
+}
+
+// ToolNamesByDefault maps the default name of each enabled tool to the name
+// it is exposed under, after overrides, prefix and suffix
+func (m *Module) ToolNamesByDefault() map[string]string {
+	defaults := GetDefaultToolsConfig()
+	return modules.ToolNamesByDefault(defaults.toolConfigs(), m.tools.toolConfigs(), m.BuildToolName)
+}
@@ -155,3 +155,12 @@ type EventsListResponse struct {
 		Total    int             `json:"total" yaml:"total"`
 	} `json:"data" yaml:"data"`
 }
+
+// EventTypesResponse represents the event types listed by the API
+type EventTypesResponse struct {
+	EventTypes []string `json:"event_types"`
+	Count      int      `json:"count"`
+	Page       int      `json:"page"`
+	PageSize   int      `json:"page_size"`
+	Search     string   `json:"search,omitempty"`
+}
@@ -0,0 +1,54 @@
+package logs
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+)
+
+// CompleteArgument suggests datasource names and the indices of the
+// datasource selected in args
+func (m *Module) CompleteArgument(ctx context.Context, name string, args map[string]string) ([]string, error) {
+	switch name {
+	case "datasource":
+		return m.datasourceNames, nil
+	case "index":
+		ds, err := m.datasource(map[string]interface{}{"datasource": args["datasource"]})
+		if err != nil {
+			// Nothing to complete until a known datasource is chosen
+			return nil, nil
+		}
+		return m.completions.Get(ctx, "indices/"+ds.name, func(ctx context.Context) ([]string, error) {
+			return m.fetchIndexNames(ctx, ds)
+		})
+	}
+	return nil, nil
+}
+
+// fetchIndexNames lists the names of the indices of ds
+func (m *Module) fetchIndexNames(ctx context.Context, ds *datasource) ([]string, error) {
+	resp, err := m.makeElasticsearchRequest(ctx, ds, "GET", "_cat/indices?format=json&h=index&s=index", nil)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response: %w", err)
+	}
+	if resp.StatusCode >= 400 {
+		return nil, fmt.Errorf("Elasticsearch returned status %d", resp.StatusCode)
+	}
+
+	var indices []ElasticsearchIndex
+	if err := json.Unmarshal(body, &indices); err != nil {
+		return nil, fmt.Errorf("failed to parse JSON response: %w", err)
+	}
+	names := make([]string, 0, len(indices))
+	for _, index := range indices {
+		names = append(names, index.Index)
+	}
+	return names, nil
+}
@@ -9,6 +9,7 @@ import (
 	"net/http"
 	"net/url"
 	"regexp"
+	"sort"
 	"strconv"
 	"strings"
 	"time"
@@ -17,6 +18,9 @@ import (
 
 	"github.com/mark3labs/mcp-go/mcp"
 	"github.com/mark3labs/mcp-go/server"
+	"github.com/shaowenchen/ops-mcp-server/pkg/config"
+	appMetrics "github.com/shaowenchen/ops-mcp-server/pkg/metrics"
+	"github.com/shaowenchen/ops-mcp-server/pkg/modules"
 	"go.uber.org/zap"
 )
 
@@ -24,13 +28,19 @@ import (
 type ToolsConfig struct {
 	Prefix string `mapstructure:"prefix" json:"prefix" yaml:"prefix"`
 	Suffix string `mapstructure:"suffix" json:"suffix" yaml:"suffix"`
+	// Overrides change individual tools, keyed by their default name
+	Overrides map[string]config.ToolOverride `mapstructure:"overrides" json:"overrides" yaml:"overrides"`
 }
 
 // Config contains logs module configuration
 type Config struct {
-	// Elasticsearch configuration - required
+	// Elasticsearch is the unnamed cluster, served as datasource "default"
 	Elasticsearch *ElasticsearchConfig `mapstructure:"elasticsearch" json:"elasticsearch" yaml:"elasticsearch"`
-	Tools         ToolsConfig          `mapstructure:"tools" json:"tools" yaml:"tools"`
+	// Datasources are named Elasticsearch clusters, e.g. one per region
+	Datasources map[string]*ElasticsearchConfig `mapstructure:"datasources" json:"datasources" yaml:"datasources"`
+	// DefaultDatasource is searched when a tool call names no datasource
+	DefaultDatasource string      `mapstructure:"default_datasource" json:"default_datasource" yaml:"default_datasource"`
+	Tools             ToolsConfig `mapstructure:"tools" json:"tools" yaml:"tools"`
 }
 
 // ElasticsearchConfig contains elasticsearch backend configuration
@@ -42,11 +52,25 @@ type ElasticsearchConfig struct {
 	Timeout  int    `mapstructure:"timeout" json:"timeout" yaml:"timeout"`
 }
 
+// defaultDatasourceName is the datasource name of the unnamed Elasticsearch cluster
+const defaultDatasourceName = "default"
+
+// datasource is an Elasticsearch cluster with its HTTP client
+type datasource struct {
+	name       string
+	config     *ElasticsearchConfig
+	httpClient *http.Client
+}
+
 // Module represents the logs module
 type Module struct {
-	config     *Config
-	logger     *zap.Logger
-	httpClient *http.Client
+	config            *Config
+	logger            *zap.Logger
+	datasources       map[string]*datasource
+	datasourceNames   []string
+	defaultDatasource string
+	tools             LogsToolsConfig
+	completions       *modules.CompletionCache
 }
 
 // New creates a new logs module
@@ -56,10 +80,63 @@ func New(config *Config, logger *zap.Logger) (*Module, error) {
 	}
 
 	// Elasticsearch configuration is optional - module can be created without it
+	m := &Module{
+		config:      config,
+		logger:      logger.Named("logs"),
+		datasources: make(map[string]*datasource),
+		completions: modules.NewCompletionCache(modules.CompletionTTL),
+	}
 
+	if config.Elasticsearch != nil && config.Elasticsearch.Endpoint != "" {
+		m.addDatasource(defaultDatasourceName, config.Elasticsearch)
+	}
+	for name, elasticsearch := range config.Datasources {
+		if elasticsearch == nil {
+			continue
+		}
+		if _, exists := m.datasources[name]; exists {
+			return nil, fmt.Errorf("logs datasource %q conflicts with the unnamed elasticsearch cluster", name)
+		}
+		m.addDatasource(name, elasticsearch)
+	}
+	sort.Strings(m.datasourceNames)
+
+	// Viper lowercases map keys, so names are matched case-insensitively
+	defaultName := strings.ToLower(config.DefaultDatasource)
+	switch {
+	case defaultName != "":
+		if _, ok := m.datasources[defaultName]; !ok {
+			return nil, fmt.Errorf("logs default datasource %q is not configured", config.DefaultDatasource)
+		}
+		m.defaultDatasource = defaultName
+	case m.datasources[defaultDatasourceName] != nil:
+		m.defaultDatasource = defaultDatasourceName
+	case len(m.datasourceNames) == 1:
+		m.defaultDatasource = m.datasourceNames[0]
+	}
+
+	if len(m.datasources) > 0 {
+		m.logger.Info("Logs module created with Elasticsearch backend",
+			zap.Strings("datasources", m.datasourceNames),
+			zap.String("default_datasource", m.defaultDatasource),
+		)
+	} else {
+		m.logger.Info("Logs module created without Elasticsearch configuration - tools will return configuration required error")
+	}
+
+	m.tools = GetDefaultToolsConfig()
+	if err := modules.ApplyToolOverrides("logs", m.tools.toolConfigs(), m.config.Tools.Overrides, m.BuildToolName, m.logger); err != nil {
+		return nil, err
+	}
+
+	return m, nil
+}
+
+// addDatasource registers an Elasticsearch cluster under name
+func (m *Module) addDatasource(name string, config *ElasticsearchConfig) {
 	timeout := 120 * time.Second // Increase default timeout to 120 seconds
-	if config.Elasticsearch != nil && config.Elasticsearch.Timeout > 0 {
-		timeout = time.Duration(config.Elasticsearch.Timeout) * time.Second
+	if config.Timeout > 0 {
+		timeout = time.Duration(config.Timeout) * time.Second
 	}
 
 	// Create HTTP client - each request uses a new connection, closes after request
@@ -72,49 +149,105 @@ func New(config *Config, logger *zap.Logger) (*Module, error) {
 		ResponseHeaderTimeout: timeout,
 	}
 
-	m := &Module{
+	m.datasources[name] = &datasource{
+		name:   name,
 		config: config,
-		logger: logger.Named("logs"),
 		httpClient: &http.Client{
 			Transport: transport,
 			Timeout:   timeout, // Use configured timeout for client
 		},
 	}
+	m.datasourceNames = append(m.datasourceNames, name)
 
-	if config.Elasticsearch != nil && config.Elasticsearch.Endpoint != "" {
-		m.logger.Info("Logs module created with Elasticsearch backend",
-			zap.String("endpoint", config.Elasticsearch.Endpoint),
-			zap.Duration("timeout", timeout),
-		)
-	} else {
-		m.logger.Info("Logs module created without Elasticsearch configuration - tools will return configuration required error")
-	}
+	m.logger.Info("Elasticsearch datasource configured",
+		zap.String("datasource", name),
+		zap.String("endpoint", config.Endpoint),
+		zap.Duration("timeout", timeout),
+	)
+}
 
-	return m, nil
+// datasource returns the datasource named by the datasource argument of a
+// tool call, the default datasource when the argument is absent
+func (m *Module) datasource(args map[string]interface{}) (*datasource, error) {
+	if len(m.datasources) == 0 {
+		return nil, fmt.Errorf("Elasticsearch configuration not found - please set logs.elasticsearch.endpoint or logs.datasources in config")
+	}
+	name, _ := args["datasource"].(string)
+	if name == "" {
+		name = m.defaultDatasource
+	}
+	if name == "" {
+		return nil, fmt.Errorf("datasource is required, available datasources: %s", strings.Join(m.datasourceNames, ", "))
+	}
+	ds, ok := m.datasources[strings.ToLower(name)]
+	if !ok {
+		return nil, fmt.Errorf("unknown datasource %q, available datasources: %s", name, strings.Join(m.datasourceNames, ", "))
+	}
+	return ds, nil
 }
 
 // GetTools returns all MCP tools for the logs module
 func (m *Module) GetTools() []server.ServerTool {
-	// Get default tool configuration
-	toolsConfig := GetDefaultToolsConfig()
+	return m.BuildTools(m.tools)
+}
+
+// GetResources returns the index catalog of the default datasource, exposed
+// while the list indices tool is enabled
+func (m *Module) GetResources() []server.ServerResource {
+	if !m.tools.ListIndices.Enabled {
+		return nil
+	}
+	return []server.ServerResource{
+		{
+			Resource: mcp.NewResource("ops://logs/indices", "Elasticsearch indices",
+				mcp.WithResourceDescription("Indices of the default Elasticsearch datasource with their health, document count and size"),
+				mcp.WithMIMEType("application/json")),
+			Handler: modules.ToolResource(m.handleListIndices, func(mcp.ReadResourceRequest) map[string]any {
+				return map[string]any{}
+			}),
+		},
+	}
+}
 
-	// Tool configuration can be modified based on config file or other conditions
-	// For example: disable certain tools based on m.config
-	// toolsConfig.SearchLogs.Enabled = false
+// GetResourceTemplates returns no templates, indices have a fixed URI
+func (m *Module) GetResourceTemplates() []server.ServerResourceTemplate {
+	return nil
+}
 
-	return m.BuildTools(toolsConfig)
+// HealthCheck reports whether Elasticsearch is configured
+func (m *Module) HealthCheck() error {
+	if len(m.datasources) == 0 {
+		return fmt.Errorf("Elasticsearch configuration not found - please set logs.elasticsearch.endpoint or logs.datasources in config")
+	}
+	return nil
 }
 
 // Tool handlers
 
-func (m *Module) handleQueryLogs(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
-	// Check if Elasticsearch is configured
-	if m.config.Elasticsearch == nil || m.config.Elasticsearch.Endpoint == "" {
-		return nil, fmt.Errorf("Elasticsearch configuration not found - please set logs.elasticsearch.endpoint in config")
+func (m *Module) handleListDatasources(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	datasources := make([]DatasourceInfo, 0, len(m.datasourceNames))
+	for _, name := range m.datasourceNames {
+		datasources = append(datasources, DatasourceInfo{
+			Name:    name,
+			Default: name == m.defaultDatasource,
+		})
 	}
 
+	return modules.StructuredResult(DatasourcesResponse{
+		Datasources:       datasources,
+		DefaultDatasource: m.defaultDatasource,
+		TotalCount:        len(datasources),
+	}, "")
+}
+
+func (m *Module) handleQueryLogs(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
 	args := request.GetArguments()
 
+	ds, err := m.datasource(args)
+	if err != nil {
+		return nil, err
+	}
+
 	// Parse parameters
 	var service, level, startTime, endTime string
 	var size int = 100
@@ -213,7 +346,7 @@ func (m *Module) handleQueryLogs(ctx context.Context, request mcp.CallToolReques
 		},
 	}
 
-	resp, err := m.makeElasticsearchRequest(ctx, "POST", "*/_search", searchQuery)
+	resp, err := m.makeElasticsearchRequest(ctx, ds, "POST", "*/_search", searchQuery)
 	if err != nil {
 		return &mcp.CallToolResult{
 			IsError: true,
@@ -299,6 +432,11 @@ func (m *Module) handleQueryLogs(ctx context.Context, request mcp.CallToolReques
 func (m *Module) handleGetLogStats(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
 	args := request.GetArguments()
 
+	ds, err := m.datasource(args)
+	if err != nil {
+		return nil, err
+	}
+
 	timeRange := "24h"
 	if val, ok := args["time_range"].(string); ok {
 		timeRange = val
@@ -330,7 +468,7 @@ func (m *Module) handleGetLogStats(ctx context.Context, request mcp.CallToolRequ
 		},
 	}
 
-	resp, err := m.makeElasticsearchRequest(ctx, "POST", "*/_search", aggQuery)
+	resp, err := m.makeElasticsearchRequest(ctx, ds, "POST", "*/_search", aggQuery)
 	if err != nil {
 		return &mcp.CallToolResult{
 			IsError: true,
@@ -456,6 +594,11 @@ func (m *Module) handleGetLogStats(ctx context.Context, request mcp.CallToolRequ
 }
 
 func (m *Module) handleGetLogServices(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	ds, err := m.datasource(request.GetArguments())
+	if err != nil {
+		return nil, err
+	}
+
 	// Query Elasticsearch for unique services
 	aggQuery := map[string]interface{}{
 		"size": 0,
@@ -469,7 +612,7 @@ func (m *Module) handleGetLogServices(ctx context.Context, request mcp.CallToolR
 		},
 	}
 
-	resp, err := m.makeElasticsearchRequest(ctx, "POST", "*/_search", aggQuery)
+	resp, err := m.makeElasticsearchRequest(ctx, ds, "POST", "*/_search", aggQuery)
 	if err != nil {
 		return &mcp.CallToolResult{
 			IsError: true,
@@ -560,6 +703,11 @@ func (m *Module) handleGetLogServices(ctx context.Context, request mcp.CallToolR
 }
 
 func (m *Module) handleGetLogLevels(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	ds, err := m.datasource(request.GetArguments())
+	if err != nil {
+		return nil, err
+	}
+
 	// Query Elasticsearch for unique log levels actually used
 	aggQuery := map[string]interface{}{
 		"size": 0,
@@ -573,7 +721,7 @@ func (m *Module) handleGetLogLevels(ctx context.Context, request mcp.CallToolReq
 		},
 	}
 
-	resp, err := m.makeElasticsearchRequest(ctx, "POST", "*/_search", aggQuery)
+	resp, err := m.makeElasticsearchRequest(ctx, ds, "POST", "*/_search", aggQuery)
 	if err != nil {
 		return &mcp.CallToolResult{
 			IsError: true,
@@ -689,6 +837,11 @@ func (m *Module) handleGetLogLevels(ctx context.Context, request mcp.CallToolReq
 func (m *Module) handleGetRecentErrors(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
 	args := request.GetArguments()
 
+	ds, err := m.datasource(args)
+	if err != nil {
+		return nil, err
+	}
+
 	var hours int = 24
 	var size int = 20
 
@@ -736,7 +889,7 @@ func (m *Module) handleGetRecentErrors(ctx context.Context, request mcp.CallTool
 	}
 
 	// Execute search against logs indices
-	resp, err := m.makeElasticsearchRequest(ctx, "POST", "*/_search", errorQuery)
+	resp, err := m.makeElasticsearchRequest(ctx, ds, "POST", "*/_search", errorQuery)
 	if err != nil {
 		return &mcp.CallToolResult{
 			IsError: true,
@@ -833,13 +986,9 @@ func (m *Module) handleGetRecentErrors(ctx context.Context, request mcp.CallTool
 	}, nil
 }
 
-// makeElasticsearchRequest creates and executes an HTTP request to Elasticsearch
-func (m *Module) makeElasticsearchRequest(ctx context.Context, method, path string, body interface{}) (*http.Response, error) {
-	if m.config.Elasticsearch == nil {
-		return nil, fmt.Errorf("elasticsearch configuration is not available")
-	}
-
-	fullURL := strings.TrimRight(m.config.Elasticsearch.Endpoint, "/") + "/" + strings.TrimLeft(path, "/")
+// makeElasticsearchRequest creates and executes an HTTP request to the Elasticsearch cluster of ds
+func (m *Module) makeElasticsearchRequest(ctx context.Context, ds *datasource, method, path string, body interface{}) (*http.Response, error) {
+	fullURL := strings.TrimRight(ds.config.Endpoint, "/") + "/" + strings.TrimLeft(path, "/")
 
 	var reqBody io.Reader
 	var bodyStr string
@@ -863,9 +1012,10 @@ func (m *Module) makeElasticsearchRequest(ctx context.Context, method, path stri
 		zap.String("method", method),
 		zap.String("full_url", fullURL),
 		zap.String("path", path),
-		zap.String("endpoint", m.config.Elasticsearch.Endpoint),
+		zap.String("datasource", ds.name),
+		zap.String("endpoint", ds.config.Endpoint),
 		zap.Bool("has_body", body != nil),
-		zap.Int("timeout_seconds", m.config.Elasticsearch.Timeout))
+		zap.Int("timeout_seconds", ds.config.Timeout))
 
 	// Also print to console for visibility
 	fmt.Printf("🔍 Elasticsearch API Call: %s %s\n", method, fullURL)
@@ -898,16 +1048,27 @@ func (m *Module) makeElasticsearchRequest(ctx context.Context, method, path stri
 
 	// Set authentication
 	authMethod := "none"
-	if m.config.Elasticsearch.APIKey != "" {
-		req.Header.Set("Authorization", "ApiKey "+m.config.Elasticsearch.APIKey)
+	if ds.config.APIKey != "" {
+		req.Header.Set("Authorization", "ApiKey "+ds.config.APIKey)
 		authMethod = "api_key"
-	} else if m.config.Elasticsearch.Username != "" && m.config.Elasticsearch.Password != "" {
-		req.SetBasicAuth(m.config.Elasticsearch.Username, m.config.Elasticsearch.Password)
+	} else if ds.config.Username != "" && ds.config.Password != "" {
+		req.SetBasicAuth(ds.config.Username, ds.config.Password)
 		authMethod = "basic_auth"
 	}
 
-	resp, err := m.httpClient.Do(req)
+	start := time.Now()
+	waiting := modules.ProgressFromContext(ctx).Wait(fmt.Sprintf("Waiting for Elasticsearch %s", ds.name))
+	resp, err := ds.httpClient.Do(req)
+	waiting()
+	duration := time.Since(start)
 	if err != nil {
+		// Record backend metrics
+		appMetrics.RecordBackendRequest(appMetrics.BackendElasticsearch, ds.name, duration, false)
+		errorType := "network_error"
+		if strings.Contains(strings.ToLower(err.Error()), "timeout") {
+			errorType = "timeout"
+		}
+		appMetrics.RecordBackendError(appMetrics.BackendElasticsearch, ds.name, errorType)
 		m.logger.Error("❌ Elasticsearch Request Failed",
 			zap.String("method", method),
 			zap.String("url", fullURL),
@@ -927,6 +1088,19 @@ func (m *Module) makeElasticsearchRequest(ctx context.Context, method, path stri
 
 	fmt.Printf("✅ Elasticsearch Response: %d %s\n", resp.StatusCode, resp.Status)
 
+	// Record backend metrics
+	success := resp.StatusCode >= 200 && resp.StatusCode < 300
+	appMetrics.RecordBackendRequest(appMetrics.BackendElasticsearch, ds.name, duration, success)
+	if !success {
+		errorType := "http_error"
+		if resp.StatusCode == 401 || resp.StatusCode == 403 {
+			errorType = "auth_error"
+		} else if resp.StatusCode >= 500 {
+			errorType = "server_error"
+		}
+		appMetrics.RecordBackendError(appMetrics.BackendElasticsearch, ds.name, errorType)
+	}
+
 	return resp, nil
 }
 
@@ -935,6 +1109,11 @@ func (m *Module) makeElasticsearchRequest(ctx context.Context, method, path stri
 func (m *Module) handleListIndices(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
 	args := request.GetArguments()
 
+	ds, err := m.datasource(args)
+	if err != nil {
+		return nil, err
+	}
+
 	// Build query parameters
 	params := url.Values{}
 	if format, ok := args["format"].(string); ok && format != "" {
@@ -960,7 +1139,7 @@ func (m *Module) handleListIndices(ctx context.Context, request mcp.CallToolRequ
 		path += "?" + params.Encode()
 	}
 
-	resp, err := m.makeElasticsearchRequest(ctx, "GET", path, nil)
+	resp, err := m.makeElasticsearchRequest(ctx, ds, "GET", path, nil)
 	if err != nil {
 		return &mcp.CallToolResult{
 			IsError: true,
@@ -1004,54 +1183,48 @@ func (m *Module) handleListIndices(ctx context.Context, request mcp.CallToolRequ
 	}
 
 	// Parse response based on format
-	var result interface{}
-	if params.Get("format") == "json" {
-		var indices []ElasticsearchIndex
-		if err := json.Unmarshal(responseData, &indices); err != nil {
-			return &mcp.CallToolResult{
-				IsError: true,
-				Content: []mcp.Content{
-					mcp.TextContent{
-						Type: "text",
-						Text: fmt.Sprintf("Failed to parse JSON response: %v", err),
-					},
-				},
-			}, nil
-		}
-		result = map[string]interface{}{
-			"indices": indices,
-			"total":   len(indices),
+	if format := params.Get("format"); format != "json" {
+		// Text formats list one index per line
+		output := string(responseData)
+		total := 0
+		for _, line := range strings.Split(output, "\n") {
+			if strings.TrimSpace(line) != "" {
+				total++
+			}
 		}
-	} else {
-		result = string(responseData)
+		return modules.StructuredResult(IndicesResult{
+			Total:  total,
+			Format: format,
+			Output: output,
+		}, output)
 	}
 
-	data, err := json.Marshal(result)
-	if err != nil {
+	var indices []ElasticsearchIndex
+	if err := json.Unmarshal(responseData, &indices); err != nil {
 		return &mcp.CallToolResult{
 			IsError: true,
 			Content: []mcp.Content{
 				mcp.TextContent{
 					Type: "text",
-					Text: fmt.Sprintf("Failed to marshal response: %v", err),
+					Text: fmt.Sprintf("Failed to parse JSON response: %v", err),
 				},
 			},
 		}, nil
 	}
-
-	return &mcp.CallToolResult{
-		Content: []mcp.Content{
-			mcp.TextContent{
-				Type: "text",
-				Text: string(data),
-			},
-		},
-	}, nil
+	return modules.StructuredResult(IndicesResult{
+		Indices: indices,
+		Total:   len(indices),
+	}, "")
 }
 
 func (m *Module) handleGetMappings(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
 	args := request.GetArguments()
 
+	ds, err := m.datasource(args)
+	if err != nil {
+		return nil, err
+	}
+
 	indexName, ok := args["index"].(string)
 	if !ok || indexName == "" {
 		return &mcp.CallToolResult{
@@ -1072,7 +1245,7 @@ func (m *Module) handleGetMappings(ctx context.Context, request mcp.CallToolRequ
 
 	// Get mappings
 	mappingsPath := fmt.Sprintf("%s/_mapping", indexName)
-	mappingsResp, err := m.makeElasticsearchRequest(ctx, "GET", mappingsPath, nil)
+	mappingsResp, err := m.makeElasticsearchRequest(ctx, ds, "GET", mappingsPath, nil)
 	if err != nil {
 		return &mcp.CallToolResult{
 			IsError: true,
@@ -1132,7 +1305,7 @@ func (m *Module) handleGetMappings(ctx context.Context, request mcp.CallToolRequ
 	// Get settings if requested
 	if includeSettings {
 		settingsPath := fmt.Sprintf("%s/_settings", indexName)
-		settingsResp, err := m.makeElasticsearchRequest(ctx, "GET", settingsPath, nil)
+		settingsResp, err := m.makeElasticsearchRequest(ctx, ds, "GET", settingsPath, nil)
 		if err != nil {
 			m.logger.Warn("Failed to get settings", zap.Error(err))
 		} else {
@@ -1171,21 +1344,21 @@ func (m *Module) handleGetMappings(ctx context.Context, request mcp.CallToolRequ
 }
 
 func (m *Module) handleElasticsearchSearch(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
-	// Check if Elasticsearch is configured
-	if m.config.Elasticsearch == nil || m.config.Elasticsearch.Endpoint == "" {
+	args := request.GetArguments()
+
+	ds, err := m.datasource(args)
+	if err != nil {
 		return &mcp.CallToolResult{
 			IsError: true,
 			Content: []mcp.Content{
 				mcp.TextContent{
 					Type: "text",
-					Text: "Elasticsearch configuration not found - please set logs.elasticsearch.endpoint in config",
+					Text: err.Error(),
 				},
 			},
 		}, nil
 	}
 
-	args := request.GetArguments()
-
 	indexName, ok := args["index"].(string)
 	if !ok || indexName == "" {
 		return &mcp.CallToolResult{
@@ -1235,7 +1408,7 @@ func (m *Module) handleElasticsearchSearch(ctx context.Context, request mcp.Call
 
 	// Execute the native ES search request
 	path := fmt.Sprintf("%s/_search", indexName)
-	resp, err := m.makeElasticsearchRequest(ctx, "POST", path, searchRequest)
+	resp, err := m.makeElasticsearchRequest(ctx, ds, "POST", path, searchRequest)
 	if err != nil {
 		return &mcp.CallToolResult{
 			IsError: true,
@@ -1278,20 +1451,33 @@ func (m *Module) handleElasticsearchSearch(ctx context.Context, request mcp.Call
 		}, nil
 	}
 
-	// Return the raw ES response
-	return &mcp.CallToolResult{
-		Content: []mcp.Content{
-			mcp.TextContent{
-				Type: "text",
-				Text: string(responseData),
+	// Responses in another shape do not match the output schema
+	var searchResult ElasticsearchSearchResponse
+	if err := json.Unmarshal(responseData, &searchResult); err != nil {
+		m.logger.Warn("Elasticsearch response is not a standard search response", zap.Error(err))
+		return &mcp.CallToolResult{
+			IsError: true,
+			Content: []mcp.Content{
+				mcp.TextContent{
+					Type: "text",
+					Text: fmt.Sprintf("Failed to parse search response: %v", err),
+				},
 			},
-		},
-	}, nil
+		}, nil
+	}
+	modules.ProgressFromContext(ctx).Step(fmt.Sprintf("Received %d of %d matching logs", len(searchResult.Hits.Hits), searchResult.Hits.Total.Value))
+
+	return modules.StructuredResult(searchResult, "")
 }
 
 func (m *Module) handleESQL(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
 	args := request.GetArguments()
 
+	ds, err := m.datasource(args)
+	if err != nil {
+		return nil, err
+	}
+
 	query, ok := args["query"].(string)
 	if !ok || query == "" {
 		return nil, fmt.Errorf("query parameter is required")
@@ -1320,7 +1506,7 @@ func (m *Module) handleESQL(ctx context.Context, request mcp.CallToolRequest) (*
 		esqlRequest["columnar"] = true
 	}
 
-	resp, err := m.makeElasticsearchRequest(ctx, "GET", "_query", esqlRequest)
+	resp, err := m.makeElasticsearchRequest(ctx, ds, "GET", "_query", esqlRequest)
 	if err != nil {
 		return nil, err
 	}
@@ -1340,53 +1526,44 @@ func (m *Module) handleESQL(ctx context.Context, request mcp.CallToolRequest) (*
 	}
 
 	// Return response based on format
-	var result interface{}
-	if format == "json" {
-		var esqlResponse ESQLResponse
-		if err := json.Unmarshal(responseData, &esqlResponse); err != nil {
-			return nil, fmt.Errorf("failed to parse ES|QL response: %w", err)
-		}
-
-		// Transform response into an array of objects (like Rust implementation)
-		// This makes the response much more user-friendly
-		objects := make([]map[string]interface{}, 0, len(esqlResponse.Values))
-		for _, row := range esqlResponse.Values {
-			obj := make(map[string]interface{})
-			for i, value := range row {
-				if i < len(esqlResponse.Columns) {
-					obj[esqlResponse.Columns[i].Name] = value
-				}
-			}
-			objects = append(objects, obj)
-		}
+	if format != "json" {
+		output := string(responseData)
+		return modules.StructuredResult(ESQLResult{Format: format, Output: output}, output)
+	}
 
-		result = map[string]interface{}{
-			"columns": esqlResponse.Columns,
-			"data":    objects,
-			"meta":    esqlResponse.Meta,
-		}
-	} else {
-		result = string(responseData)
+	var esqlResponse ESQLResponse
+	if err := json.Unmarshal(responseData, &esqlResponse); err != nil {
+		return nil, fmt.Errorf("failed to parse ES|QL response: %w", err)
 	}
 
-	data, err := json.Marshal(result)
-	if err != nil {
-		return nil, err
+	// Transform response into an array of objects (like Rust implementation)
+	// This makes the response much more user-friendly
+	objects := make([]map[string]interface{}, 0, len(esqlResponse.Values))
+	for _, row := range esqlResponse.Values {
+		obj := make(map[string]interface{})
+		for i, value := range row {
+			if i < len(esqlResponse.Columns) {
+				obj[esqlResponse.Columns[i].Name] = value
+			}
+		}
+		objects = append(objects, obj)
 	}
 
-	return &mcp.CallToolResult{
-		Content: []mcp.Content{
-			mcp.TextContent{
-				Type: "text",
-				Text: string(data),
-			},
-		},
-	}, nil
+	return modules.StructuredResult(ESQLResult{
+		Columns: esqlResponse.Columns,
+		Data:    objects,
+		Meta:    esqlResponse.Meta,
+	}, "")
 }
 
 func (m *Module) handleGetShards(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
 	args := request.GetArguments()
 
+	ds, err := m.datasource(args)
+	if err != nil {
+		return nil, err
+	}
+
 	// Build query parameters
 	params := url.Values{}
 	if format, ok := args["format"].(string); ok && format != "" {
@@ -1411,7 +1588,7 @@ func (m *Module) handleGetShards(ctx context.Context, request mcp.CallToolReques
 		path += "?" + params.Encode()
 	}
 
-	resp, err := m.makeElasticsearchRequest(ctx, "GET", path, nil)
+	resp, err := m.makeElasticsearchRequest(ctx, ds, "GET", path, nil)
 	if err != nil {
 		return nil, err
 	}
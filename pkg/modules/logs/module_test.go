@@ -0,0 +1,118 @@
+package logs
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"go.uber.org/zap"
+)
+
+// newSearchModule creates a module against a fake Elasticsearch answering
+// searches with response
+func newSearchModule(t *testing.T, response string) *Module {
+	t.Helper()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost || r.URL.Path != "/app-logs/_search" {
+			http.NotFound(w, r)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		io.WriteString(w, response)
+	}))
+	t.Cleanup(server.Close)
+
+	m, err := New(&Config{Elasticsearch: &ElasticsearchConfig{Endpoint: server.URL}}, zap.NewNop())
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	return m
+}
+
+func search(t *testing.T, m *Module) *mcp.CallToolResult {
+	t.Helper()
+	var request mcp.CallToolRequest
+	request.Params.Arguments = map[string]any{"index": "app-logs", "body": `{"query":{"match_all":{}}}`}
+	result, err := m.handleElasticsearchSearch(context.Background(), request)
+	if err != nil {
+		t.Fatalf("handleElasticsearchSearch: %v", err)
+	}
+	return result
+}
+
+func TestSearchReturnsStructuredHits(t *testing.T) {
+	m := newSearchModule(t, `{
+		"took": 3,
+		"timed_out": false,
+		"_shards": {"total": 1, "successful": 1, "skipped": 0, "failed": 0},
+		"hits": {"total": {"value": 1, "relation": "eq"}, "max_score": 1.0, "hits": [
+			{"_index": "app-logs", "_id": "1", "_score": 1.0, "_source": {"message": "disk full"}}
+		]}
+	}`)
+
+	result := search(t, m)
+	if result.IsError {
+		t.Fatalf("search failed: %v", result.Content)
+	}
+	searchResult, ok := result.StructuredContent.(ElasticsearchSearchResponse)
+	if !ok {
+		t.Fatalf("structured content is %T, want ElasticsearchSearchResponse", result.StructuredContent)
+	}
+	if len(searchResult.Hits.Hits) != 1 || searchResult.Hits.Hits[0].Source["message"] != "disk full" {
+		t.Errorf("hits %+v, want the disk full log", searchResult.Hits.Hits)
+	}
+
+	// The text mirrors the structured content rather than the raw response
+	text := result.Content[0].(mcp.TextContent).Text
+	compact, _ := json.Marshal(searchResult)
+	if text != string(compact) {
+		t.Errorf("text %s, want the compact structured content %s", text, compact)
+	}
+}
+
+func TestSearchReadsTotalInBothForms(t *testing.T) {
+	tests := []struct {
+		name         string
+		total        string
+		wantValue    int64
+		wantRelation string
+	}{
+		{"object", `{"value": 10000, "relation": "gte"}`, 10000, "gte"},
+		// Elasticsearch 6 and rest_total_hits_as_int report the total as a number
+		{"number", `42`, 42, "eq"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := newSearchModule(t, `{"took": 3, "hits": {"total": `+tt.total+`, "hits": []}}`)
+
+			result := search(t, m)
+			if result.IsError {
+				t.Fatalf("search failed: %v", result.Content)
+			}
+			total := result.StructuredContent.(ElasticsearchSearchResponse).Hits.Total
+			if total.Value != tt.wantValue || total.Relation != tt.wantRelation {
+				t.Errorf("total %d %s, want %d %s", total.Value, total.Relation, tt.wantValue, tt.wantRelation)
+			}
+		})
+	}
+}
+
+func TestSearchRejectsNonStandardResponse(t *testing.T) {
+	m := newSearchModule(t, `{"took": 3, "hits": "none"}`)
+
+	result := search(t, m)
+	if !result.IsError {
+		t.Fatalf("search of a non-standard response succeeded with %v", result.Content)
+	}
+	if result.StructuredContent != nil {
+		t.Errorf("structured content %v returned along with the error", result.StructuredContent)
+	}
+	if text := result.Content[0].(mcp.TextContent).Text; !strings.Contains(text, "Failed to parse search response") {
+		t.Errorf("error %q, want a parse failure", text)
+	}
+}
@@ -0,0 +1,53 @@
+package logs
+
+import (
+	"github.com/shaowenchen/ops-mcp-server/pkg/config"
+	"github.com/shaowenchen/ops-mcp-server/pkg/modules"
+	"go.uber.org/zap"
+)
+
+func init() {
+	modules.Register(modules.Factory{
+		Name:    "logs",
+		Section: "logs",
+		Enabled: func(cfg *config.Config) *bool { return &cfg.Logs.Enabled },
+		New: func(cfg *config.Config, logger *zap.Logger) (modules.Module, error) {
+			return New(ConfigFrom(cfg), logger)
+		},
+	})
+}
+
+// ConfigFrom builds the logs module configuration from the server configuration
+func ConfigFrom(cfg *config.Config) *Config {
+	logsConfig := &Config{
+		Tools: ToolsConfig{
+			Prefix:    cfg.Logs.Tools.Prefix,
+			Suffix:    cfg.Logs.Tools.Suffix,
+			Overrides: cfg.Logs.Tools.Overrides,
+		},
+		DefaultDatasource: cfg.Logs.DefaultDatasource,
+	}
+	if cfg.Logs.Elasticsearch != nil {
+		logsConfig.Elasticsearch = elasticsearchConfigFrom(cfg.Logs.Elasticsearch)
+	}
+	if len(cfg.Logs.Datasources) > 0 {
+		logsConfig.Datasources = make(map[string]*ElasticsearchConfig, len(cfg.Logs.Datasources))
+		for name, elasticsearch := range cfg.Logs.Datasources {
+			if elasticsearch != nil {
+				logsConfig.Datasources[name] = elasticsearchConfigFrom(elasticsearch)
+			}
+		}
+	}
+	return logsConfig
+}
+
+// elasticsearchConfigFrom copies an Elasticsearch cluster of the server configuration
+func elasticsearchConfigFrom(elasticsearch *config.LogsElasticsearchConfig) *ElasticsearchConfig {
+	return &ElasticsearchConfig{
+		Endpoint: elasticsearch.Endpoint,
+		Username: elasticsearch.Username,
+		Password: elasticsearch.Password,
+		APIKey:   elasticsearch.APIKey,
+		Timeout:  elasticsearch.Timeout,
+	}
+}
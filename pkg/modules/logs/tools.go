@@ -4,20 +4,18 @@ import (
 	"github.com/mark3labs/mcp-go/mcp"
 	"github.com/mark3labs/mcp-go/server"
 	"github.com/shaowenchen/ops-mcp-server/pkg/metrics"
+	"github.com/shaowenchen/ops-mcp-server/pkg/modules"
 )
 
 // ToolConfig defines configuration for a single tool
-type ToolConfig struct {
-	Enabled     bool   // Whether the tool is enabled
-	Name        string // Tool name
-	Description string // Tool description
-}
+type ToolConfig = modules.ToolConfig
 
 // LogsToolsConfig defines configuration for all tools
 type LogsToolsConfig struct {
 	Search      ToolConfig
 	ListIndices ToolConfig
 	ESQL        ToolConfig
+	Datasources ToolConfig
 }
 
 // GetDefaultToolsConfig returns default tool configuration
@@ -38,6 +36,11 @@ func GetDefaultToolsConfig() LogsToolsConfig {
 			Name:        "query-logs",
 			Description: "Query logs using ES|QL (Elasticsearch Query Language)",
 		},
+		Datasources: ToolConfig{
+			Enabled:     true,
+			Name:        "list-log-datasources",
+			Description: "List the Elasticsearch datasources that log tools can search with the datasource argument, and which one is the default",
+		},
 	}
 }
 
@@ -84,6 +87,15 @@ func (m *Module) BuildTools(toolsConfig LogsToolsConfig) []server.ServerTool {
 		})
 	}
 
+	// List Datasources Tool
+	if toolsConfig.Datasources.Enabled {
+		toolName := m.BuildToolName(toolsConfig.Datasources.Name)
+		tools = append(tools, server.ServerTool{
+			Tool:    m.buildListDatasourcesToolDefinition(toolsConfig.Datasources),
+			Handler: metrics.WrapToolHandler(m.handleListDatasources, toolName, "logs"),
+		})
+	}
+
 	return tools
 }
 
@@ -94,6 +106,8 @@ func (m *Module) buildSearchToolDefinition(config ToolConfig) mcp.Tool {
 		mcp.WithDescription(config.Description),
 		mcp.WithString("index", mcp.Required(), mcp.Description("Index name or pattern to search (e.g., 'logs-*', 'filebeat-*')")),
 		mcp.WithString("body", mcp.Required(), mcp.Description("Complete Elasticsearch query body as JSON string. Supports all ES Query DSL features: query, aggs, size, from, sort, _source, etc. Example: '{\"size\":0,\"query\":{\"query_string\":{\"query\":\"error\"}},\"aggs\":{\"by_level\":{\"terms\":{\"field\":\"level.keyword\"}}}}'")),
+		modules.OutputSchema[ElasticsearchSearchResponse](nil),
+		m.datasourceArgument(),
 	)
 }
 
@@ -103,6 +117,8 @@ func (m *Module) buildListIndicesToolDefinition(config ToolConfig) mcp.Tool {
 		mcp.WithString("format", mcp.Description("Output format (table, json) - default: table")),
 		mcp.WithString("health", mcp.Description("Filter by health status (green, yellow, red)")),
 		mcp.WithString("status", mcp.Description("Filter by status (open, close)")),
+		modules.OutputSchema[IndicesResult](nil),
+		m.datasourceArgument(),
 	)
 }
 
@@ -112,5 +128,42 @@ func (m *Module) buildESQLToolDefinition(config ToolConfig) mcp.Tool {
 		mcp.WithString("query", mcp.Required(), mcp.Description("ES|QL query string. Example: 'FROM logs-* | WHERE @timestamp > NOW() - 1 hour | STATS count() BY level'")),
 		mcp.WithString("format", mcp.Description("Response format (json, csv, tsv, txt) - default: json")),
 		mcp.WithString("columnar", mcp.Description("Return results in columnar format (true or false) - default: false")),
+		modules.OutputSchema[ESQLResult](nil),
+		m.datasourceArgument(),
 	)
 }
+
+func (m *Module) buildListDatasourcesToolDefinition(config ToolConfig) mcp.Tool {
+	return mcp.NewTool(m.BuildToolName(config.Name),
+		mcp.WithDescription(config.Description),
+		modules.OutputSchema[DatasourcesResponse](nil),
+	)
+}
+
+// datasourceArgument returns the optional datasource argument of the search tools
+func (m *Module) datasourceArgument() mcp.ToolOption {
+	description := "Elasticsearch datasource to search"
+	if m.tools.Datasources.Enabled {
+		description += ", see " + m.BuildToolName(m.tools.Datasources.Name)
+	}
+	if m.defaultDatasource != "" {
+		description += " (default: " + m.defaultDatasource + ")"
+	}
+	options := []mcp.PropertyOption{mcp.Description(description)}
+	if len(m.datasourceNames) > 0 {
+		options = append(options, mcp.Enum(m.datasourceNames...))
+	}
+	return mcp.WithString("datasource", options...)
+}
+
+// toolConfigs returns every tool of the configuration
+func (c *LogsToolsConfig) toolConfigs() []*ToolConfig {
+	return []*ToolConfig{&c.Search, &c.ListIndices, &c.ESQL, &c.Datasources}
+}
+
+// ToolNamesByDefault maps the default name of each enabled tool to the name
+// it is exposed under, after overrides, prefix and suffix
+func (m *Module) ToolNamesByDefault() map[string]string {
+	defaults := GetDefaultToolsConfig()
+	return modules.ToolNamesByDefault(defaults.toolConfigs(), m.tools.toolConfigs(), m.BuildToolName)
+}
@@ -1,6 +1,9 @@
 package logs
 
-import "time"
+import (
+	"encoding/json"
+	"time"
+)
 
 // LogEntry represents a single log entry
 type LogEntry struct {
@@ -38,13 +41,14 @@ type ElasticsearchMapping struct {
 
 // ElasticsearchSearchHit represents a single search hit
 type ElasticsearchSearchHit struct {
-	Index  string                 `json:"_index"`
-	Type   string                 `json:"_type"`
-	ID     string                 `json:"_id"`
-	Score  *float64               `json:"_score"`
-	Source map[string]interface{} `json:"_source"`
-	Fields map[string]interface{} `json:"fields,omitempty"`
-	Sort   []interface{}          `json:"sort,omitempty"`
+	Index     string                 `json:"_index"`
+	Type      string                 `json:"_type,omitempty"`
+	ID        string                 `json:"_id"`
+	Score     *float64               `json:"_score"`
+	Source    map[string]interface{} `json:"_source,omitempty"`
+	Fields    map[string]interface{} `json:"fields,omitempty"`
+	Highlight map[string][]string    `json:"highlight,omitempty"`
+	Sort      []interface{}          `json:"sort,omitempty"`
 }
 
 // ElasticsearchSearchHits represents search hits collection
@@ -60,6 +64,18 @@ type ElasticsearchTotal struct {
 	Relation string `json:"relation"`
 }
 
+// UnmarshalJSON reads the total from an object or, as Elasticsearch 6 and
+// requests with rest_total_hits_as_int send it, from a plain number
+func (t *ElasticsearchTotal) UnmarshalJSON(data []byte) error {
+	var value int64
+	if err := json.Unmarshal(data, &value); err == nil {
+		t.Value, t.Relation = value, "eq"
+		return nil
+	}
+	type total ElasticsearchTotal
+	return json.Unmarshal(data, (*total)(t))
+}
+
 // ElasticsearchSearchResponse represents search response
 type ElasticsearchSearchResponse struct {
 	Took         int64                   `json:"took"`
@@ -71,10 +87,11 @@ type ElasticsearchSearchResponse struct {
 
 // ElasticsearchShards represents shards info
 type ElasticsearchShards struct {
-	Total      int64 `json:"total"`
-	Successful int64 `json:"successful"`
-	Skipped    int64 `json:"skipped"`
-	Failed     int64 `json:"failed"`
+	Total      int64                    `json:"total"`
+	Successful int64                    `json:"successful"`
+	Skipped    int64                    `json:"skipped"`
+	Failed     int64                    `json:"failed"`
+	Failures   []map[string]interface{} `json:"failures,omitempty"`
 }
 
 // ElasticsearchShard represents a single shard
@@ -116,6 +133,38 @@ type ESQLResponse struct {
 	Meta    map[string]interface{} `json:"meta,omitempty"`
 }
 
+// ESQLResult represents ES|QL results as one object per row, or the raw
+// output of non-JSON formats
+type ESQLResult struct {
+	Columns []ESQLColumn             `json:"columns"`
+	Data    []map[string]interface{} `json:"data"`
+	Meta    map[string]interface{}   `json:"meta,omitempty"`
+	Format  string                   `json:"format,omitempty"`
+	Output  string                   `json:"output,omitempty"`
+}
+
+// IndicesResult represents the indices of a datasource, or the raw output
+// of non-JSON formats
+type IndicesResult struct {
+	Indices []ElasticsearchIndex `json:"indices"`
+	Total   int                  `json:"total"`
+	Format  string               `json:"format,omitempty"`
+	Output  string               `json:"output,omitempty"`
+}
+
+// DatasourceInfo describes a configured Elasticsearch datasource
+type DatasourceInfo struct {
+	Name    string `json:"name"`
+	Default bool   `json:"default"`
+}
+
+// DatasourcesResponse represents the configured datasources
+type DatasourcesResponse struct {
+	Datasources       []DatasourceInfo `json:"datasources"`
+	DefaultDatasource string           `json:"default_datasource"`
+	TotalCount        int              `json:"total_count"`
+}
+
 // ESQLColumn represents ES|QL column definition
 type ESQLColumn struct {
 	Name string `json:"name"`
@@ -0,0 +1,706 @@
+package metrics
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/shaowenchen/ops-mcp-server/pkg/auth"
+	appMetrics "github.com/shaowenchen/ops-mcp-server/pkg/metrics"
+	"github.com/shaowenchen/ops-mcp-server/pkg/modules"
+	"go.uber.org/zap"
+)
+
+// alertmanager is the Alertmanager instance with its HTTP client
+type alertmanager struct {
+	config     *AlertmanagerConfig
+	httpClient *http.Client
+}
+
+// alertmanagerInstance is the instance label of Alertmanager backend metrics
+const alertmanagerInstance = "default"
+
+// confirmSilenceProperty is the elicited field confirming a silence change
+const confirmSilenceProperty = "confirm_silence"
+
+// silenceChange is a change of shared alerting state the user confirms
+type silenceChange struct {
+	verb        string
+	title       string
+	description string
+}
+
+var (
+	createSilence = silenceChange{
+		verb:        "create",
+		title:       "Create this silence",
+		description: "Confirm that notifications of the matching alerts should stop until the silence ends",
+	}
+	expireSilence = silenceChange{
+		verb:        "expire",
+		title:       "Expire this silence",
+		description: "Confirm that notifications of the alerts it mutes should resume now",
+	}
+)
+
+// defaultSilenceCreator is the creator of silences requested by anonymous callers
+const defaultSilenceCreator = "ops-mcp-server"
+
+// maxErrorBody is how much of an Alertmanager error response is reported
+const maxErrorBody = 512
+
+func (m *Module) handleListAlertGroups(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	args := request.GetArguments()
+
+	matchers, err := labelMatchers(args["filter"])
+	if err != nil {
+		return nil, err
+	}
+	limit, err := parseLimit(args)
+	if err != nil {
+		return nil, err
+	}
+	receiver, _ := args["receiver"].(string)
+	includeSilenced, _ := args["include_silenced"].(bool)
+	includeInhibited, _ := args["include_inhibited"].(bool)
+
+	params := url.Values{
+		"active":    {"true"},
+		"silenced":  {fmt.Sprint(includeSilenced)},
+		"inhibited": {fmt.Sprint(includeInhibited)},
+	}
+	response := AlertGroupsResponse{Receiver: receiver, Groups: []AlertGroup{}, Limit: limit}
+	for _, matcher := range matchers {
+		params.Add("filter", matcher.String())
+		response.Filter = append(response.Filter, matcher.String())
+	}
+	if receiver != "" {
+		params.Set("receiver", receiver)
+	}
+
+	var groups []AlertmanagerAlertGroup
+	if err := m.alertmanagerAPI(ctx, "GET", "/api/v2/alerts/groups", params, nil, &groups); err != nil {
+		return nil, fmt.Errorf("failed to list alert groups: %w", err)
+	}
+
+	returned := 0
+	for _, group := range groups {
+		alerts := make([]ManagedAlert, 0, len(group.Alerts))
+		for _, amAlert := range group.Alerts {
+			alerts = append(alerts, newManagedAlert(amAlert))
+		}
+		sortManagedAlerts(alerts)
+		for _, alert := range alerts {
+			if alert.State == "suppressed" {
+				response.Suppressed++
+			} else {
+				response.Active++
+			}
+		}
+		response.TotalCount += len(alerts)
+
+		if returned >= limit {
+			response.Truncated = true
+			continue
+		}
+		if returned+len(alerts) > limit {
+			alerts = alerts[:limit-returned]
+			response.Truncated = true
+		}
+		returned += len(alerts)
+		response.Groups = append(response.Groups, AlertGroup{
+			Labels:   group.Labels,
+			Receiver: group.Receiver.Name,
+			Alerts:   alerts,
+		})
+	}
+
+	return modules.StructuredResult(response, formatAlertGroups(response, returned, time.Now()))
+}
+
+func (m *Module) handleGetAlertDetails(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	args := request.GetArguments()
+
+	fingerprint, ok := args["fingerprint"].(string)
+	if !ok || fingerprint == "" {
+		return nil, fmt.Errorf("fingerprint parameter is required")
+	}
+
+	alerts, err := m.alertmanagerAlerts(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get alert %s: %w", fingerprint, err)
+	}
+	byFingerprint := make(map[string]AlertmanagerAlert, len(alerts))
+	for _, amAlert := range alerts {
+		byFingerprint[amAlert.Fingerprint] = amAlert
+	}
+	amAlert, ok := byFingerprint[fingerprint]
+	if !ok {
+		return nil, fmt.Errorf("alert %s is not known to Alertmanager anymore, list the alert groups for the current fingerprints", fingerprint)
+	}
+
+	response := AlertDetailsResponse{Alert: newManagedAlert(amAlert)}
+	for _, id := range amAlert.Status.SilencedBy {
+		var silence AlertmanagerSilence
+		if err := m.alertmanagerAPI(ctx, "GET", "/api/v2/silence/"+url.PathEscape(id), nil, nil, &silence); err != nil {
+			return nil, fmt.Errorf("failed to get silence %s: %w", id, err)
+		}
+		response.Silences = append(response.Silences, newSilence(silence))
+	}
+	for _, inhibitor := range amAlert.Status.InhibitedBy {
+		if inhibiting, ok := byFingerprint[inhibitor]; ok {
+			response.InhibitedBy = append(response.InhibitedBy, newManagedAlert(inhibiting))
+		}
+	}
+
+	return modules.StructuredResult(response, formatAlertDetails(response, time.Now()))
+}
+
+func (m *Module) handleListSilences(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	args := request.GetArguments()
+
+	state, _ := args["state"].(string)
+	state = strings.ToLower(state)
+	if state != "" && state != "active" && state != "pending" && state != "expired" {
+		return nil, fmt.Errorf("invalid state '%s': must be active, pending or expired", state)
+	}
+	matchers, err := labelMatchers(args["filter"])
+	if err != nil {
+		return nil, err
+	}
+	limit, err := parseLimit(args)
+	if err != nil {
+		return nil, err
+	}
+
+	params := url.Values{}
+	response := SilencesResponse{State: state, Silences: []Silence{}, Limit: limit}
+	for _, matcher := range matchers {
+		params.Add("filter", matcher.String())
+		response.Filter = append(response.Filter, matcher.String())
+	}
+
+	var silences []AlertmanagerSilence
+	if err := m.alertmanagerAPI(ctx, "GET", "/api/v2/silences", params, nil, &silences); err != nil {
+		return nil, fmt.Errorf("failed to list silences: %w", err)
+	}
+	for _, amSilence := range silences {
+		silence := newSilence(amSilence)
+		// Expired silences are kept for a while and only listed when asked for
+		if (state == "" && silence.State == "expired") || (state != "" && silence.State != state) {
+			continue
+		}
+		response.Silences = append(response.Silences, silence)
+	}
+	sort.SliceStable(response.Silences, func(i, j int) bool {
+		a, b := response.Silences[i], response.Silences[j]
+		if a.State != b.State {
+			return a.State < b.State
+		}
+		return a.EndsAt < b.EndsAt
+	})
+	response.TotalCount = len(response.Silences)
+	response.Silences, response.Truncated = truncate(response.Silences, limit)
+
+	return modules.StructuredResult(response, formatSilences(response))
+}
+
+func (m *Module) handleCreateSilence(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	args := request.GetArguments()
+
+	matchers, err := labelMatchers(args["matchers"])
+	if err != nil {
+		return nil, err
+	}
+	if len(matchers) == 0 {
+		return nil, fmt.Errorf("matchers parameter is required, e.g. [\"alertname=\\\"HighErrorRate\\\"\", \"namespace=\\\"payments\\\"\"]")
+	}
+	durationArg, _ := args["duration"].(string)
+	if durationArg == "" {
+		return nil, fmt.Errorf("duration parameter is required (examples: 30m, 2h, 1d)")
+	}
+	duration, err := parseTimeRange(durationArg)
+	if err != nil || duration <= 0 {
+		return nil, fmt.Errorf("invalid duration '%s' (supported units: s, m, h, d - examples: 30m, 2h, 1d)", durationArg)
+	}
+	comment, _ := args["comment"].(string)
+	if strings.TrimSpace(comment) == "" {
+		return nil, fmt.Errorf("comment parameter is required, explain why the alerts are silenced")
+	}
+	// Authenticated callers file silences under their own name only
+	createdBy, _ := args["created_by"].(string)
+	if identity, ok := auth.IdentityFromContext(ctx); ok && identity.Name != "" {
+		createdBy = identity.Name
+	}
+	if createdBy == "" {
+		createdBy = defaultSilenceCreator
+	}
+
+	// Show the user what would be muted before asking for confirmation
+	filter := url.Values{}
+	for _, matcher := range matchers {
+		filter.Add("filter", matcher.String())
+	}
+	matched, err := m.alertmanagerAlerts(ctx, filter)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list the alerts the silence matches: %w", err)
+	}
+
+	now := time.Now().UTC()
+	amSilence := AlertmanagerSilence{
+		StartsAt:  now.Format(time.RFC3339),
+		EndsAt:    now.Add(duration).Format(time.RFC3339),
+		CreatedBy: createdBy,
+		Comment:   comment,
+	}
+	for _, matcher := range matchers {
+		amSilence.Matchers = append(amSilence.Matchers, matcher.alertmanagerMatcher())
+	}
+	silence := newSilence(amSilence)
+
+	if cancelled, err := m.confirmSilence(ctx, createSilence, silence, matched); cancelled != nil || err != nil {
+		return cancelled, err
+	}
+
+	var created struct {
+		SilenceID string `json:"silenceID"`
+	}
+	if err := m.alertmanagerAPI(ctx, "POST", "/api/v2/silences", nil, amSilence, &created); err != nil {
+		return nil, fmt.Errorf("failed to create silence: %w", err)
+	}
+	silence.ID = created.SilenceID
+	silence.State = "active"
+	m.logger.Info("Created Alertmanager silence",
+		zap.String("silence_id", silence.ID),
+		zap.Strings("matchers", silence.Matchers),
+		zap.String("ends_at", silence.EndsAt),
+		zap.String("created_by", createdBy))
+
+	result := SilenceResult{Status: "created", Silence: &silence, MatchedAlerts: len(matched)}
+	return modules.StructuredResult(result, formatSilenceResult(result))
+}
+
+func (m *Module) handleExpireSilence(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	args := request.GetArguments()
+
+	id, ok := args["silence_id"].(string)
+	if !ok || id == "" {
+		return nil, fmt.Errorf("silence_id parameter is required")
+	}
+
+	var amSilence AlertmanagerSilence
+	if err := m.alertmanagerAPI(ctx, "GET", "/api/v2/silence/"+url.PathEscape(id), nil, nil, &amSilence); err != nil {
+		return nil, fmt.Errorf("failed to get silence %s: %w", id, err)
+	}
+	silence := newSilence(amSilence)
+
+	// Show the user which alerts would notify again before asking for confirmation
+	filter := url.Values{"filter": silence.Matchers}
+	matching, err := m.alertmanagerAlerts(ctx, filter)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list the alerts the silence mutes: %w", err)
+	}
+	muted := make([]AlertmanagerAlert, 0, len(matching))
+	for _, amAlert := range matching {
+		if slices.Contains(amAlert.Status.SilencedBy, id) {
+			muted = append(muted, amAlert)
+		}
+	}
+	if cancelled, err := m.confirmSilence(ctx, expireSilence, silence, muted); cancelled != nil || err != nil {
+		return cancelled, err
+	}
+
+	if err := m.alertmanagerAPI(ctx, "DELETE", "/api/v2/silence/"+url.PathEscape(id), nil, nil, nil); err != nil {
+		return nil, fmt.Errorf("failed to expire silence %s: %w", id, err)
+	}
+	silence.State = "expired"
+	m.logger.Info("Expired Alertmanager silence",
+		zap.String("silence_id", id),
+		zap.Strings("matchers", silence.Matchers))
+
+	result := SilenceResult{Status: "expired", Silence: &silence, MatchedAlerts: len(muted)}
+	return modules.StructuredResult(result, formatSilenceResult(result))
+}
+
+// confirmSilence asks the user to confirm the change of the silence,
+// returning the result to return instead of making it when the user did not
+// approve. matched are the alerts the change mutes or unmutes.
+func (m *Module) confirmSilence(ctx context.Context, change silenceChange, silence Silence, matched []AlertmanagerAlert) (*mcp.CallToolResult, error) {
+	session, ok := modules.ElicitationSession(ctx)
+	if !ok {
+		return nil, fmt.Errorf("silences must be confirmed by the user, but the client does not support elicitation")
+	}
+
+	request := mcp.ElicitationRequest{
+		Params: mcp.ElicitationParams{
+			Message: silenceMessage(change, silence, matched),
+			RequestedSchema: map[string]interface{}{
+				"type": "object",
+				"properties": map[string]interface{}{
+					confirmSilenceProperty: map[string]interface{}{
+						"type":        "boolean",
+						"title":       change.title,
+						"description": change.description,
+						"default":     false,
+					},
+				},
+				"required": []string{confirmSilenceProperty},
+			},
+		},
+	}
+	request.Method = string(mcp.MethodElicitationCreate)
+	result, err := session.RequestElicitation(ctx, request)
+	if err != nil {
+		return nil, fmt.Errorf("failed to ask the user to confirm the silence %s: %w", change.verb, err)
+	}
+	reason := string(result.Action)
+	if result.Action == mcp.ElicitationResponseActionAccept {
+		content, _ := result.Content.(map[string]interface{})
+		if confirmed, _ := content[confirmSilenceProperty].(bool); confirmed {
+			return nil, nil
+		}
+		reason = "not confirmed"
+	}
+	return modules.StructuredResult(SilenceResult{
+		Status:        "cancelled",
+		MatchedAlerts: len(matched),
+		Reason:        reason,
+		Message:       fmt.Sprintf("The user did not approve to %s this silence, do not retry unless asked to", change.verb),
+	}, "")
+}
+
+// silenceMessage describes the change of the silence and the alerts it
+// would mute or unmute
+func silenceMessage(change silenceChange, silence Silence, matched []AlertmanagerAlert) string {
+	var message strings.Builder
+	if change == expireSilence {
+		fmt.Fprintf(&message, "Expire silence %s of alerts matching {%s}, set to end at %s.", silence.ID, strings.Join(silence.Matchers, ", "), silence.EndsAt)
+	} else {
+		fmt.Fprintf(&message, "Silence alerts matching {%s} until %s.", strings.Join(silence.Matchers, ", "), silence.EndsAt)
+	}
+	fmt.Fprintf(&message, "\nComment: %s", silence.Comment)
+	fmt.Fprintf(&message, "\nCreated by: %s", silence.CreatedBy)
+	switch {
+	case len(matched) == 0 && change == expireSilence:
+		message.WriteString("\nIt mutes no alert now.")
+		return message.String()
+	case len(matched) == 0:
+		message.WriteString("\nNo alert matches it now.")
+		return message.String()
+	case change == expireSilence:
+		fmt.Fprintf(&message, "\nIt mutes %d alerts now, they notify again once expired:", len(matched))
+	default:
+		fmt.Fprintf(&message, "\nIt matches %d alerts now:", len(matched))
+	}
+	counts := make(map[string]int)
+	names := []string{}
+	for _, amAlert := range matched {
+		name := amAlert.Labels["alertname"]
+		if counts[name] == 0 {
+			names = append(names, name)
+		}
+		counts[name]++
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		fmt.Fprintf(&message, "\n- %s (%d)", name, counts[name])
+	}
+	return message.String()
+}
+
+// alertmanagerAlerts returns the alerts known to Alertmanager, silenced and
+// inhibited ones included, matching filter
+func (m *Module) alertmanagerAlerts(ctx context.Context, filter url.Values) ([]AlertmanagerAlert, error) {
+	params := url.Values{
+		"active":      {"true"},
+		"silenced":    {"true"},
+		"inhibited":   {"true"},
+		"unprocessed": {"true"},
+	}
+	for _, matcher := range filter["filter"] {
+		params.Add("filter", matcher)
+	}
+	var alerts []AlertmanagerAlert
+	if err := m.alertmanagerAPI(ctx, "GET", "/api/v2/alerts", params, nil, &alerts); err != nil {
+		return nil, err
+	}
+	return alerts, nil
+}
+
+// alertmanagerAPI calls the Alertmanager v2 API and decodes the response into
+// out when it is not nil
+func (m *Module) alertmanagerAPI(ctx context.Context, method, path string, params url.Values, body interface{}, out interface{}) error {
+	if m.alertmanager == nil {
+		return fmt.Errorf("Alertmanager configuration is not available")
+	}
+	if len(params) > 0 {
+		path += "?" + params.Encode()
+	}
+	url := m.alertmanager.config.Endpoint + path
+
+	var reqBody io.Reader
+	if body != nil {
+		jsonData, err := json.Marshal(body)
+		if err != nil {
+			return fmt.Errorf("failed to marshal request body: %w", err)
+		}
+		reqBody = bytes.NewBuffer(jsonData)
+	}
+
+	m.logger.Info("Making Alertmanager Request",
+		zap.String("method", method),
+		zap.String("full_url", url),
+		zap.Bool("has_body", body != nil))
+
+	req, err := http.NewRequestWithContext(ctx, method, url, reqBody)
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Accept", "application/json")
+	config := m.alertmanager.config
+	authMethod := setAuth(req, config.Token, config.Username, config.Password)
+
+	start := time.Now()
+	waiting := modules.ProgressFromContext(ctx).Wait("Waiting for Alertmanager")
+	resp, err := m.alertmanager.httpClient.Do(req)
+	waiting()
+	duration := time.Since(start)
+	if err != nil {
+		m.logger.Error("Alertmanager Request Failed",
+			zap.String("method", method),
+			zap.String("url", url),
+			zap.Error(err))
+		appMetrics.RecordBackendRequest(appMetrics.BackendAlertmanager, alertmanagerInstance, duration, false)
+		errorType := "network_error"
+		if strings.Contains(strings.ToLower(err.Error()), "timeout") {
+			errorType = "timeout"
+		}
+		appMetrics.RecordBackendError(appMetrics.BackendAlertmanager, alertmanagerInstance, errorType)
+		return fmt.Errorf("failed to execute request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	success := resp.StatusCode >= 200 && resp.StatusCode < 300
+	appMetrics.RecordBackendRequest(appMetrics.BackendAlertmanager, alertmanagerInstance, duration, success)
+	m.logger.Info("Alertmanager Response Received",
+		zap.String("method", method),
+		zap.String("url", url),
+		zap.Int("status_code", resp.StatusCode),
+		zap.String("auth_method", authMethod))
+
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("failed to read response body: %w", err)
+	}
+	if !success {
+		errorType := "http_error"
+		if resp.StatusCode == 401 || resp.StatusCode == 403 {
+			errorType = "auth_error"
+		} else if resp.StatusCode >= 500 {
+			errorType = "server_error"
+		}
+		appMetrics.RecordBackendError(appMetrics.BackendAlertmanager, alertmanagerInstance, errorType)
+		message := strings.TrimSpace(string(respBody))
+		if len(message) > maxErrorBody {
+			message = message[:maxErrorBody] + "..."
+		}
+		return fmt.Errorf("Alertmanager API returned status %d: %s", resp.StatusCode, message)
+	}
+	if out == nil {
+		return nil
+	}
+	if err := json.Unmarshal(respBody, out); err != nil {
+		return fmt.Errorf("failed to decode response: %w", err)
+	}
+	return nil
+}
+
+// newManagedAlert lifts the alertname and severity labels out of an
+// Alertmanager alert
+func newManagedAlert(amAlert AlertmanagerAlert) ManagedAlert {
+	alert := newAlert(PrometheusAlert{Labels: amAlert.Labels})
+	managed := ManagedAlert{
+		Fingerprint:  amAlert.Fingerprint,
+		Name:         alert.Name,
+		State:        amAlert.Status.State,
+		Severity:     alert.Severity,
+		Labels:       alert.Labels,
+		Annotations:  amAlert.Annotations,
+		StartsAt:     amAlert.StartsAt,
+		EndsAt:       amAlert.EndsAt,
+		GeneratorURL: amAlert.GeneratorURL,
+		SilencedBy:   amAlert.Status.SilencedBy,
+		InhibitedBy:  amAlert.Status.InhibitedBy,
+	}
+	for _, receiver := range amAlert.Receivers {
+		managed.Receivers = append(managed.Receivers, receiver.Name)
+	}
+	return managed
+}
+
+// newSilence renders the matchers of an Alertmanager silence
+func newSilence(amSilence AlertmanagerSilence) Silence {
+	silence := Silence{
+		ID:        amSilence.ID,
+		Matchers:  make([]string, 0, len(amSilence.Matchers)),
+		StartsAt:  amSilence.StartsAt,
+		EndsAt:    amSilence.EndsAt,
+		CreatedBy: amSilence.CreatedBy,
+		Comment:   amSilence.Comment,
+	}
+	if amSilence.Status != nil {
+		silence.State = amSilence.Status.State
+	}
+	for _, matcher := range amSilence.Matchers {
+		negative := matcher.IsEqual != nil && !*matcher.IsEqual
+		op := "="
+		switch {
+		case matcher.IsRegex && negative:
+			op = "!~"
+		case matcher.IsRegex:
+			op = "=~"
+		case negative:
+			op = "!="
+		}
+		silence.Matchers = append(silence.Matchers, labelMatcher{name: matcher.Name, op: op, value: matcher.Value}.String())
+	}
+	return silence
+}
+
+// alertmanagerMatcher converts the matcher to an Alertmanager silence matcher
+func (lm labelMatcher) alertmanagerMatcher() AlertmanagerMatcher {
+	isEqual := lm.op == "=" || lm.op == "=~"
+	return AlertmanagerMatcher{
+		Name:    lm.name,
+		Value:   lm.value,
+		IsRegex: lm.re != nil,
+		IsEqual: &isEqual,
+	}
+}
+
+// sortManagedAlerts orders alerts active first, then by severity, name and start
+func sortManagedAlerts(alerts []ManagedAlert) {
+	sort.SliceStable(alerts, func(i, j int) bool {
+		a, b := alerts[i], alerts[j]
+		if a.State != b.State {
+			return a.State == "active"
+		}
+		if ra, rb := severityRank(a.Severity), severityRank(b.Severity); ra != rb {
+			return ra < rb
+		}
+		if a.Name != b.Name {
+			return a.Name < b.Name
+		}
+		return a.StartsAt < b.StartsAt
+	})
+}
+
+// formatAlertGroups renders alert groups with one alert per line for the
+// text content
+func formatAlertGroups(response AlertGroupsResponse, returned int, now time.Time) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "%d alerts in %d groups: %d active, %d suppressed", response.TotalCount, len(response.Groups), response.Active, response.Suppressed)
+	for _, group := range response.Groups {
+		fmt.Fprintf(&b, "\n%s -> %s", formatLabels(group.Labels), group.Receiver)
+		for _, alert := range group.Alerts {
+			b.WriteString("\n  " + formatManagedAlert(alert, now))
+		}
+	}
+	if response.Truncated {
+		fmt.Fprintf(&b, "\n... %d more alerts, raise limit to see them", response.TotalCount-returned)
+	}
+	return b.String()
+}
+
+// formatManagedAlert renders an alert on one line
+func formatManagedAlert(alert ManagedAlert, now time.Time) string {
+	var b strings.Builder
+	b.WriteString(alert.State)
+	if alert.Severity != "" {
+		b.WriteString(" " + alert.Severity)
+	}
+	b.WriteString(" " + alert.Name)
+	if len(alert.Labels) > 0 {
+		b.WriteString(" " + formatLabels(alert.Labels))
+	}
+	if startsAt, err := time.Parse(time.RFC3339Nano, alert.StartsAt); err == nil {
+		fmt.Fprintf(&b, " since %s (%s)", startsAt.UTC().Format(time.RFC3339), now.Sub(startsAt).Round(time.Second))
+	}
+	fmt.Fprintf(&b, " fingerprint %s", alert.Fingerprint)
+	if len(alert.SilencedBy) > 0 {
+		fmt.Fprintf(&b, " silenced by %s", strings.Join(alert.SilencedBy, ", "))
+	}
+	if len(alert.InhibitedBy) > 0 {
+		fmt.Fprintf(&b, " inhibited by %s", strings.Join(alert.InhibitedBy, ", "))
+	}
+	if summary := alertSummary(alert.Annotations); summary != "" {
+		b.WriteString(" - " + summary)
+	}
+	return b.String()
+}
+
+// formatAlertDetails renders an alert with its annotations, silences and
+// inhibiting alerts for the text content
+func formatAlertDetails(response AlertDetailsResponse, now time.Time) string {
+	alert := response.Alert
+	var b strings.Builder
+	b.WriteString(formatManagedAlert(alert, now))
+	if len(alert.Receivers) > 0 {
+		fmt.Fprintf(&b, "\n  receivers: %s", strings.Join(alert.Receivers, ", "))
+	}
+	for _, name := range sortedKeys(alert.Annotations) {
+		fmt.Fprintf(&b, "\n  %s: %s", name, oneLine(alert.Annotations[name]))
+	}
+	if alert.GeneratorURL != "" {
+		fmt.Fprintf(&b, "\n  source: %s", alert.GeneratorURL)
+	}
+	for _, silence := range response.Silences {
+		fmt.Fprintf(&b, "\n  silence %s", formatSilence(silence))
+	}
+	for _, inhibitor := range response.InhibitedBy {
+		fmt.Fprintf(&b, "\n  inhibited by %s", formatManagedAlert(inhibitor, now))
+	}
+	return b.String()
+}
+
+// formatSilences renders silences one per line for the text content
+func formatSilences(response SilencesResponse) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "%d silences", response.TotalCount)
+	for _, silence := range response.Silences {
+		b.WriteString("\n" + formatSilence(silence))
+	}
+	if response.Truncated {
+		fmt.Fprintf(&b, "\n... %d more silences, raise limit to see them", response.TotalCount-len(response.Silences))
+	}
+	return b.String()
+}
+
+// formatSilence renders a silence on one line
+func formatSilence(silence Silence) string {
+	text := fmt.Sprintf("%s {%s} until %s by %s: %s", silence.ID, strings.Join(silence.Matchers, ", "), silence.EndsAt, silence.CreatedBy, oneLine(silence.Comment))
+	if silence.State != "" {
+		text = silence.State + " " + text
+	}
+	return text
+}
+
+// formatSilenceResult renders a created or expired silence
+func formatSilenceResult(result SilenceResult) string {
+	switch result.Status {
+	case "created":
+		return fmt.Sprintf("Created silence %s matching %d alerts now\n%s", result.Silence.ID, result.MatchedAlerts, formatSilence(*result.Silence))
+	default:
+		return fmt.Sprintf("Expired silence %s\n%s", result.Silence.ID, formatSilence(*result.Silence))
+	}
+}
@@ -0,0 +1,163 @@
+package metrics
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+	"go.uber.org/zap"
+
+	"github.com/shaowenchen/ops-mcp-server/pkg/auth"
+)
+
+// elicitingSession is a client session answering elicitations with confirm
+type elicitingSession struct {
+	confirm  bool
+	messages []string
+}
+
+func (s *elicitingSession) Initialize()                                         {}
+func (s *elicitingSession) Initialized() bool                                   { return true }
+func (s *elicitingSession) NotificationChannel() chan<- mcp.JSONRPCNotification { return nil }
+func (s *elicitingSession) SessionID() string                                   { return "test" }
+
+func (s *elicitingSession) RequestElicitation(ctx context.Context, request mcp.ElicitationRequest) (*mcp.ElicitationResult, error) {
+	s.messages = append(s.messages, request.Params.Message)
+	result := &mcp.ElicitationResult{}
+	result.Action = mcp.ElicitationResponseActionAccept
+	result.Content = map[string]interface{}{confirmSilenceProperty: s.confirm}
+	return result, nil
+}
+
+// fakeAlertmanager records the silences created and expired through it
+type fakeAlertmanager struct {
+	created []AlertmanagerSilence
+	expired []string
+}
+
+// newAlertmanagerModule creates a module against a fake Alertmanager with
+// one alert muted by silence s-1
+func newAlertmanagerModule(t *testing.T) (*Module, *fakeAlertmanager) {
+	t.Helper()
+	fake := &fakeAlertmanager{}
+	am := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.Method == http.MethodGet && r.URL.Path == "/api/v2/alerts":
+			io.WriteString(w, `[{"labels":{"alertname":"HighErrorRate"},"status":{"state":"suppressed","silencedBy":["s-1"]}}]`)
+		case r.Method == http.MethodPost && r.URL.Path == "/api/v2/silences":
+			var silence AlertmanagerSilence
+			json.NewDecoder(r.Body).Decode(&silence)
+			fake.created = append(fake.created, silence)
+			io.WriteString(w, `{"silenceID":"s-2"}`)
+		case r.Method == http.MethodGet && r.URL.Path == "/api/v2/silence/s-1":
+			io.WriteString(w, `{"id":"s-1","matchers":[{"name":"alertname","value":"HighErrorRate","isRegex":false}],"endsAt":"2026-10-16T12:00:00Z","createdBy":"alice","comment":"INC-42","status":{"state":"active"}}`)
+		case r.Method == http.MethodDelete && r.URL.Path == "/api/v2/silence/s-1":
+			fake.expired = append(fake.expired, "s-1")
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	t.Cleanup(am.Close)
+
+	m, err := New(&Config{Alertmanager: &AlertmanagerConfig{Endpoint: am.URL}}, zap.NewNop())
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	return m, fake
+}
+
+// withSession returns ctx with session as the client session of the call
+func withSession(ctx context.Context, session server.ClientSession) context.Context {
+	return server.NewMCPServer("test", "1.0.0").WithContext(ctx, session)
+}
+
+func TestCreateSilenceAuthor(t *testing.T) {
+	tests := []struct {
+		name     string
+		identity *auth.Identity
+		want     string
+	}{
+		{"authenticated", &auth.Identity{Name: "alice", Source: auth.SourceToken}, "alice"},
+		{"anonymous", nil, "mallory"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m, fake := newAlertmanagerModule(t)
+			ctx := withSession(context.Background(), &elicitingSession{confirm: true})
+			if tt.identity != nil {
+				ctx = auth.WithIdentity(ctx, tt.identity)
+			}
+			var request mcp.CallToolRequest
+			request.Params.Arguments = map[string]any{
+				"matchers":   []any{`alertname="HighErrorRate"`},
+				"duration":   "2h",
+				"comment":    "INC-42",
+				"created_by": "mallory",
+			}
+
+			result, err := m.handleCreateSilence(ctx, request)
+			if err != nil {
+				t.Fatalf("handleCreateSilence: %v", err)
+			}
+			if result.IsError || len(fake.created) != 1 {
+				t.Fatalf("silence not created: %v", result.Content)
+			}
+			if got := fake.created[0].CreatedBy; got != tt.want {
+				t.Errorf("silence created by %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestExpireSilenceConfirmation(t *testing.T) {
+	tests := []struct {
+		name        string
+		confirm     bool
+		wantExpired bool
+		wantStatus  string
+	}{
+		{"confirmed", true, true, "expired"},
+		{"not confirmed", false, false, "cancelled"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m, fake := newAlertmanagerModule(t)
+			session := &elicitingSession{confirm: tt.confirm}
+			var request mcp.CallToolRequest
+			request.Params.Arguments = map[string]any{"silence_id": "s-1"}
+
+			result, err := m.handleExpireSilence(withSession(context.Background(), session), request)
+			if err != nil {
+				t.Fatalf("handleExpireSilence: %v", err)
+			}
+			if len(session.messages) != 1 || !strings.Contains(session.messages[0], "It mutes 1 alerts now") {
+				t.Errorf("user asked %q, want the alert the silence mutes", session.messages)
+			}
+			if expired := len(fake.expired) > 0; expired != tt.wantExpired {
+				t.Errorf("silence expired %v, want %v", expired, tt.wantExpired)
+			}
+			if got := result.StructuredContent.(SilenceResult).Status; got != tt.wantStatus {
+				t.Errorf("status %s, want %s", got, tt.wantStatus)
+			}
+		})
+	}
+}
+
+func TestExpireSilenceRequiresElicitation(t *testing.T) {
+	m, fake := newAlertmanagerModule(t)
+	var request mcp.CallToolRequest
+	request.Params.Arguments = map[string]any{"silence_id": "s-1"}
+
+	if _, err := m.handleExpireSilence(context.Background(), request); err == nil {
+		t.Error("silence expired without a client supporting elicitation")
+	}
+	if len(fake.expired) > 0 {
+		t.Error("silence expired without confirmation")
+	}
+}
@@ -0,0 +1,431 @@
+package metrics
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/shaowenchen/ops-mcp-server/pkg/modules"
+)
+
+// severityRanks orders alerts of the common severities, most severe first
+var severityRanks = map[string]int{
+	"critical": 0,
+	"error":    1,
+	"warning":  2,
+	"info":     3,
+}
+
+func (m *Module) handleListAlerts(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	args := request.GetArguments()
+
+	ds, err := m.datasource(args)
+	if err != nil {
+		return nil, err
+	}
+
+	state, _ := args["state"].(string)
+	state = strings.ToLower(state)
+	if state != "" && state != "firing" && state != "pending" {
+		return nil, fmt.Errorf("invalid state '%s': must be firing or pending", state)
+	}
+	var severities []string
+	if severity, ok := args["severity"].(string); ok {
+		for _, s := range strings.Split(severity, ",") {
+			if s = strings.ToLower(strings.TrimSpace(s)); s != "" {
+				severities = append(severities, s)
+			}
+		}
+	}
+	matchers, err := labelMatchers(args["labels"])
+	if err != nil {
+		return nil, err
+	}
+	limit, err := parseLimit(args)
+	if err != nil {
+		return nil, err
+	}
+
+	var data struct {
+		Alerts []PrometheusAlert `json:"alerts"`
+	}
+	if _, err := m.prometheusAPI(ctx, ds, "/api/v1/alerts", nil, &data); err != nil {
+		return nil, fmt.Errorf("failed to list alerts: %w", err)
+	}
+
+	response := AlertsResponse{
+		Datasource: ds.name,
+		State:      state,
+		Severity:   severities,
+		Alerts:     []Alert{},
+		Limit:      limit,
+	}
+	for _, matcher := range matchers {
+		response.Matchers = append(response.Matchers, matcher.String())
+	}
+	for _, promAlert := range data.Alerts {
+		if state != "" && promAlert.State != state {
+			continue
+		}
+		if len(severities) > 0 && !containsFold(severities, promAlert.Labels["severity"]) {
+			continue
+		}
+		if !matchesAll(matchers, promAlert.Labels) {
+			continue
+		}
+		response.Alerts = append(response.Alerts, newAlert(promAlert))
+		switch promAlert.State {
+		case "firing":
+			response.Firing++
+		case "pending":
+			response.Pending++
+		}
+	}
+	sortAlerts(response.Alerts)
+	response.TotalCount = len(response.Alerts)
+	response.Alerts, response.Truncated = truncate(response.Alerts, limit)
+
+	return modules.StructuredResult(response, formatAlerts(response, time.Now()))
+}
+
+func (m *Module) handleGetAlertRules(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	args := request.GetArguments()
+
+	name, ok := args["alert"].(string)
+	if !ok || name == "" {
+		return nil, fmt.Errorf("alert parameter is required")
+	}
+	ds, err := m.datasource(args)
+	if err != nil {
+		return nil, err
+	}
+
+	groups, err := m.alertingRules(ctx, ds, name)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get rules of alert %s: %w", name, err)
+	}
+
+	response := AlertRulesResponse{Datasource: ds.name, Alert: name, Rules: []AlertRule{}}
+	for _, group := range groups {
+		for _, rule := range group.Rules {
+			// Older Prometheus versions ignore the rule_name[] filter
+			if rule.Type != "alerting" || rule.Name != name {
+				continue
+			}
+			response.Rules = append(response.Rules, newAlertRule(group, rule))
+		}
+	}
+	response.TotalCount = len(response.Rules)
+
+	return modules.StructuredResult(response, formatAlertRules(response))
+}
+
+// alertingRules returns the rule groups of ds with their alerting rules,
+// only those of the alert name when it is set
+func (m *Module) alertingRules(ctx context.Context, ds *datasource, name string) ([]PrometheusRuleGroup, error) {
+	params := url.Values{"type": {"alert"}}
+	if name != "" {
+		params.Set("rule_name[]", name)
+	}
+	var data struct {
+		Groups []PrometheusRuleGroup `json:"groups"`
+	}
+	if _, err := m.prometheusAPI(ctx, ds, "/api/v1/rules", params, &data); err != nil {
+		return nil, err
+	}
+	return data.Groups, nil
+}
+
+// alertNames returns the names of the alerting rules of ds
+func (m *Module) alertNames(ctx context.Context, ds *datasource) ([]string, error) {
+	groups, err := m.alertingRules(ctx, ds, "")
+	if err != nil {
+		return nil, err
+	}
+	seen := make(map[string]bool)
+	var names []string
+	for _, group := range groups {
+		for _, rule := range group.Rules {
+			if rule.Type == "alerting" && !seen[rule.Name] {
+				seen[rule.Name] = true
+				names = append(names, rule.Name)
+			}
+		}
+	}
+	sort.Strings(names)
+	return names, nil
+}
+
+// newAlert lifts the alertname and severity labels out of a Prometheus alert
+func newAlert(promAlert PrometheusAlert) Alert {
+	labels := make(map[string]string, len(promAlert.Labels))
+	for name, value := range promAlert.Labels {
+		if name != "alertname" && name != "severity" {
+			labels[name] = value
+		}
+	}
+	return Alert{
+		Name:        promAlert.Labels["alertname"],
+		State:       promAlert.State,
+		Severity:    promAlert.Labels["severity"],
+		Labels:      labels,
+		Annotations: promAlert.Annotations,
+		ActiveAt:    promAlert.ActiveAt,
+		Value:       promAlert.Value,
+	}
+}
+
+// newAlertRule converts an alerting rule of group
+func newAlertRule(group PrometheusRuleGroup, rule PrometheusRule) AlertRule {
+	alertRule := AlertRule{
+		Name:           rule.Name,
+		Group:          group.Name,
+		File:           group.File,
+		Query:          rule.Query,
+		Labels:         rule.Labels,
+		Annotations:    rule.Annotations,
+		State:          rule.State,
+		ActiveAlerts:   len(rule.Alerts),
+		Health:         rule.Health,
+		LastError:      rule.LastError,
+		LastEvaluation: rule.LastEvaluation,
+	}
+	if rule.Duration > 0 {
+		alertRule.For = formatSeconds(rule.Duration)
+	}
+	if rule.KeepFiringFor > 0 {
+		alertRule.KeepFiringFor = formatSeconds(rule.KeepFiringFor)
+	}
+	if rule.EvaluationTime > 0 {
+		alertRule.EvaluationTime = formatSeconds(rule.EvaluationTime)
+	}
+	return alertRule
+}
+
+// sortAlerts orders alerts firing first, then by severity, name and start
+func sortAlerts(alerts []Alert) {
+	sort.SliceStable(alerts, func(i, j int) bool {
+		a, b := alerts[i], alerts[j]
+		if a.State != b.State {
+			return a.State == "firing"
+		}
+		if ra, rb := severityRank(a.Severity), severityRank(b.Severity); ra != rb {
+			return ra < rb
+		}
+		if a.Name != b.Name {
+			return a.Name < b.Name
+		}
+		return a.ActiveAt < b.ActiveAt
+	})
+}
+
+// severityRank returns the rank of severity, unknown severities last
+func severityRank(severity string) int {
+	if rank, ok := severityRanks[strings.ToLower(severity)]; ok {
+		return rank
+	}
+	return len(severityRanks)
+}
+
+// formatAlerts renders alerts one per line for the text content
+func formatAlerts(response AlertsResponse, now time.Time) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "%d alerts: %d firing, %d pending", response.TotalCount, response.Firing, response.Pending)
+	for _, alert := range response.Alerts {
+		b.WriteString("\n")
+		b.WriteString(alert.State)
+		if alert.Severity != "" {
+			b.WriteString(" " + alert.Severity)
+		}
+		b.WriteString(" " + alert.Name)
+		if len(alert.Labels) > 0 {
+			b.WriteString(" " + formatLabels(alert.Labels))
+		}
+		if activeAt, err := time.Parse(time.RFC3339Nano, alert.ActiveAt); err == nil {
+			fmt.Fprintf(&b, " since %s (%s)", activeAt.UTC().Format(time.RFC3339), now.Sub(activeAt).Round(time.Second))
+		}
+		if alert.Value != "" {
+			b.WriteString(" value " + alert.Value)
+		}
+		if summary := alertSummary(alert.Annotations); summary != "" {
+			b.WriteString(" - " + summary)
+		}
+	}
+	if response.Truncated {
+		fmt.Fprintf(&b, "\n... %d more alerts, raise limit to see them", response.TotalCount-len(response.Alerts))
+	}
+	return b.String()
+}
+
+// formatAlertRules renders alerting rules for the text content
+func formatAlertRules(response AlertRulesResponse) string {
+	if len(response.Rules) == 0 {
+		return fmt.Sprintf("No alerting rule named %s in datasource %s", response.Alert, response.Datasource)
+	}
+	var b strings.Builder
+	for i, rule := range response.Rules {
+		if i > 0 {
+			b.WriteString("\n\n")
+		}
+		fmt.Fprintf(&b, "%s (group %s): %s, %d active, health %s", rule.Name, rule.Group, rule.State, rule.ActiveAlerts, rule.Health)
+		fmt.Fprintf(&b, "\n  expr: %s", oneLine(rule.Query))
+		if rule.For != "" {
+			fmt.Fprintf(&b, "\n  for: %s", rule.For)
+		}
+		if rule.KeepFiringFor != "" {
+			fmt.Fprintf(&b, "\n  keep_firing_for: %s", rule.KeepFiringFor)
+		}
+		if len(rule.Labels) > 0 {
+			fmt.Fprintf(&b, "\n  labels: %s", formatLabels(rule.Labels))
+		}
+		for _, name := range sortedKeys(rule.Annotations) {
+			fmt.Fprintf(&b, "\n  %s: %s", name, oneLine(rule.Annotations[name]))
+		}
+		if rule.LastEvaluation != "" {
+			fmt.Fprintf(&b, "\n  last evaluation: %s", rule.LastEvaluation)
+			if rule.EvaluationTime != "" {
+				fmt.Fprintf(&b, " (took %s)", rule.EvaluationTime)
+			}
+		}
+		if rule.LastError != "" {
+			fmt.Fprintf(&b, "\n  last error: %s", rule.LastError)
+		}
+	}
+	return b.String()
+}
+
+// alertSummary returns the summary annotation of an alert, its description
+// when there is no summary
+func alertSummary(annotations map[string]string) string {
+	if summary := annotations["summary"]; summary != "" {
+		return oneLine(summary)
+	}
+	return oneLine(annotations["description"])
+}
+
+// formatLabels renders labels as a PromQL selector sorted by name
+func formatLabels(labels map[string]string) string {
+	pairs := make([]string, 0, len(labels))
+	for _, name := range sortedKeys(labels) {
+		pairs = append(pairs, fmt.Sprintf("%s=%q", name, labels[name]))
+	}
+	return "{" + strings.Join(pairs, ", ") + "}"
+}
+
+// formatSeconds renders seconds as a Go duration without trailing zero units
+func formatSeconds(seconds float64) string {
+	s := time.Duration(seconds * float64(time.Second)).Round(time.Millisecond).String()
+	if strings.HasSuffix(s, "m0s") {
+		s = strings.TrimSuffix(s, "0s")
+	}
+	if strings.HasSuffix(s, "h0m") {
+		s = strings.TrimSuffix(s, "0m")
+	}
+	return s
+}
+
+// oneLine collapses the whitespace of multi-line expressions and annotations
+func oneLine(s string) string {
+	return strings.Join(strings.Fields(s), " ")
+}
+
+func sortedKeys(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func containsFold(values []string, value string) bool {
+	for _, v := range values {
+		if strings.EqualFold(v, value) {
+			return true
+		}
+	}
+	return false
+}
+
+// labelMatcher is a Prometheus label matcher like namespace="payments" or
+// pod=~"api-.*", matched against alert labels
+type labelMatcher struct {
+	name  string
+	op    string
+	value string
+	re    *regexp.Regexp
+}
+
+var labelMatcherPattern = regexp.MustCompile(`^\s*([a-zA-Z_][a-zA-Z0-9_]*)\s*(=~|!~|!=|=)\s*(.*?)\s*$`)
+
+// labelMatchers parses the labels argument, a matcher or a list of them
+func labelMatchers(arg interface{}) ([]labelMatcher, error) {
+	var exprs []string
+	switch arg := arg.(type) {
+	case string:
+		if arg != "" {
+			exprs = []string{arg}
+		}
+	case []interface{}:
+		for _, item := range arg {
+			if expr, ok := item.(string); ok && expr != "" {
+				exprs = append(exprs, expr)
+			}
+		}
+	}
+
+	matchers := make([]labelMatcher, 0, len(exprs))
+	for _, expr := range exprs {
+		parts := labelMatcherPattern.FindStringSubmatch(expr)
+		if parts == nil {
+			return nil, fmt.Errorf("invalid label matcher '%s': expected name=\"value\", name!=\"value\", name=~\"regex\" or name!~\"regex\"", expr)
+		}
+		matcher := labelMatcher{name: parts[1], op: parts[2], value: parts[3]}
+		if len(matcher.value) >= 2 && strings.HasPrefix(matcher.value, `"`) && strings.HasSuffix(matcher.value, `"`) {
+			matcher.value = matcher.value[1 : len(matcher.value)-1]
+		}
+		if matcher.op == "=~" || matcher.op == "!~" {
+			re, err := regexp.Compile("^(?:" + matcher.value + ")$")
+			if err != nil {
+				return nil, fmt.Errorf("invalid regex in label matcher '%s': %w", expr, err)
+			}
+			matcher.re = re
+		}
+		matchers = append(matchers, matcher)
+	}
+	return matchers, nil
+}
+
+// matches reports whether labels satisfy the matcher, a missing label
+// having the empty value as in PromQL
+func (lm labelMatcher) matches(labels map[string]string) bool {
+	value := labels[lm.name]
+	switch lm.op {
+	case "=":
+		return value == lm.value
+	case "!=":
+		return value != lm.value
+	case "=~":
+		return lm.re.MatchString(value)
+	default:
+		return !lm.re.MatchString(value)
+	}
+}
+
+func (lm labelMatcher) String() string {
+	return fmt.Sprintf("%s%s%q", lm.name, lm.op, lm.value)
+}
+
+func matchesAll(matchers []labelMatcher, labels map[string]string) bool {
+	for _, matcher := range matchers {
+		if !matcher.matches(labels) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,54 @@
+package metrics
+
+import "context"
+
+// completedLabels are the labels whose values are suggested for arguments of the same name
+var completedLabels = map[string]bool{
+	"namespace": true,
+	"pod":       true,
+	"container": true,
+	"service":   true,
+	"job":       true,
+	"instance":  true,
+	"node":      true,
+}
+
+// CompleteArgument suggests datasource names, metric names for queries and
+// selectors, label names, alert names, and the values of common labels of
+// the datasource selected in args
+func (m *Module) CompleteArgument(ctx context.Context, name string, args map[string]string) ([]string, error) {
+	if name == "datasource" {
+		return m.datasourceNames, nil
+	}
+
+	label := name
+	switch {
+	case name == "query" || name == "metric" || name == "match":
+		label = "__name__"
+	case name == "label" || name == "alert":
+		// Label names and alert names rather than values, listed below
+	case !completedLabels[name]:
+		return nil, nil
+	}
+
+	ds, err := m.datasource(map[string]interface{}{"datasource": args["datasource"]})
+	if err != nil {
+		// Nothing to complete until a known datasource is chosen
+		return nil, nil
+	}
+	if name == "alert" {
+		return m.completions.Get(ctx, ds.name+"/alerts", func(ctx context.Context) ([]string, error) {
+			return m.alertNames(ctx, ds)
+		})
+	}
+	if name == "label" {
+		return m.completions.Get(ctx, ds.name+"/labels", func(ctx context.Context) ([]string, error) {
+			var labels []string
+			_, err := m.prometheusAPI(ctx, ds, "/api/v1/labels", nil, &labels)
+			return labels, err
+		})
+	}
+	return m.completions.Get(ctx, ds.name+"/"+label, func(ctx context.Context) ([]string, error) {
+		return m.listLabelValues(ctx, ds, label, "")
+	})
+}
@@ -0,0 +1,263 @@
+package metrics
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/shaowenchen/ops-mcp-server/pkg/modules"
+	"go.uber.org/zap"
+)
+
+// defaultLimit is how many labels, values, series or alerts are returned
+// when the limit argument is not set
+const defaultLimit = 100
+
+func (m *Module) handleListLabels(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	args := request.GetArguments()
+
+	ds, err := m.datasource(args)
+	if err != nil {
+		return nil, err
+	}
+	selection, err := metadataSelection(args)
+	if err != nil {
+		return nil, err
+	}
+	selection.Datasource = ds.name
+
+	var labels []string
+	selection.Warnings, err = m.prometheusAPI(ctx, ds, "/api/v1/labels", selection.params(), &labels)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list labels: %w", err)
+	}
+	labels, selection.Truncated = truncate(labels, selection.Limit)
+
+	return modules.StructuredResult(LabelsResponse{
+		MetadataSelection: selection,
+		Labels:            labels,
+		TotalCount:        len(labels),
+	}, "")
+}
+
+func (m *Module) handleListLabelValues(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	args := request.GetArguments()
+
+	label, ok := args["label"].(string)
+	if !ok || label == "" {
+		return nil, fmt.Errorf("label parameter is required")
+	}
+	ds, err := m.datasource(args)
+	if err != nil {
+		return nil, err
+	}
+	selection, err := metadataSelection(args)
+	if err != nil {
+		return nil, err
+	}
+	selection.Datasource = ds.name
+
+	var values []string
+	selection.Warnings, err = m.prometheusAPI(ctx, ds, "/api/v1/label/"+url.PathEscape(label)+"/values", selection.params(), &values)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list values of label %s: %w", label, err)
+	}
+	values, selection.Truncated = truncate(values, selection.Limit)
+
+	return modules.StructuredResult(LabelValuesResponse{
+		MetadataSelection: selection,
+		Label:             label,
+		Values:            values,
+		TotalCount:        len(values),
+	}, "")
+}
+
+func (m *Module) handleFindSeries(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	args := request.GetArguments()
+
+	ds, err := m.datasource(args)
+	if err != nil {
+		return nil, err
+	}
+	selection, err := metadataSelection(args)
+	if err != nil {
+		return nil, err
+	}
+	if len(selection.Match) == 0 {
+		return nil, fmt.Errorf("match parameter is required, e.g. {job=\"node\"}")
+	}
+	selection.Datasource = ds.name
+
+	var series []map[string]string
+	selection.Warnings, err = m.prometheusAPI(ctx, ds, "/api/v1/series", selection.params(), &series)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find series: %w", err)
+	}
+	series, selection.Truncated = truncate(series, selection.Limit)
+
+	return modules.StructuredResult(SeriesResponse{
+		MetadataSelection: selection,
+		Series:            series,
+		TotalCount:        len(series),
+	}, "")
+}
+
+// metadataSelection reads the match, start, end, time_range and limit
+// arguments of the labels, label values and series tools. A time_range
+// sets start that long before end, or before now when end is not set.
+func metadataSelection(args map[string]interface{}) (MetadataSelection, error) {
+	var selection MetadataSelection
+
+	switch match := args["match"].(type) {
+	case string:
+		if match != "" {
+			selection.Match = []string{match}
+		}
+	case []interface{}:
+		for _, item := range match {
+			if selector, ok := item.(string); ok && selector != "" {
+				selection.Match = append(selection.Match, selector)
+			}
+		}
+	}
+
+	selection.Start, _ = args["start"].(string)
+	selection.End, _ = args["end"].(string)
+	if timeRange, ok := args["time_range"].(string); ok && timeRange != "" {
+		if selection.Start != "" {
+			return selection, fmt.Errorf("set either start or time_range, not both")
+		}
+		duration, err := parseTimeRange(timeRange)
+		if err != nil {
+			return selection, fmt.Errorf("invalid time_range format '%s': %w (supported units: s, m, h, d - examples: 5m, 1h, 24h, 7d)", timeRange, err)
+		}
+		end := time.Now()
+		if selection.End != "" {
+			if end, err = parsePrometheusTime(selection.End); err != nil {
+				return selection, err
+			}
+		} else {
+			selection.End = strconv.FormatInt(end.Unix(), 10)
+		}
+		selection.Start = strconv.FormatInt(end.Add(-duration).Unix(), 10)
+	}
+
+	limit, err := parseLimit(args)
+	if err != nil {
+		return selection, err
+	}
+	selection.Limit = limit
+	return selection, nil
+}
+
+// parseLimit reads the limit argument, defaultLimit when it is not set
+func parseLimit(args map[string]interface{}) (int, error) {
+	return positiveArgument(args, "limit", defaultLimit)
+}
+
+// positiveArgument reads a positive number argument, defaultValue when it
+// is not set
+func positiveArgument(args map[string]interface{}, name string, defaultValue int) (int, error) {
+	value, ok := args[name].(string)
+	if !ok || value == "" {
+		return defaultValue, nil
+	}
+	parsed, err := strconv.Atoi(value)
+	if err != nil || parsed <= 0 {
+		return 0, fmt.Errorf("invalid %s '%s': must be a positive number", name, value)
+	}
+	return parsed, nil
+}
+
+// params returns the query parameters of the selection. One more result than
+// the limit is asked for to tell whether the results were truncated.
+func (s MetadataSelection) params() url.Values {
+	params := url.Values{}
+	for _, selector := range s.Match {
+		params.Add("match[]", selector)
+	}
+	if s.Start != "" {
+		params.Set("start", s.Start)
+	}
+	if s.End != "" {
+		params.Set("end", s.End)
+	}
+	if s.Limit > 0 {
+		params.Set("limit", strconv.Itoa(s.Limit+1))
+	}
+	return params
+}
+
+// parsePrometheusTime parses a time in one of the formats Prometheus accepts,
+// RFC 3339 or a Unix timestamp in seconds
+func parsePrometheusTime(value string) (time.Time, error) {
+	if seconds, err := strconv.ParseFloat(value, 64); err == nil {
+		whole, fraction := math.Modf(seconds)
+		return time.Unix(int64(whole), int64(fraction*float64(time.Second))), nil
+	}
+	t, err := time.Parse(time.RFC3339Nano, value)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid time '%s': use RFC 3339 or a Unix timestamp", value)
+	}
+	return t, nil
+}
+
+// truncate returns the first limit items and whether there were more
+func truncate[T any](items []T, limit int) ([]T, bool) {
+	if limit > 0 && len(items) > limit {
+		return items[:limit], true
+	}
+	return items, false
+}
+
+// prometheusAPI calls a GET endpoint of the Prometheus HTTP API of ds and
+// decodes its data into out, returning the warnings of the response
+func (m *Module) prometheusAPI(ctx context.Context, ds *datasource, path string, params url.Values, out interface{}) ([]string, error) {
+	if len(params) > 0 {
+		path += "?" + params.Encode()
+	}
+	resp, err := m.makePrometheusRequest(ctx, ds, "GET", path, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response body: %w", err)
+	}
+
+	var apiResp struct {
+		Status    string          `json:"status"`
+		Data      json.RawMessage `json:"data"`
+		ErrorType string          `json:"errorType"`
+		Error     string          `json:"error"`
+		Warnings  []string        `json:"warnings"`
+	}
+	if err := json.Unmarshal(respBody, &apiResp); err != nil {
+		if resp.StatusCode != http.StatusOK {
+			return nil, fmt.Errorf("Prometheus API returned status %d", resp.StatusCode)
+		}
+		m.logger.Error("Failed to decode response",
+			zap.Error(err),
+			zap.String("response_body", string(respBody)))
+		return nil, fmt.Errorf("failed to decode response: %w", err)
+	}
+	if apiResp.Status != "success" {
+		if apiResp.Error != "" {
+			return nil, fmt.Errorf("Prometheus API error (%s): %s", apiResp.ErrorType, apiResp.Error)
+		}
+		return nil, fmt.Errorf("Prometheus API returned status %d", resp.StatusCode)
+	}
+	if err := json.Unmarshal(apiResp.Data, out); err != nil {
+		return nil, fmt.Errorf("failed to decode response data: %w", err)
+	}
+	return apiResp.Warnings, nil
+}
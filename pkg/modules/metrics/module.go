@@ -9,13 +9,17 @@ import (
 	"net"
 	"net/http"
 	"net/url"
+	"slices"
+	"sort"
 	"strconv"
 	"strings"
 	"time"
 
 	"github.com/mark3labs/mcp-go/mcp"
 	"github.com/mark3labs/mcp-go/server"
+	"github.com/shaowenchen/ops-mcp-server/pkg/config"
 	appMetrics "github.com/shaowenchen/ops-mcp-server/pkg/metrics"
+	"github.com/shaowenchen/ops-mcp-server/pkg/modules"
 	"go.uber.org/zap"
 )
 
@@ -25,26 +29,59 @@ type PrometheusConfig struct {
 	Username string `mapstructure:"username" json:"username" yaml:"username"`
 	Password string `mapstructure:"password" json:"password" yaml:"password"`
 	Token    string `mapstructure:"token" json:"token" yaml:"token"`
+	Timeout  int    `mapstructure:"timeout" json:"timeout" yaml:"timeout"`
+}
+
+// AlertmanagerConfig contains Alertmanager configuration
+type AlertmanagerConfig struct {
+	Endpoint string `mapstructure:"endpoint" json:"endpoint" yaml:"endpoint"`
+	Username string `mapstructure:"username" json:"username" yaml:"username"`
+	Password string `mapstructure:"password" json:"password" yaml:"password"`
+	Token    string `mapstructure:"token" json:"token" yaml:"token"`
+	Timeout  int    `mapstructure:"timeout" json:"timeout" yaml:"timeout"`
 }
 
 // ToolsConfig contains tools configuration
 type ToolsConfig struct {
 	Prefix string `mapstructure:"prefix" json:"prefix" yaml:"prefix"`
 	Suffix string `mapstructure:"suffix" json:"suffix" yaml:"suffix"`
+	// Overrides change individual tools, keyed by their default name
+	Overrides map[string]config.ToolOverride `mapstructure:"overrides" json:"overrides" yaml:"overrides"`
 }
 
 // Config contains metrics module configuration
 type Config struct {
-	// Prometheus configuration - required
+	// Prometheus is the unnamed Prometheus instance, served as datasource "default"
 	Prometheus *PrometheusConfig `mapstructure:"prometheus" json:"prometheus" yaml:"prometheus"`
-	Tools      ToolsConfig       `mapstructure:"tools" json:"tools" yaml:"tools"`
+	// Datasources are named Prometheus instances, e.g. one per region
+	Datasources map[string]*PrometheusConfig `mapstructure:"datasources" json:"datasources" yaml:"datasources"`
+	// DefaultDatasource is queried when a tool call names no datasource
+	DefaultDatasource string      `mapstructure:"default_datasource" json:"default_datasource" yaml:"default_datasource"`
+	Tools             ToolsConfig `mapstructure:"tools" json:"tools" yaml:"tools"`
+	// Alertmanager enables the alert group and silence tools
+	Alertmanager *AlertmanagerConfig `mapstructure:"alertmanager" json:"alertmanager" yaml:"alertmanager"`
+}
+
+// defaultDatasourceName is the datasource name of the unnamed Prometheus instance
+const defaultDatasourceName = "default"
+
+// datasource is a Prometheus instance with its HTTP client
+type datasource struct {
+	name       string
+	config     *PrometheusConfig
+	httpClient *http.Client
 }
 
 // Module represents the metrics module
 type Module struct {
-	config     *Config
-	logger     *zap.Logger
-	httpClient *http.Client
+	config            *Config
+	logger            *zap.Logger
+	datasources       map[string]*datasource
+	datasourceNames   []string
+	defaultDatasource string
+	tools             MetricsToolsConfig
+	completions       *modules.CompletionCache
+	alertmanager      *alertmanager
 }
 
 // New creates a new metrics module
@@ -53,43 +90,133 @@ func New(config *Config, logger *zap.Logger) (*Module, error) {
 		return nil, fmt.Errorf("metrics config is required")
 	}
 
-	// Create HTTP client - each request uses a new connection, closes after request
-	transport := &http.Transport{
-		DisableKeepAlives:     true, // Disable connection reuse - close after each request
-		DialContext: (&net.Dialer{
-			Timeout: 10 * time.Second,
-		}).DialContext,
-		TLSHandshakeTimeout:   5 * time.Second,
-		ResponseHeaderTimeout: 10 * time.Second,
+	m := &Module{
+		config:      config,
+		logger:      logger.Named("metrics"),
+		datasources: make(map[string]*datasource),
+		completions: modules.NewCompletionCache(modules.CompletionTTL),
 	}
 
-	m := &Module{
-		config: config,
-		logger: logger.Named("metrics"),
-		httpClient: &http.Client{
-			Transport: transport,
-			Timeout:   30 * time.Second, // Prometheus queries timeout
-		},
+	if config.Prometheus != nil && config.Prometheus.Endpoint != "" {
+		m.addDatasource(defaultDatasourceName, config.Prometheus)
+	}
+	for name, prometheus := range config.Datasources {
+		if prometheus == nil {
+			continue
+		}
+		if _, exists := m.datasources[name]; exists {
+			return nil, fmt.Errorf("metrics datasource %q conflicts with the unnamed prometheus instance", name)
+		}
+		m.addDatasource(name, prometheus)
+	}
+	sort.Strings(m.datasourceNames)
+	if config.Alertmanager != nil && config.Alertmanager.Endpoint != "" {
+		m.alertmanager = &alertmanager{config: config.Alertmanager, httpClient: newHTTPClient(config.Alertmanager.Timeout)}
+		m.logger.Info("Alertmanager configured",
+			zap.String("alertmanager_endpoint", config.Alertmanager.Endpoint),
+			zap.Duration("timeout", m.alertmanager.httpClient.Timeout),
+		)
+	}
+
+	// Viper lowercases map keys, so names are matched case-insensitively
+	defaultName := strings.ToLower(config.DefaultDatasource)
+	switch {
+	case defaultName != "":
+		if _, ok := m.datasources[defaultName]; !ok {
+			return nil, fmt.Errorf("metrics default datasource %q is not configured", config.DefaultDatasource)
+		}
+		m.defaultDatasource = defaultName
+	case m.datasources[defaultDatasourceName] != nil:
+		m.defaultDatasource = defaultDatasourceName
+	case len(m.datasourceNames) == 1:
+		m.defaultDatasource = m.datasourceNames[0]
 	}
 
-	if config.Prometheus != nil {
+	if len(m.datasources) > 0 {
 		m.logger.Info("Metrics module created with Prometheus",
-			zap.String("prometheus_endpoint", config.Prometheus.Endpoint),
+			zap.Strings("datasources", m.datasourceNames),
+			zap.String("default_datasource", m.defaultDatasource),
 		)
 	} else {
 		m.logger.Info("Metrics module created without Prometheus configuration")
 	}
 
+	m.tools = GetDefaultToolsConfig()
+	if err := modules.ApplyToolOverrides("metrics", m.tools.toolConfigs(), m.config.Tools.Overrides, m.BuildToolName, m.logger); err != nil {
+		return nil, err
+	}
+	if m.alertmanager == nil {
+		for _, tool := range m.tools.alertmanagerToolConfigs() {
+			tool.Enabled = false
+		}
+	}
+
 	return m, nil
 }
 
-// makePrometheusRequest creates and executes an HTTP request to Prometheus API
-func (m *Module) makePrometheusRequest(ctx context.Context, method, path string, body interface{}) (*http.Response, error) {
-	if m.config.Prometheus == nil {
+// addDatasource registers a Prometheus instance under name
+func (m *Module) addDatasource(name string, config *PrometheusConfig) {
+	m.datasources[name] = &datasource{
+		name:       name,
+		config:     config,
+		httpClient: newHTTPClient(config.Timeout),
+	}
+	m.datasourceNames = append(m.datasourceNames, name)
+
+	m.logger.Info("Prometheus datasource configured",
+		zap.String("datasource", name),
+		zap.String("prometheus_endpoint", config.Endpoint),
+		zap.Duration("timeout", m.datasources[name].httpClient.Timeout),
+	)
+}
+
+// newHTTPClient creates the HTTP client of a backend with a timeout in seconds
+func newHTTPClient(timeoutSeconds int) *http.Client {
+	// Set default timeout if not specified
+	timeout := 30 * time.Second
+	if timeoutSeconds > 0 {
+		timeout = time.Duration(timeoutSeconds) * time.Second
+	}
+
+	// Create HTTP client - each request uses a new connection, closes after request
+	transport := &http.Transport{
+		DisableKeepAlives:     true, // Disable connection reuse - close after each request
+		DialContext: (&net.Dialer{
+			Timeout: 10 * time.Second,
+		}).DialContext,
+		TLSHandshakeTimeout:   5 * time.Second,
+		ResponseHeaderTimeout: 10 * time.Second,
+	}
+
+	return &http.Client{
+		Transport: transport,
+		Timeout:   timeout, // Backend request timeout
+	}
+}
+
+// datasource returns the datasource named by the datasource argument of a
+// tool call, the default datasource when the argument is absent
+func (m *Module) datasource(args map[string]interface{}) (*datasource, error) {
+	if len(m.datasources) == 0 {
 		return nil, fmt.Errorf("Prometheus configuration is not available")
 	}
+	name, _ := args["datasource"].(string)
+	if name == "" {
+		name = m.defaultDatasource
+	}
+	if name == "" {
+		return nil, fmt.Errorf("datasource is required, available datasources: %s", strings.Join(m.datasourceNames, ", "))
+	}
+	ds, ok := m.datasources[strings.ToLower(name)]
+	if !ok {
+		return nil, fmt.Errorf("unknown datasource %q, available datasources: %s", name, strings.Join(m.datasourceNames, ", "))
+	}
+	return ds, nil
+}
 
-	url := m.config.Prometheus.Endpoint + path
+// makePrometheusRequest creates and executes an HTTP request to the Prometheus API of ds
+func (m *Module) makePrometheusRequest(ctx context.Context, ds *datasource, method, path string, body interface{}) (*http.Response, error) {
+	url := ds.config.Endpoint + path
 
 	var reqBody io.Reader
 	if body != nil {
@@ -105,7 +232,8 @@ func (m *Module) makePrometheusRequest(ctx context.Context, method, path string,
 		zap.String("method", method),
 		zap.String("full_url", url),
 		zap.String("path", path),
-		zap.String("endpoint", m.config.Prometheus.Endpoint),
+		zap.String("datasource", ds.name),
+		zap.String("endpoint", ds.config.Endpoint),
 		zap.Bool("has_body", body != nil))
 
 	req, err := http.NewRequestWithContext(ctx, method, url, reqBody)
@@ -118,17 +246,12 @@ func (m *Module) makePrometheusRequest(ctx context.Context, method, path string,
 	req.Header.Set("Accept", "application/json")
 
 	// Set authentication
-	authMethod := "none"
-	if m.config.Prometheus.Token != "" {
-		req.Header.Set("Authorization", "Bearer "+m.config.Prometheus.Token)
-		authMethod = "bearer_token"
-	} else if m.config.Prometheus.Username != "" && m.config.Prometheus.Password != "" {
-		req.SetBasicAuth(m.config.Prometheus.Username, m.config.Prometheus.Password)
-		authMethod = "basic_auth"
-	}
+	authMethod := setAuth(req, ds.config.Token, ds.config.Username, ds.config.Password)
 
 	start := time.Now()
-	resp, err := m.httpClient.Do(req)
+	waiting := modules.ProgressFromContext(ctx).Wait(fmt.Sprintf("Waiting for Prometheus %s", ds.name))
+	resp, err := ds.httpClient.Do(req)
+	waiting()
 	duration := time.Since(start)
 	
 	if err != nil {
@@ -137,20 +260,20 @@ func (m *Module) makePrometheusRequest(ctx context.Context, method, path string,
 			zap.String("url", url),
 			zap.Error(err))
 		// Record backend metrics
-		appMetrics.RecordBackendRequest(appMetrics.BackendPrometheus, duration, false)
+		appMetrics.RecordBackendRequest(appMetrics.BackendPrometheus, ds.name, duration, false)
 		if err.Error() != "" {
 			errorType := "network_error"
 			if strings.Contains(strings.ToLower(err.Error()), "timeout") {
 				errorType = "timeout"
 			}
-			appMetrics.RecordBackendError(appMetrics.BackendPrometheus, errorType)
+			appMetrics.RecordBackendError(appMetrics.BackendPrometheus, ds.name, errorType)
 		}
 		return nil, fmt.Errorf("failed to execute request: %w", err)
 	}
 	
 	// Record backend metrics
 	success := resp.StatusCode >= 200 && resp.StatusCode < 300
-	appMetrics.RecordBackendRequest(appMetrics.BackendPrometheus, duration, success)
+	appMetrics.RecordBackendRequest(appMetrics.BackendPrometheus, ds.name, duration, success)
 	if !success {
 		errorType := "http_error"
 		if resp.StatusCode == 401 || resp.StatusCode == 403 {
@@ -158,7 +281,7 @@ func (m *Module) makePrometheusRequest(ctx context.Context, method, path string,
 		} else if resp.StatusCode >= 500 {
 			errorType = "server_error"
 		}
-		appMetrics.RecordBackendError(appMetrics.BackendPrometheus, errorType)
+		appMetrics.RecordBackendError(appMetrics.BackendPrometheus, ds.name, errorType)
 	}
 
 	// Log response details
@@ -173,12 +296,22 @@ func (m *Module) makePrometheusRequest(ctx context.Context, method, path string,
 	return resp, nil
 }
 
-// queryPrometheus executes a Prometheus query directly
-func (m *Module) queryPrometheus(ctx context.Context, query string, queryType string, params map[string]string) (*PrometheusResponse, error) {
-	if m.config.Prometheus == nil {
-		return nil, fmt.Errorf("Prometheus configuration is not available")
+// setAuth authenticates req with the bearer token, else with basic auth, and
+// returns the method used
+func setAuth(req *http.Request, token, username, password string) string {
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+		return "bearer_token"
+	}
+	if username != "" && password != "" {
+		req.SetBasicAuth(username, password)
+		return "basic_auth"
 	}
+	return "none"
+}
 
+// queryPrometheus executes a Prometheus query against ds
+func (m *Module) queryPrometheus(ctx context.Context, ds *datasource, query string, queryType string, params map[string]string) (*PrometheusResponse, error) {
 	// Format: {endpoint}/api/v1/{queryType}
 	path := fmt.Sprintf("/api/v1/%s", queryType)
 
@@ -190,15 +323,16 @@ func (m *Module) queryPrometheus(ctx context.Context, query string, queryType st
 		queryParams.Set(key, value)
 	}
 
-	fullURL := m.config.Prometheus.Endpoint + path + "?" + queryParams.Encode()
+	fullURL := ds.config.Endpoint + path + "?" + queryParams.Encode()
 
 	m.logger.Info("Executing Prometheus Query",
+		zap.String("datasource", ds.name),
 		zap.String("url", fullURL),
 		zap.String("query", query),
 		zap.String("query_type", queryType),
 		zap.Any("params", params))
 
-	resp, err := m.makePrometheusRequest(ctx, "GET", path+"?"+queryParams.Encode(), nil)
+	resp, err := m.makePrometheusRequest(ctx, ds, "GET", path+"?"+queryParams.Encode(), nil)
 	if err != nil {
 		m.logger.Error("Prometheus query failed",
 			zap.String("query", query),
@@ -286,23 +420,73 @@ func (m *Module) queryPrometheus(ctx context.Context, query string, queryType st
 
 // GetTools returns all MCP tools for the metrics module
 func (m *Module) GetTools() []server.ServerTool {
-	// Get default tool configuration
-	toolsConfig := GetDefaultToolsConfig()
+	return m.BuildTools(m.tools)
+}
+
+// GetResources returns the metric names of the default datasource, exposed
+// while the list metrics tool is enabled
+func (m *Module) GetResources() []server.ServerResource {
+	if !m.tools.ListMetrics.Enabled {
+		return nil
+	}
+	return []server.ServerResource{
+		{
+			Resource: mcp.NewResource("ops://metrics/names", "Prometheus metric names",
+				mcp.WithResourceDescription("Names of all metrics of the default Prometheus datasource"),
+				mcp.WithMIMEType("application/json")),
+			Handler: m.handleReadMetricNames,
+		},
+	}
+}
 
-	// Tool configuration can be modified based on config file or other conditions
-	// For example: disable certain tools based on m.config
-	// toolsConfig.ListMetrics.Enabled = false
+// GetResourceTemplates returns the metric names of the series matching a
+// selector, exposed while the list metrics tool is enabled
+func (m *Module) GetResourceTemplates() []server.ServerResourceTemplate {
+	if !m.tools.ListMetrics.Enabled {
+		return nil
+	}
+	return []server.ServerResourceTemplate{
+		{
+			Template: mcp.NewResourceTemplate("ops://metrics/names{?match}", "Prometheus metric names by selector",
+				mcp.WithTemplateDescription("Names of the metrics of the default Prometheus datasource whose series match the URL-encoded selector match, such as {job=\"node\"}"),
+				mcp.WithTemplateMIMEType("application/json")),
+			Handler: m.handleReadMetricNames,
+		},
+	}
+}
 
-	return m.BuildTools(toolsConfig)
+// HealthCheck reports whether Prometheus is configured
+func (m *Module) HealthCheck() error {
+	if len(m.datasources) == 0 {
+		return fmt.Errorf("Prometheus configuration is not available")
+	}
+	return nil
 }
 
-func (m *Module) handleListMetrics(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
-	if m.config.Prometheus == nil {
-		return nil, fmt.Errorf("Prometheus configuration is not available")
+func (m *Module) handleListDatasources(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	datasources := make([]DatasourceInfo, 0, len(m.datasourceNames))
+	for _, name := range m.datasourceNames {
+		datasources = append(datasources, DatasourceInfo{
+			Name:    name,
+			Default: name == m.defaultDatasource,
+		})
 	}
 
+	return modules.StructuredResult(DatasourcesResponse{
+		Datasources:       datasources,
+		DefaultDatasource: m.defaultDatasource,
+		TotalCount:        len(datasources),
+	}, "")
+}
+
+func (m *Module) handleListMetrics(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
 	args := request.GetArguments()
 
+	ds, err := m.datasource(args)
+	if err != nil {
+		return nil, err
+	}
+
 	// Get search filter if provided
 	searchFilter := ""
 	if search, ok := args["search"].(string); ok {
@@ -317,56 +501,24 @@ func (m *Module) handleListMetrics(ctx context.Context, request mcp.CallToolRequ
 		}
 	}
 
+	match := ""
+	if value, ok := args["match"].(string); ok {
+		match = value
+	}
+
 	m.logger.Info("Listing available metrics",
 		zap.String("search_filter", searchFilter),
+		zap.String("match", match),
 		zap.Int("limit", limit))
 
-	// Query Prometheus metadata API to get all metrics
-	resp, err := m.makePrometheusRequest(ctx, "GET", "/api/v1/label/__name__/values", nil)
+	names, err := m.listMetricNames(ctx, ds, match)
 	if err != nil {
-		m.logger.Error("Failed to query metrics list", zap.Error(err))
-		return nil, fmt.Errorf("failed to query metrics list: %w", err)
-	}
-	defer func() {
-		if resp != nil && resp.Body != nil {
-			resp.Body.Close()
-		}
-	}()
-
-	if resp.StatusCode != http.StatusOK {
-		m.logger.Error("Prometheus API returned non-200 status",
-			zap.Int("status_code", resp.StatusCode))
-		return nil, fmt.Errorf("Prometheus API returned status %d", resp.StatusCode)
-	}
-
-	// Read and parse response
-	respBody, err := io.ReadAll(resp.Body)
-	if err != nil {
-		m.logger.Error("Failed to read response body", zap.Error(err))
-		return nil, fmt.Errorf("failed to read response body: %w", err)
-	}
-
-	var apiResp struct {
-		Status string   `json:"status"`
-		Data   []string `json:"data"`
-	}
-
-	if err := json.Unmarshal(respBody, &apiResp); err != nil {
-		m.logger.Error("Failed to decode response",
-			zap.Error(err),
-			zap.String("response_body", string(respBody)))
-		return nil, fmt.Errorf("failed to decode response: %w", err)
-	}
-
-	if apiResp.Status != "success" {
-		m.logger.Error("API request failed",
-			zap.String("status", apiResp.Status))
-		return nil, fmt.Errorf("API request failed with status: %s", apiResp.Status)
+		return nil, err
 	}
 
 	// Filter metrics if search pattern provided
 	filteredMetrics := make([]string, 0)
-	for _, metric := range apiResp.Data {
+	for _, metric := range names {
 		if searchFilter == "" || strings.Contains(metric, searchFilter) {
 			filteredMetrics = append(filteredMetrics, metric)
 		}
@@ -377,30 +529,73 @@ func (m *Module) handleListMetrics(ctx context.Context, request mcp.CallToolRequ
 		filteredMetrics = filteredMetrics[:limit]
 	}
 
-	result := map[string]interface{}{
-		"metrics":       filteredMetrics,
-		"total_count":   len(filteredMetrics),
-		"search_filter": searchFilter,
-		"limit":         limit,
-		"timestamp":     time.Now().Format(time.RFC3339),
-		"status":        "success",
+	result := ListMetricsResponse{
+		Datasource:   ds.name,
+		Metrics:      filteredMetrics,
+		TotalCount:   len(filteredMetrics),
+		SearchFilter: searchFilter,
+		Match:        match,
+		Limit:        limit,
+		Timestamp:    time.Now().Format(time.RFC3339),
+		Status:       "success",
+	}
+
+	m.logger.Info("Metrics list completed successfully",
+		zap.Int("returned_count", len(filteredMetrics)),
+		zap.Int("total_available", len(names)))
+
+	return modules.StructuredResult(result, "")
+}
+
+// listMetricNames returns the metric names known to ds, only those of the
+// series matching the match selector when it is set
+func (m *Module) listMetricNames(ctx context.Context, ds *datasource, match string) ([]string, error) {
+	return m.listLabelValues(ctx, ds, "__name__", match)
+}
+
+// listLabelValues returns the values of label known to ds, only those of the
+// series matching the match selector when it is set
+func (m *Module) listLabelValues(ctx context.Context, ds *datasource, label, match string) ([]string, error) {
+	params := url.Values{}
+	if match != "" {
+		params.Set("match[]", match)
+	}
+
+	var values []string
+	if _, err := m.prometheusAPI(ctx, ds, "/api/v1/label/"+url.PathEscape(label)+"/values", params, &values); err != nil {
+		m.logger.Error("Failed to query label values", zap.String("label", label), zap.Error(err))
+		return nil, fmt.Errorf("failed to query label values: %w", err)
 	}
+	return values, nil
+}
 
-	data, err := json.Marshal(result)
+// handleReadMetricNames serves the metric names of the default datasource as
+// a resource, all of them rather than the first page list-metrics returns
+func (m *Module) handleReadMetricNames(ctx context.Context, request mcp.ReadResourceRequest) ([]mcp.ResourceContents, error) {
+	ds, err := m.datasource(nil)
 	if err != nil {
-		return nil, fmt.Errorf("failed to marshal response: %w", err)
+		return nil, err
+	}
+	match := modules.ResourceArgument(request, "match")
+	names, err := m.listMetricNames(ctx, ds, match)
+	if err != nil {
+		return nil, err
 	}
 
-	m.logger.Info("Metrics list completed successfully",
-		zap.Int("returned_count", len(filteredMetrics)),
-		zap.Int("total_available", len(apiResp.Data)))
-
-	return &mcp.CallToolResult{
-		Content: []mcp.Content{
-			mcp.TextContent{
-				Type: "text",
-				Text: string(data),
-			},
+	data, err := json.Marshal(map[string]interface{}{
+		"datasource":  ds.name,
+		"match":       match,
+		"metrics":     names,
+		"total_count": len(names),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal response: %w", err)
+	}
+	return []mcp.ResourceContents{
+		mcp.TextResourceContents{
+			URI:      request.Params.URI,
+			MIMEType: "application/json",
+			Text:     string(data),
 		},
 	}, nil
 }
@@ -413,6 +608,11 @@ func (m *Module) handleExecuteQuery(ctx context.Context, request mcp.CallToolReq
 		return nil, fmt.Errorf("query parameter is required")
 	}
 
+	ds, err := m.datasource(args)
+	if err != nil {
+		return nil, err
+	}
+
 	m.logger.Info("Executing PromQL instant query",
 		zap.String("query", query))
 
@@ -420,7 +620,7 @@ func (m *Module) handleExecuteQuery(ctx context.Context, request mcp.CallToolReq
 	params := make(map[string]string)
 	params["time"] = fmt.Sprintf("%d", time.Now().Unix())
 
-	promResp, err := m.queryPrometheus(ctx, query, "query", params)
+	promResp, err := m.queryPrometheus(ctx, ds, query, "query", params)
 	if err != nil {
 		m.logger.Error("Failed to execute PromQL query",
 			zap.String("query", query),
@@ -435,29 +635,18 @@ func (m *Module) handleExecuteQuery(ctx context.Context, request mcp.CallToolReq
 		Error:    promResp.Error,
 		Warnings: promResp.Warnings,
 		Metadata: map[string]string{
+			"datasource": ds.name,
 			"query":     query,
 			"type":      "instant",
 			"timestamp": time.Now().Format(time.RFC3339),
 		},
 	}
 
-	data, err := json.Marshal(response)
-	if err != nil {
-		return nil, fmt.Errorf("failed to marshal response: %w", err)
-	}
-
 	m.logger.Info("PromQL instant query completed successfully",
 		zap.String("query", query),
 		zap.String("status", promResp.Status))
 
-	return &mcp.CallToolResult{
-		Content: []mcp.Content{
-			mcp.TextContent{
-				Type: "text",
-				Text: string(data),
-			},
-		},
-	}, nil
+	return modules.StructuredResult(response, "")
 }
 
 func (m *Module) handleExecuteRangeQuery(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
@@ -473,12 +662,33 @@ func (m *Module) handleExecuteRangeQuery(ctx context.Context, request mcp.CallTo
 		return nil, fmt.Errorf("time_range parameter is required")
 	}
 
+	ds, err := m.datasource(args)
+	if err != nil {
+		return nil, err
+	}
+
 	// Get step parameter or use default
 	step := "15s"
 	if stepArg, ok := args["step"].(string); ok && stepArg != "" {
 		step = stepArg
 	}
 
+	summarize, _ := args["summarize"].(bool)
+	sortBy, _ := args["sort_by"].(string)
+	if sortBy == "" {
+		sortBy = summaryStatistics[0]
+	} else if !slices.Contains(summaryStatistics, sortBy) {
+		return nil, fmt.Errorf("invalid sort_by '%s': must be one of %s", sortBy, strings.Join(summaryStatistics, ", "))
+	}
+	topK, err := positiveArgument(args, "top_k", defaultTopK)
+	if err != nil {
+		return nil, err
+	}
+	maxSamples, err := positiveArgument(args, "max_samples", defaultMaxSamples)
+	if err != nil {
+		return nil, err
+	}
+
 	m.logger.Info("Executing PromQL range query",
 		zap.String("query", query),
 		zap.String("time_range", timeRange),
@@ -502,7 +712,7 @@ func (m *Module) handleExecuteRangeQuery(ctx context.Context, request mcp.CallTo
 	params["end"] = fmt.Sprintf("%d", now.Unix())
 	params["step"] = step
 
-	promResp, err := m.queryPrometheus(ctx, query, "query_range", params)
+	promResp, err := m.queryPrometheus(ctx, ds, query, "query_range", params)
 	if err != nil {
 		m.logger.Error("Failed to execute PromQL range query",
 			zap.String("query", query),
@@ -518,6 +728,7 @@ func (m *Module) handleExecuteRangeQuery(ctx context.Context, request mcp.CallTo
 		Error:    promResp.Error,
 		Warnings: promResp.Warnings,
 		Metadata: map[string]string{
+			"datasource": ds.name,
 			"query":      query,
 			"type":       "range",
 			"time_range": timeRange,
@@ -527,24 +738,32 @@ func (m *Module) handleExecuteRangeQuery(ctx context.Context, request mcp.CallTo
 		},
 	}
 
-	data, err := json.Marshal(response)
-	if err != nil {
-		return nil, fmt.Errorf("failed to marshal response: %w", err)
+	samples := 0
+	for _, metric := range promResp.Data.Result {
+		samples += len(metric.Values)
+	}
+	if !summarize && samples > maxSamples {
+		// Returning every sample would flood the context of the caller
+		summarize = true
+		response.Warnings = append(response.Warnings, fmt.Sprintf(
+			"the result has %d samples, more than max_samples %d, so it is summarized: raise step, narrow the query or raise max_samples to get every sample",
+			samples, maxSamples))
 	}
 
 	m.logger.Info("PromQL range query completed successfully",
 		zap.String("query", query),
 		zap.String("time_range", timeRange),
-		zap.String("status", promResp.Status))
+		zap.String("status", promResp.Status),
+		zap.Int("samples", samples),
+		zap.Bool("summarized", summarize))
 
-	return &mcp.CallToolResult{
-		Content: []mcp.Content{
-			mcp.TextContent{
-				Type: "text",
-				Text: string(data),
-			},
-		},
-	}, nil
+	if summarize {
+		response.Summary = summarizeRange(promResp.Data.Result, sortBy, topK)
+		response.Data.Result = []PrometheusMetric{}
+		response.Metadata["summarized"] = "true"
+		return modules.StructuredResult(response, formatRangeSummary(response.Summary, response.Metadata, response.Warnings))
+	}
+	return modules.StructuredResult(response, "")
 }
 
 // parseTimeRange parses a time range string supporting s, m, h, d units
@@ -0,0 +1,62 @@
+package metrics
+
+import (
+	"github.com/shaowenchen/ops-mcp-server/pkg/config"
+	"github.com/shaowenchen/ops-mcp-server/pkg/modules"
+	"go.uber.org/zap"
+)
+
+func init() {
+	modules.Register(modules.Factory{
+		Name:    "metrics",
+		Section: "metrics",
+		Enabled: func(cfg *config.Config) *bool { return &cfg.Metrics.Enabled },
+		New: func(cfg *config.Config, logger *zap.Logger) (modules.Module, error) {
+			return New(ConfigFrom(cfg), logger)
+		},
+	})
+}
+
+// ConfigFrom builds the metrics module configuration from the server configuration
+func ConfigFrom(cfg *config.Config) *Config {
+	metricsConfig := &Config{
+		Tools: ToolsConfig{
+			Prefix:    cfg.Metrics.Tools.Prefix,
+			Suffix:    cfg.Metrics.Tools.Suffix,
+			Overrides: cfg.Metrics.Tools.Overrides,
+		},
+		DefaultDatasource: cfg.Metrics.DefaultDatasource,
+	}
+	if cfg.Metrics.Prometheus != nil {
+		metricsConfig.Prometheus = prometheusConfigFrom(cfg.Metrics.Prometheus)
+	}
+	if len(cfg.Metrics.Datasources) > 0 {
+		metricsConfig.Datasources = make(map[string]*PrometheusConfig, len(cfg.Metrics.Datasources))
+		for name, prometheus := range cfg.Metrics.Datasources {
+			if prometheus != nil {
+				metricsConfig.Datasources[name] = prometheusConfigFrom(prometheus)
+			}
+		}
+	}
+	if cfg.Metrics.Alertmanager != nil {
+		metricsConfig.Alertmanager = &AlertmanagerConfig{
+			Endpoint: cfg.Metrics.Alertmanager.Endpoint,
+			Username: cfg.Metrics.Alertmanager.Username,
+			Password: cfg.Metrics.Alertmanager.Password,
+			Token:    cfg.Metrics.Alertmanager.Token,
+			Timeout:  cfg.Metrics.Alertmanager.Timeout,
+		}
+	}
+	return metricsConfig
+}
+
+// prometheusConfigFrom copies a Prometheus instance of the server configuration
+func prometheusConfigFrom(prometheus *config.PrometheusConfig) *PrometheusConfig {
+	return &PrometheusConfig{
+		Endpoint: prometheus.Endpoint,
+		Username: prometheus.Username,
+		Password: prometheus.Password,
+		Token:    prometheus.Token,
+		Timeout:  prometheus.Timeout,
+	}
+}
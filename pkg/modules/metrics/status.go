@@ -0,0 +1,375 @@
+package metrics
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/shaowenchen/ops-mcp-server/pkg/modules"
+)
+
+// defaultTSDBLimit is how many entries of each cardinality ranking are
+// returned when the limit argument is not set
+const defaultTSDBLimit = 10
+
+func (m *Module) handleListTargets(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	args := request.GetArguments()
+
+	ds, err := m.datasource(args)
+	if err != nil {
+		return nil, err
+	}
+	health, _ := args["health"].(string)
+	health = strings.ToLower(health)
+	switch health {
+	case "":
+		health = "unhealthy"
+	case "up", "down", "unknown", "unhealthy", "all":
+	default:
+		return nil, fmt.Errorf("invalid health '%s': must be up, down, unknown, unhealthy or all", health)
+	}
+	job, _ := args["job"].(string)
+	limit, err := parseLimit(args)
+	if err != nil {
+		return nil, err
+	}
+
+	var data struct {
+		ActiveTargets []PrometheusTarget `json:"activeTargets"`
+	}
+	if _, err := m.prometheusAPI(ctx, ds, "/api/v1/targets", url.Values{"state": {"active"}}, &data); err != nil {
+		return nil, fmt.Errorf("failed to list targets: %w", err)
+	}
+
+	response := TargetsResponse{
+		Datasource: ds.name,
+		Job:        job,
+		Health:     health,
+		Pools:      []ScrapePool{},
+		Targets:    []Target{},
+		Limit:      limit,
+	}
+	pools := make(map[string]*ScrapePool)
+	for _, promTarget := range data.ActiveTargets {
+		target := newTarget(promTarget)
+		if job != "" && target.Job != job && target.Pool != job {
+			continue
+		}
+
+		pool, ok := pools[target.Pool]
+		if !ok {
+			pool = &ScrapePool{
+				Name:           target.Pool,
+				ScrapeInterval: promTarget.ScrapeInterval,
+				ScrapeTimeout:  promTarget.ScrapeTimeout,
+			}
+			pools[target.Pool] = pool
+		}
+		switch target.Health {
+		case "up":
+			pool.Up++
+		case "down":
+			pool.Down++
+		default:
+			pool.Unknown++
+		}
+		// Summed here, divided once every target is counted
+		pool.AvgScrapeDuration += target.LastScrapeDuration
+		pool.MaxScrapeDuration = max(pool.MaxScrapeDuration, target.LastScrapeDuration)
+
+		if health == "all" || health == target.Health || (health == "unhealthy" && target.Health != "up") {
+			response.Targets = append(response.Targets, target)
+		}
+	}
+	for _, pool := range pools {
+		avg := pool.AvgScrapeDuration / float64(pool.Up+pool.Down+pool.Unknown)
+		pool.AvgScrapeDuration = math.Round(avg*1000) / 1000
+		response.Pools = append(response.Pools, *pool)
+	}
+	sort.Slice(response.Pools, func(i, j int) bool { return response.Pools[i].Name < response.Pools[j].Name })
+	sort.SliceStable(response.Targets, func(i, j int) bool {
+		a, b := response.Targets[i], response.Targets[j]
+		if a.Pool != b.Pool {
+			return a.Pool < b.Pool
+		}
+		return a.Instance < b.Instance
+	})
+	response.TotalCount = len(response.Targets)
+	response.Targets, response.Truncated = truncate(response.Targets, limit)
+
+	return modules.StructuredResult(response, formatTargets(response))
+}
+
+func (m *Module) handleGetTSDBStatus(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	args := request.GetArguments()
+
+	ds, err := m.datasource(args)
+	if err != nil {
+		return nil, err
+	}
+	limit, err := positiveArgument(args, "limit", defaultTSDBLimit)
+	if err != nil {
+		return nil, err
+	}
+
+	var status PrometheusTSDBStatus
+	params := url.Values{"limit": {strconv.Itoa(limit)}}
+	if _, err := m.prometheusAPI(ctx, ds, "/api/v1/status/tsdb", params, &status); err != nil {
+		return nil, fmt.Errorf("failed to get TSDB status: %w", err)
+	}
+
+	head := status.HeadStats
+	response := TSDBStatusResponse{
+		Datasource: ds.name,
+		HeadStats: HeadStats{
+			Series:     head.NumSeries,
+			LabelPairs: head.NumLabelPairs,
+			Chunks:     head.ChunkCount,
+		},
+		Limit: limit,
+	}
+	// An empty head reports math.MaxInt64 and math.MinInt64 as its bounds
+	if head.NumSeries > 0 {
+		response.HeadStats.MinTime = time.UnixMilli(head.MinTime).UTC().Format(time.RFC3339)
+		response.HeadStats.MaxTime = time.UnixMilli(head.MaxTime).UTC().Format(time.RFC3339)
+	}
+	// Prometheus versions before the limit parameter return their top 10
+	response.SeriesCountByMetricName, _ = truncate(orEmpty(status.SeriesCountByMetricName), limit)
+	response.LabelValueCountByLabelName, _ = truncate(orEmpty(status.LabelValueCountByLabelName), limit)
+	response.MemoryInBytesByLabelName, _ = truncate(orEmpty(status.MemoryInBytesByLabelName), limit)
+	response.SeriesCountByLabelValuePair, _ = truncate(orEmpty(status.SeriesCountByLabelValuePair), limit)
+
+	return modules.StructuredResult(response, formatTSDBStatus(response))
+}
+
+func (m *Module) handleGetRuntimeInfo(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	args := request.GetArguments()
+
+	ds, err := m.datasource(args)
+	if err != nil {
+		return nil, err
+	}
+
+	// Prometheus-compatible backends often implement only one of the two
+	response := RuntimeInfoResponse{Datasource: ds.name}
+	var build PrometheusBuildInfo
+	_, buildErr := m.prometheusAPI(ctx, ds, "/api/v1/status/buildinfo", nil, &build)
+	if buildErr == nil {
+		response.Build = &BuildInfo{
+			Version:   build.Version,
+			Revision:  build.Revision,
+			Branch:    build.Branch,
+			BuildDate: build.BuildDate,
+			GoVersion: build.GoVersion,
+		}
+	} else {
+		response.Warnings = append(response.Warnings, fmt.Sprintf("build information unavailable: %v", buildErr))
+	}
+	var runtime PrometheusRuntimeInfo
+	_, runtimeErr := m.prometheusAPI(ctx, ds, "/api/v1/status/runtimeinfo", nil, &runtime)
+	if runtimeErr == nil {
+		response.Runtime = &RuntimeInfo{
+			StartTime:           runtime.StartTime,
+			ReloadConfigSuccess: runtime.ReloadConfigSuccess,
+			LastConfigTime:      runtime.LastConfigTime,
+			CorruptionCount:     runtime.CorruptionCount,
+			GoroutineCount:      runtime.GoroutineCount,
+			GOMAXPROCS:          runtime.GOMAXPROCS,
+			GOMEMLIMIT:          runtime.GOMEMLIMIT,
+			GOGC:                runtime.GOGC,
+			StorageRetention:    runtime.StorageRetention,
+		}
+	} else {
+		response.Warnings = append(response.Warnings, fmt.Sprintf("runtime information unavailable: %v", runtimeErr))
+	}
+	if buildErr != nil && runtimeErr != nil {
+		return nil, fmt.Errorf("failed to get runtime information: %w", runtimeErr)
+	}
+
+	return modules.StructuredResult(response, formatRuntimeInfo(response, time.Now()))
+}
+
+// newTarget lifts the job and instance labels out of a scrape target
+func newTarget(promTarget PrometheusTarget) Target {
+	labels := make(map[string]string, len(promTarget.Labels))
+	for name, value := range promTarget.Labels {
+		if name != "job" && name != "instance" {
+			labels[name] = value
+		}
+	}
+	pool := promTarget.ScrapePool
+	if pool == "" {
+		// Prometheus versions before 2.27 have no scrape pools
+		pool = promTarget.Labels["job"]
+	}
+	return Target{
+		Pool:               pool,
+		Job:                promTarget.Labels["job"],
+		Instance:           promTarget.Labels["instance"],
+		Health:             promTarget.Health,
+		ScrapeURL:          promTarget.ScrapeURL,
+		Labels:             labels,
+		LastError:          promTarget.LastError,
+		LastScrape:         promTarget.LastScrape,
+		LastScrapeDuration: promTarget.LastScrapeDuration,
+	}
+}
+
+// orEmpty returns items, an empty slice when it is nil
+func orEmpty[T any](items []T) []T {
+	if items == nil {
+		return []T{}
+	}
+	return items
+}
+
+// formatTargets renders a line per scrape pool followed by the matching
+// targets for the text content
+func formatTargets(response TargetsResponse) string {
+	var up, down, unknown int
+	for _, pool := range response.Pools {
+		up, down, unknown = up+pool.Up, down+pool.Down, unknown+pool.Unknown
+	}
+	var b strings.Builder
+	fmt.Fprintf(&b, "%d targets in %d scrape pools: %d up, %d down, %d unknown", up+down+unknown, len(response.Pools), up, down, unknown)
+	for _, pool := range response.Pools {
+		fmt.Fprintf(&b, "\n%s: %d up, %d down, %d unknown, scrape %s avg %s max",
+			pool.Name, pool.Up, pool.Down, pool.Unknown, formatSeconds(pool.AvgScrapeDuration), formatSeconds(pool.MaxScrapeDuration))
+		if pool.ScrapeInterval != "" {
+			fmt.Fprintf(&b, " (interval %s, timeout %s)", pool.ScrapeInterval, pool.ScrapeTimeout)
+		}
+	}
+	if len(response.Targets) == 0 {
+		if response.Health != "all" {
+			fmt.Fprintf(&b, "\nNo %s targets", response.Health)
+		}
+		return b.String()
+	}
+	b.WriteString("\n")
+	for _, target := range response.Targets {
+		fmt.Fprintf(&b, "\n%s %s %s", target.Health, target.Pool, target.Instance)
+		if len(target.Labels) > 0 {
+			b.WriteString(" " + formatLabels(target.Labels))
+		}
+		if lastScrape, err := time.Parse(time.RFC3339Nano, target.LastScrape); err == nil && !lastScrape.IsZero() {
+			fmt.Fprintf(&b, " last scrape %s (took %s)", lastScrape.UTC().Format(time.RFC3339), formatSeconds(target.LastScrapeDuration))
+		}
+		if target.LastError != "" {
+			b.WriteString(": " + oneLine(target.LastError))
+		}
+	}
+	if response.Truncated {
+		fmt.Fprintf(&b, "\n... %d more targets, raise limit to see them", response.TotalCount-len(response.Targets))
+	}
+	return b.String()
+}
+
+// formatTSDBStatus renders the head stats and the cardinality rankings for
+// the text content
+func formatTSDBStatus(response TSDBStatusResponse) string {
+	head := response.HeadStats
+	var b strings.Builder
+	fmt.Fprintf(&b, "Head: %d series, %d label pairs, %d chunks", head.Series, head.LabelPairs, head.Chunks)
+	if head.MinTime != "" {
+		fmt.Fprintf(&b, ", %s to %s", head.MinTime, head.MaxTime)
+	}
+	share := func(value int64) string {
+		if head.Series == 0 {
+			return ""
+		}
+		return fmt.Sprintf(" (%.1f%%)", float64(value)*100/float64(head.Series))
+	}
+	rankings := []struct {
+		title  string
+		items  []NameCount
+		format func(int64) string
+	}{
+		{"Metrics by series", response.SeriesCountByMetricName, func(v int64) string { return strconv.FormatInt(v, 10) + share(v) }},
+		{"Labels by value count", response.LabelValueCountByLabelName, func(v int64) string { return strconv.FormatInt(v, 10) }},
+		{"Labels by memory", response.MemoryInBytesByLabelName, formatBytes},
+		{"Label pairs by series", response.SeriesCountByLabelValuePair, func(v int64) string { return strconv.FormatInt(v, 10) + share(v) }},
+	}
+	for _, ranking := range rankings {
+		if len(ranking.items) == 0 {
+			continue
+		}
+		fmt.Fprintf(&b, "\n\n%s:", ranking.title)
+		for _, item := range ranking.items {
+			fmt.Fprintf(&b, "\n  %s %s", item.Name, ranking.format(item.Value))
+		}
+	}
+	return b.String()
+}
+
+// formatRuntimeInfo renders the build and runtime information for the text
+// content
+func formatRuntimeInfo(response RuntimeInfoResponse, now time.Time) string {
+	var lines []string
+	if build := response.Build; build != nil {
+		line := "Version " + build.Version
+		var details []string
+		if build.Revision != "" {
+			details = append(details, "revision "+build.Revision)
+		}
+		if build.BuildDate != "" {
+			details = append(details, "built "+build.BuildDate)
+		}
+		if build.GoVersion != "" {
+			details = append(details, build.GoVersion)
+		}
+		if len(details) > 0 {
+			line += " (" + strings.Join(details, ", ") + ")"
+		}
+		lines = append(lines, line)
+	}
+	if runtime := response.Runtime; runtime != nil {
+		if startTime, err := time.Parse(time.RFC3339Nano, runtime.StartTime); err == nil {
+			lines = append(lines, fmt.Sprintf("Started %s (up %s)", startTime.UTC().Format(time.RFC3339), now.Sub(startTime).Round(time.Second)))
+		}
+		reload := "successful"
+		if !runtime.ReloadConfigSuccess {
+			reload = "FAILED"
+		}
+		line := "Last config reload " + reload
+		if runtime.LastConfigTime != "" {
+			line += " at " + runtime.LastConfigTime
+		}
+		lines = append(lines, line)
+		if runtime.StorageRetention != "" {
+			lines = append(lines, "Storage retention "+runtime.StorageRetention)
+		}
+		if runtime.CorruptionCount > 0 {
+			lines = append(lines, fmt.Sprintf("WAL corruptions %d", runtime.CorruptionCount))
+		}
+		line = fmt.Sprintf("Goroutines %d, GOMAXPROCS %d", runtime.GoroutineCount, runtime.GOMAXPROCS)
+		if runtime.GOGC != "" {
+			line += ", GOGC " + runtime.GOGC
+		}
+		if runtime.GOMEMLIMIT > 0 && runtime.GOMEMLIMIT < 1<<62 {
+			line += ", GOMEMLIMIT " + formatBytes(runtime.GOMEMLIMIT)
+		}
+		lines = append(lines, line)
+	}
+	lines = append(lines, response.Warnings...)
+	return strings.Join(lines, "\n")
+}
+
+// formatBytes renders a size in bytes with a binary unit
+func formatBytes(bytes int64) string {
+	const unit = 1024
+	if bytes < unit {
+		return fmt.Sprintf("%dB", bytes)
+	}
+	value, exp := float64(bytes)/unit, 0
+	for value >= unit && exp < 4 {
+		value /= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f%ciB", value, "KMGTP"[exp])
+}
@@ -0,0 +1,322 @@
+package metrics
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// defaultTopK is how many series a summary keeps when top_k is not set
+const defaultTopK = 20
+
+// defaultMaxSamples is how many samples a range query returns in full
+// before it is summarized, when max_samples is not set
+const defaultMaxSamples = 2000
+
+// maxEvents is how many step changes and spikes are reported per series
+const maxEvents = 5
+
+// summaryStatistics are the statistics series can be ranked by
+var summaryStatistics = []string{"max", "avg", "min", "p95", "last", "slope"}
+
+// sample is a finite sample of a series
+type sample struct {
+	t float64
+	v float64
+}
+
+// summarizeRange summarizes the series of a range query result, keeping the
+// topK with the highest sortBy statistic. Series without a single finite
+// sample are only counted.
+func summarizeRange(result []PrometheusMetric, sortBy string, topK int) *RangeSummary {
+	summary := &RangeSummary{
+		SeriesCount: len(result),
+		SortBy:      sortBy,
+		TopK:        topK,
+		Series:      []SeriesSummary{},
+	}
+	for _, metric := range result {
+		summary.SampleCount += len(metric.Values)
+		if series, ok := summarizeSeries(metric); ok {
+			summary.Series = append(summary.Series, series)
+		} else {
+			summary.EmptySeries++
+		}
+	}
+	sort.SliceStable(summary.Series, func(i, j int) bool {
+		return statistic(summary.Series[i], sortBy) > statistic(summary.Series[j], sortBy)
+	})
+	summary.Series, summary.Truncated = truncate(summary.Series, topK)
+	return summary
+}
+
+// summarizeSeries computes the statistics of a series, false when it has no
+// finite sample
+func summarizeSeries(metric PrometheusMetric) (SeriesSummary, bool) {
+	samples := make([]sample, 0, len(metric.Values))
+	for _, value := range metric.Values {
+		v, err := strconv.ParseFloat(value.Value, 64)
+		if err != nil || math.IsNaN(v) || math.IsInf(v, 0) {
+			continue
+		}
+		samples = append(samples, sample{t: value.Timestamp, v: v})
+	}
+	if len(samples) == 0 {
+		return SeriesSummary{}, false
+	}
+
+	series := SeriesSummary{
+		Metric:  metric.Labels,
+		Samples: len(samples),
+		MinAt:   sampleTime(samples[0].t),
+		MaxAt:   sampleTime(samples[0].t),
+	}
+	values := make([]float64, len(samples))
+	low, high := samples[0].v, samples[0].v
+	var sum float64
+	for i, s := range samples {
+		values[i] = s.v
+		sum += s.v
+		if s.v < low {
+			low, series.MinAt = s.v, sampleTime(s.t)
+		}
+		if s.v > high {
+			high, series.MaxAt = s.v, sampleTime(s.t)
+		}
+	}
+	last := samples[len(samples)-1]
+	series.Min, series.Max = roundSignificant(low), roundSignificant(high)
+	series.Last, series.LastAt = roundSignificant(last.v), sampleTime(last.t)
+	series.Avg = roundSignificant(sum / float64(len(samples)))
+	series.P95 = roundSignificant(percentile(values, 0.95))
+	series.Slope = roundSignificant(slope(samples))
+	series.StepChanges, series.Spikes = detectEvents(samples, high-low)
+	return series, true
+}
+
+// statistic returns the statistic of series named name
+func statistic(series SeriesSummary, name string) float64 {
+	switch name {
+	case "avg":
+		return series.Avg
+	case "min":
+		return series.Min
+	case "p95":
+		return series.P95
+	case "last":
+		return series.Last
+	case "slope":
+		return series.Slope
+	default:
+		return series.Max
+	}
+}
+
+// percentile returns the nearest-rank percentile p of values
+func percentile(values []float64, p float64) float64 {
+	sorted := append([]float64(nil), values...)
+	sort.Float64s(sorted)
+	rank := int(math.Ceil(p*float64(len(sorted)))) - 1
+	return sorted[max(rank, 0)]
+}
+
+// median returns the median of values, which must not be empty
+func median(values []float64) float64 {
+	sorted := append([]float64(nil), values...)
+	sort.Float64s(sorted)
+	n := len(sorted)
+	if n%2 == 1 {
+		return sorted[n/2]
+	}
+	return (sorted[n/2-1] + sorted[n/2]) / 2
+}
+
+// slope returns the least squares slope of samples in units per second
+func slope(samples []sample) float64 {
+	if len(samples) < 2 {
+		return 0
+	}
+	// Timestamps are shifted to the first sample to keep precision
+	var sumT, sumV float64
+	for _, s := range samples {
+		sumT += s.t - samples[0].t
+		sumV += s.v
+	}
+	n := float64(len(samples))
+	meanT, meanV := sumT/n, sumV/n
+	var cov, variance float64
+	for _, s := range samples {
+		dt := s.t - samples[0].t - meanT
+		cov += dt * (s.v - meanV)
+		variance += dt * dt
+	}
+	if variance == 0 {
+		return 0
+	}
+	return cov / variance
+}
+
+// detectEvents finds the step changes and spikes of samples, at most
+// maxEvents of each, the largest ones in time order.
+//
+// The level before and after each sample is the median of a window of
+// samples on each side, so isolated outliers do not move it. A step change
+// is where these levels differ by more than the threshold, once the usual
+// change of a trending series is discounted. A spike is a sample away from
+// both levels, in the same direction, by more than the threshold. The
+// threshold is six times the noise, estimated from the median absolute
+// deviation of the differences between samples, and at least a tenth of
+// the range of the series.
+func detectEvents(samples []sample, valueRange float64) ([]StepChange, []Spike) {
+	n := len(samples)
+	window := min(max(n/10, 5), 30)
+	if n < 2*window || valueRange <= 0 {
+		return nil, nil
+	}
+
+	values := make([]float64, n)
+	for i, s := range samples {
+		values[i] = s.v
+	}
+	diffs := make([]float64, n-1)
+	for i := range diffs {
+		diffs[i] = values[i+1] - values[i]
+	}
+	trend := median(diffs)
+	deviations := make([]float64, len(diffs))
+	for i, d := range diffs {
+		deviations[i] = math.Abs(d - trend)
+	}
+	noise := 1.4826 * median(deviations) / math.Sqrt2
+	threshold := max(6*noise, valueRange/10)
+
+	type candidate struct {
+		index         int
+		shift         float64
+		before, after float64
+	}
+	var steps []candidate
+	var spikes []Spike
+	var spikeSizes []float64
+	for i := 2; i < n-2; i++ {
+		before := median(values[max(i-window, 0):i])
+		after := median(values[i+1 : min(i+1+window, n)])
+		v := values[i]
+		if (v-before > threshold && v-after > threshold) || (before-v > threshold && after-v > threshold) {
+			spikes = append(spikes, Spike{Time: sampleTime(samples[i].t), Value: roundSignificant(v), Baseline: roundSignificant((before + after) / 2)})
+			spikeSizes = append(spikeSizes, min(math.Abs(v-before), math.Abs(v-after)))
+		}
+
+		if i < window || i+window > n {
+			continue
+		}
+		levelBefore := median(values[i-window : i])
+		levelAfter := median(values[i : i+window])
+		shift := levelAfter - levelBefore - float64(window)*trend
+		if math.Abs(shift) > threshold {
+			steps = append(steps, candidate{index: i, shift: shift, before: levelBefore, after: levelAfter})
+		}
+	}
+
+	// Consecutive candidates shifting the same way are one step change. The
+	// windows see the whole shift on a plateau centered on the change, so it
+	// is placed in the middle of the candidates near the largest shift.
+	var changes []StepChange
+	var changeSizes []float64
+	for start := 0; start < len(steps); {
+		end := start + 1
+		for end < len(steps) && steps[end].index == steps[end-1].index+1 && (steps[end].shift > 0) == (steps[start].shift > 0) {
+			end++
+		}
+		largest := 0.0
+		for _, step := range steps[start:end] {
+			largest = max(largest, math.Abs(step.shift))
+		}
+		var tied []candidate
+		for _, step := range steps[start:end] {
+			if math.Abs(step.shift) >= largest*0.9 {
+				tied = append(tied, step)
+			}
+		}
+		step := tied[len(tied)/2]
+		changes = append(changes, StepChange{
+			Time:   sampleTime(samples[step.index].t),
+			Before: roundSignificant(step.before),
+			After:  roundSignificant(step.after),
+		})
+		changeSizes = append(changeSizes, largest)
+		start = end
+	}
+	return keepLargest(changes, changeSizes), keepLargest(spikes, spikeSizes)
+}
+
+// keepLargest keeps the maxEvents events of the largest sizes, in time order
+func keepLargest[T any](events []T, sizes []float64) []T {
+	if len(events) <= maxEvents {
+		return events
+	}
+	indexes := make([]int, len(events))
+	for i := range indexes {
+		indexes[i] = i
+	}
+	sort.SliceStable(indexes, func(i, j int) bool { return sizes[indexes[i]] > sizes[indexes[j]] })
+	indexes = indexes[:maxEvents]
+	sort.Ints(indexes)
+	kept := make([]T, 0, maxEvents)
+	for _, i := range indexes {
+		kept = append(kept, events[i])
+	}
+	return kept
+}
+
+// sampleTime renders a sample timestamp in RFC 3339
+func sampleTime(timestamp float64) string {
+	return time.UnixMilli(int64(math.Round(timestamp * 1000))).UTC().Format(time.RFC3339)
+}
+
+// roundSignificant rounds v to 6 significant digits, dropping the noise of
+// float arithmetic and keeping the structured summary compact
+func roundSignificant(v float64) float64 {
+	rounded, err := strconv.ParseFloat(strconv.FormatFloat(v, 'g', 6, 64), 64)
+	if err != nil {
+		return v
+	}
+	return rounded
+}
+
+// formatRangeSummary renders a summary one series per line followed by its
+// step changes and spikes for the text content
+func formatRangeSummary(summary *RangeSummary, metadata map[string]string, warnings []string) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "%d series, %d samples from %s to %s at step %s, top %d by %s",
+		summary.SeriesCount, summary.SampleCount, metadata["start_time"], metadata["end_time"], metadata["step"], len(summary.Series), summary.SortBy)
+	for _, warning := range warnings {
+		b.WriteString("\nwarning: " + warning)
+	}
+	for _, series := range summary.Series {
+		fmt.Fprintf(&b, "\n%s min %s max %s avg %s p95 %s last %s slope %s/s",
+			formatLabels(series.Metric), formatValue(series.Min), formatValue(series.Max), formatValue(series.Avg),
+			formatValue(series.P95), formatValue(series.Last), formatValue(series.Slope))
+		for _, change := range series.StepChanges {
+			fmt.Fprintf(&b, "\n  step change at %s: %s -> %s", change.Time, formatValue(change.Before), formatValue(change.After))
+		}
+		for _, spike := range series.Spikes {
+			fmt.Fprintf(&b, "\n  spike at %s: %s (baseline %s)", spike.Time, formatValue(spike.Value), formatValue(spike.Baseline))
+		}
+	}
+	if summary.Truncated {
+		fmt.Fprintf(&b, "\n... %d more series, raise top_k to see them", summary.SeriesCount-summary.EmptySeries-len(summary.Series))
+	}
+	if summary.EmptySeries > 0 {
+		fmt.Fprintf(&b, "\n%d series without finite samples left out", summary.EmptySeries)
+	}
+	return b.String()
+}
+
+// formatValue renders a sample value with 4 significant digits
+func formatValue(v float64) string {
+	return strconv.FormatFloat(v, 'g', 4, 64)
+}
@@ -0,0 +1,55 @@
+package metrics
+
+import (
+	"strings"
+	"testing"
+)
+
+// constantSeries returns a series of pod with n samples of value
+func constantSeries(pod string, n int, value string) PrometheusMetric {
+	metric := PrometheusMetric{Labels: map[string]string{"pod": pod}}
+	for i := 0; i < n; i++ {
+		metric.Values = append(metric.Values, PrometheusValue{Timestamp: float64(1700000000 + 60*i), Value: value})
+	}
+	return metric
+}
+
+func TestSummarizeRangeCountsEmptySeriesApart(t *testing.T) {
+	result := []PrometheusMetric{
+		constantSeries("api-1", 3, "30"),
+		constantSeries("api-2", 3, "20"),
+		constantSeries("api-3", 3, "10"),
+		constantSeries("idle-1", 3, "NaN"),
+		constantSeries("idle-2", 3, "+Inf"),
+	}
+
+	summary := summarizeRange(result, "max", 2)
+	if summary.SeriesCount != 5 || summary.EmptySeries != 2 || !summary.Truncated {
+		t.Fatalf("%d series, %d empty, truncated %v, want 5, 2 and true", summary.SeriesCount, summary.EmptySeries, summary.Truncated)
+	}
+	if len(summary.Series) != 2 || summary.Series[0].Metric["pod"] != "api-1" || summary.Series[1].Metric["pod"] != "api-2" {
+		t.Errorf("summary keeps %v, want api-1 and api-2", summary.Series)
+	}
+
+	text := formatRangeSummary(summary, map[string]string{}, nil)
+	for _, want := range []string{"... 1 more series, raise top_k", "2 series without finite samples left out"} {
+		if !strings.Contains(text, want) {
+			t.Errorf("summary text %q does not report %q", text, want)
+		}
+	}
+}
+
+func TestSummarizeRangeNotTruncatedByEmptySeries(t *testing.T) {
+	result := []PrometheusMetric{
+		constantSeries("api-1", 3, "30"),
+		constantSeries("idle-1", 3, "NaN"),
+	}
+
+	summary := summarizeRange(result, "max", 1)
+	if summary.Truncated {
+		t.Error("summary truncated by a series without finite samples")
+	}
+	if text := formatRangeSummary(summary, map[string]string{}, nil); strings.Contains(text, "more series") {
+		t.Errorf("summary text %q reports series cut by top_k", text)
+	}
+}
@@ -1,23 +1,39 @@
 package metrics
 
 import (
+	"fmt"
+	"reflect"
+
+	"github.com/google/jsonschema-go/jsonschema"
 	"github.com/mark3labs/mcp-go/mcp"
 	"github.com/mark3labs/mcp-go/server"
 	appMetrics "github.com/shaowenchen/ops-mcp-server/pkg/metrics"
+	"github.com/shaowenchen/ops-mcp-server/pkg/modules"
 )
 
 // ToolConfig defines configuration for a single tool
-type ToolConfig struct {
-	Enabled     bool   // Whether the tool is enabled
-	Name        string // Tool name
-	Description string // Tool description
-}
+type ToolConfig = modules.ToolConfig
 
 // MetricsToolsConfig defines configuration for all tools
 type MetricsToolsConfig struct {
 	ListMetrics  ToolConfig
 	QueryMetrics ToolConfig
 	QueryRange   ToolConfig
+	Datasources  ToolConfig
+	Labels       ToolConfig
+	LabelValues  ToolConfig
+	Series       ToolConfig
+	Alerts       ToolConfig
+	AlertRules   ToolConfig
+	Targets      ToolConfig
+	TSDBStatus   ToolConfig
+	RuntimeInfo  ToolConfig
+	// Alertmanager tools, disabled when no Alertmanager is configured
+	AlertGroups   ToolConfig
+	AlertDetails  ToolConfig
+	Silences      ToolConfig
+	CreateSilence ToolConfig
+	ExpireSilence ToolConfig
 }
 
 // GetDefaultToolsConfig returns default tool configuration
@@ -36,7 +52,77 @@ func GetDefaultToolsConfig() MetricsToolsConfig {
 		QueryRange: ToolConfig{
 			Enabled:     true,
 			Name:        "query-metrics-range",
-			Description: "Execute a custom PromQL range query over a time period. Examples: 'rate(cpu_usage[5m])', 'sum(memory_usage_bytes) by (pod)'",
+			Description: "Execute a custom PromQL range query over a time period. Examples: 'rate(cpu_usage[5m])', 'sum(memory_usage_bytes) by (pod)'. Set summarize to get per-series statistics, trend, step changes and spikes instead of every sample.",
+		},
+		Datasources: ToolConfig{
+			Enabled:     true,
+			Name:        "list-metrics-datasources",
+			Description: "List the Prometheus datasources that metrics tools can query with the datasource argument, and which one is the default.",
+		},
+		Labels: ToolConfig{
+			Enabled:     true,
+			Name:        "list-labels",
+			Description: "List the label names of Prometheus series, optionally only those of the series matching selectors within a time range. Use it to find which labels can filter a PromQL query.",
+		},
+		LabelValues: ToolConfig{
+			Enabled:     true,
+			Name:        "list-label-values",
+			Description: "List the values of a Prometheus label, optionally only those of the series matching selectors within a time range. Examples: namespaces with label 'namespace', jobs of a metric with label 'job' and match 'up'.",
+		},
+		Series: ToolConfig{
+			Enabled:     true,
+			Name:        "find-series",
+			Description: "Find the Prometheus series matching selectors within a time range and return their full label sets. Example match: '{__name__=\"http_requests_total\", namespace=\"payments\"}'.",
+		},
+		Alerts: ToolConfig{
+			Enabled:     true,
+			Name:        "list-alerts",
+			Description: "List the firing and pending Prometheus alerts, most severe first, filtered by state, severity and label matchers. Use it first during an incident to see what is firing.",
+		},
+		AlertRules: ToolConfig{
+			Enabled:     true,
+			Name:        "get-alert-rules",
+			Description: "Show the alerting rules of an alert name: PromQL expression, for duration, labels, annotations, state, and the last evaluation and its error.",
+		},
+		Targets: ToolConfig{
+			Enabled:     true,
+			Name:        "list-targets",
+			Description: "Summarize the scrape targets per scrape pool (up, down, scrape durations) and list the unhealthy targets with their last error. Use it when a metric has no data.",
+		},
+		TSDBStatus: ToolConfig{
+			Enabled:     true,
+			Name:        "get-tsdb-status",
+			Description: "Show the TSDB head block size and the metrics, labels and label pairs with the highest cardinality. Use it to find what drives series count and memory.",
+		},
+		RuntimeInfo: ToolConfig{
+			Enabled:     true,
+			Name:        "get-runtime-info",
+			Description: "Show the Prometheus version, start time, config reload status, storage retention and Go runtime settings.",
+		},
+		AlertGroups: ToolConfig{
+			Enabled:     true,
+			Name:        "list-alert-groups",
+			Description: "List the alerts of Alertmanager grouped as they are notified, with their receiver and fingerprint. Silenced and inhibited alerts are left out unless asked for.",
+		},
+		AlertDetails: ToolConfig{
+			Enabled:     true,
+			Name:        "get-alert-details",
+			Description: "Show an Alertmanager alert by fingerprint: labels, annotations, receivers, and the silences and alerts suppressing it.",
+		},
+		Silences: ToolConfig{
+			Enabled:     true,
+			Name:        "list-silences",
+			Description: "List the Alertmanager silences with their matchers, end time, creator and comment. Expired silences are left out unless asked for.",
+		},
+		CreateSilence: ToolConfig{
+			Enabled:     true,
+			Name:        "create-silence",
+			Description: "Silence the Alertmanager alerts matching a set of label matchers for a duration. The user must confirm the silence before it is created, as it stops notifications for everyone.",
+		},
+		ExpireSilence: ToolConfig{
+			Enabled:     true,
+			Name:        "expire-silence",
+			Description: "Expire an Alertmanager silence by ID so the alerts it matched notify again. The user must confirm it before the silence is expired, as the alerts then notify everyone.",
 		},
 	}
 }
@@ -84,6 +170,132 @@ func (m *Module) BuildTools(toolsConfig MetricsToolsConfig) []server.ServerTool
 		})
 	}
 
+	// List Datasources Tool
+	if toolsConfig.Datasources.Enabled {
+		toolName := m.BuildToolName(toolsConfig.Datasources.Name)
+		tools = append(tools, server.ServerTool{
+			Tool:    m.buildListDatasourcesToolDefinition(toolsConfig.Datasources),
+			Handler: appMetrics.WrapToolHandler(m.handleListDatasources, toolName, "metrics"),
+		})
+	}
+
+	// List Labels Tool
+	if toolsConfig.Labels.Enabled {
+		toolName := m.BuildToolName(toolsConfig.Labels.Name)
+		tools = append(tools, server.ServerTool{
+			Tool:    m.buildListLabelsToolDefinition(toolsConfig.Labels),
+			Handler: appMetrics.WrapToolHandler(m.handleListLabels, toolName, "metrics"),
+		})
+	}
+
+	// List Label Values Tool
+	if toolsConfig.LabelValues.Enabled {
+		toolName := m.BuildToolName(toolsConfig.LabelValues.Name)
+		tools = append(tools, server.ServerTool{
+			Tool:    m.buildListLabelValuesToolDefinition(toolsConfig.LabelValues),
+			Handler: appMetrics.WrapToolHandler(m.handleListLabelValues, toolName, "metrics"),
+		})
+	}
+
+	// Find Series Tool
+	if toolsConfig.Series.Enabled {
+		toolName := m.BuildToolName(toolsConfig.Series.Name)
+		tools = append(tools, server.ServerTool{
+			Tool:    m.buildFindSeriesToolDefinition(toolsConfig.Series),
+			Handler: appMetrics.WrapToolHandler(m.handleFindSeries, toolName, "metrics"),
+		})
+	}
+
+	// List Alerts Tool
+	if toolsConfig.Alerts.Enabled {
+		toolName := m.BuildToolName(toolsConfig.Alerts.Name)
+		tools = append(tools, server.ServerTool{
+			Tool:    m.buildListAlertsToolDefinition(toolsConfig.Alerts),
+			Handler: appMetrics.WrapToolHandler(m.handleListAlerts, toolName, "metrics"),
+		})
+	}
+
+	// Get Alert Rules Tool
+	if toolsConfig.AlertRules.Enabled {
+		toolName := m.BuildToolName(toolsConfig.AlertRules.Name)
+		tools = append(tools, server.ServerTool{
+			Tool:    m.buildGetAlertRulesToolDefinition(toolsConfig.AlertRules),
+			Handler: appMetrics.WrapToolHandler(m.handleGetAlertRules, toolName, "metrics"),
+		})
+	}
+
+	// List Targets Tool
+	if toolsConfig.Targets.Enabled {
+		toolName := m.BuildToolName(toolsConfig.Targets.Name)
+		tools = append(tools, server.ServerTool{
+			Tool:    m.buildListTargetsToolDefinition(toolsConfig.Targets),
+			Handler: appMetrics.WrapToolHandler(m.handleListTargets, toolName, "metrics"),
+		})
+	}
+
+	// Get TSDB Status Tool
+	if toolsConfig.TSDBStatus.Enabled {
+		toolName := m.BuildToolName(toolsConfig.TSDBStatus.Name)
+		tools = append(tools, server.ServerTool{
+			Tool:    m.buildGetTSDBStatusToolDefinition(toolsConfig.TSDBStatus),
+			Handler: appMetrics.WrapToolHandler(m.handleGetTSDBStatus, toolName, "metrics"),
+		})
+	}
+
+	// Get Runtime Info Tool
+	if toolsConfig.RuntimeInfo.Enabled {
+		toolName := m.BuildToolName(toolsConfig.RuntimeInfo.Name)
+		tools = append(tools, server.ServerTool{
+			Tool:    m.buildGetRuntimeInfoToolDefinition(toolsConfig.RuntimeInfo),
+			Handler: appMetrics.WrapToolHandler(m.handleGetRuntimeInfo, toolName, "metrics"),
+		})
+	}
+
+	// List Alert Groups Tool
+	if toolsConfig.AlertGroups.Enabled {
+		toolName := m.BuildToolName(toolsConfig.AlertGroups.Name)
+		tools = append(tools, server.ServerTool{
+			Tool:    m.buildListAlertGroupsToolDefinition(toolsConfig.AlertGroups),
+			Handler: appMetrics.WrapToolHandler(m.handleListAlertGroups, toolName, "metrics"),
+		})
+	}
+
+	// Get Alert Details Tool
+	if toolsConfig.AlertDetails.Enabled {
+		toolName := m.BuildToolName(toolsConfig.AlertDetails.Name)
+		tools = append(tools, server.ServerTool{
+			Tool:    m.buildGetAlertDetailsToolDefinition(toolsConfig.AlertDetails),
+			Handler: appMetrics.WrapToolHandler(m.handleGetAlertDetails, toolName, "metrics"),
+		})
+	}
+
+	// List Silences Tool
+	if toolsConfig.Silences.Enabled {
+		toolName := m.BuildToolName(toolsConfig.Silences.Name)
+		tools = append(tools, server.ServerTool{
+			Tool:    m.buildListSilencesToolDefinition(toolsConfig.Silences),
+			Handler: appMetrics.WrapToolHandler(m.handleListSilences, toolName, "metrics"),
+		})
+	}
+
+	// Create Silence Tool
+	if toolsConfig.CreateSilence.Enabled {
+		toolName := m.BuildToolName(toolsConfig.CreateSilence.Name)
+		tools = append(tools, server.ServerTool{
+			Tool:    m.buildCreateSilenceToolDefinition(toolsConfig.CreateSilence),
+			Handler: appMetrics.WrapToolHandler(m.handleCreateSilence, toolName, "metrics"),
+		})
+	}
+
+	// Expire Silence Tool
+	if toolsConfig.ExpireSilence.Enabled {
+		toolName := m.BuildToolName(toolsConfig.ExpireSilence.Name)
+		tools = append(tools, server.ServerTool{
+			Tool:    m.buildExpireSilenceToolDefinition(toolsConfig.ExpireSilence),
+			Handler: appMetrics.WrapToolHandler(m.handleExpireSilence, toolName, "metrics"),
+		})
+	}
+
 	return tools
 }
 
@@ -93,7 +305,10 @@ func (m *Module) buildListMetricsToolDefinition(config ToolConfig) mcp.Tool {
 	return mcp.NewTool(m.BuildToolName(config.Name),
 		mcp.WithDescription(config.Description),
 		mcp.WithString("search", mcp.Description("Filter metrics by name pattern (optional)")),
+		mcp.WithString("match", mcp.Description("Only list metrics of the series matching this selector, e.g. {job=\"node\"} (optional)")),
 		mcp.WithString("limit", mcp.Description("Maximum number of metrics to return (default: 100)")),
+		m.datasourceArgument(),
+		modules.OutputSchema[ListMetricsResponse](nil),
 	)
 }
 
@@ -101,6 +316,8 @@ func (m *Module) buildQueryMetricsToolDefinition(config ToolConfig) mcp.Tool {
 	return mcp.NewTool(m.BuildToolName(config.Name),
 		mcp.WithDescription(config.Description),
 		mcp.WithString("query", mcp.Required(), mcp.Description("PromQL query expression to execute")),
+		m.datasourceArgument(),
+		modules.OutputSchema[MetricsQueryResponse](prometheusSchemas),
 	)
 }
 
@@ -110,5 +327,219 @@ func (m *Module) buildQueryRangeToolDefinition(config ToolConfig) mcp.Tool {
 		mcp.WithString("query", mcp.Required(), mcp.Description("PromQL query expression to execute")),
 		mcp.WithString("time_range", mcp.Required(), mcp.Description("Time range for query (examples: 5m, 10m, 1h, 2h, 24h, 7d). Supports s(seconds), m(minutes), h(hours), d(days)")),
 		mcp.WithString("step", mcp.Description("Query resolution step (default: 15s, examples: 15s, 30s, 60s, 1m, 5m). Supports s(seconds), m(minutes), h(hours)")),
+		mcp.WithBoolean("summarize", mcp.Description("Return per-series min, max, avg, p95, last, slope, step changes and spikes instead of every sample (default: false)")),
+		mcp.WithString("sort_by", mcp.Description("Statistic ranking the summarized series, highest first (default: max)"), mcp.Enum(summaryStatistics...)),
+		mcp.WithString("top_k", mcp.Description(fmt.Sprintf("Number of summarized series to return (default: %d)", defaultTopK))),
+		mcp.WithString("max_samples", mcp.Description(fmt.Sprintf("Maximum number of samples returned in full, larger results are summarized (default: %d)", defaultMaxSamples))),
+		m.datasourceArgument(),
+		modules.OutputSchema[MetricsQueryResponse](prometheusSchemas),
 	)
 }
+
+func (m *Module) buildListDatasourcesToolDefinition(config ToolConfig) mcp.Tool {
+	return mcp.NewTool(m.BuildToolName(config.Name),
+		mcp.WithDescription(config.Description),
+		modules.OutputSchema[DatasourcesResponse](nil),
+	)
+}
+
+func (m *Module) buildListLabelsToolDefinition(config ToolConfig) mcp.Tool {
+	return mcp.NewTool(m.BuildToolName(config.Name),
+		mcp.WithDescription(config.Description),
+		metadataArguments(false),
+		m.datasourceArgument(),
+		modules.OutputSchema[LabelsResponse](nil),
+	)
+}
+
+func (m *Module) buildListLabelValuesToolDefinition(config ToolConfig) mcp.Tool {
+	return mcp.NewTool(m.BuildToolName(config.Name),
+		mcp.WithDescription(config.Description),
+		mcp.WithString("label", mcp.Required(), mcp.Description("Label name to list the values of, e.g. namespace, job or __name__ for metric names")),
+		metadataArguments(false),
+		m.datasourceArgument(),
+		modules.OutputSchema[LabelValuesResponse](nil),
+	)
+}
+
+func (m *Module) buildFindSeriesToolDefinition(config ToolConfig) mcp.Tool {
+	return mcp.NewTool(m.BuildToolName(config.Name),
+		mcp.WithDescription(config.Description),
+		metadataArguments(true),
+		m.datasourceArgument(),
+		modules.OutputSchema[SeriesResponse](nil),
+	)
+}
+
+func (m *Module) buildListAlertsToolDefinition(config ToolConfig) mcp.Tool {
+	return mcp.NewTool(m.BuildToolName(config.Name),
+		mcp.WithDescription(config.Description),
+		mcp.WithString("state", mcp.Description("Only alerts in this state (default: both)"), mcp.Enum("firing", "pending")),
+		mcp.WithString("severity", mcp.Description("Only alerts with these comma-separated severity labels, e.g. critical or critical,warning")),
+		mcp.WithArray("labels", mcp.Description("Label matchers the alerts must all satisfy, e.g. namespace=\"payments\" or service=~\"api-.*\""), mcp.WithStringItems()),
+		mcp.WithString("limit", mcp.Description("Maximum number of alerts to return (default: 100)")),
+		m.datasourceArgument(),
+		modules.OutputSchema[AlertsResponse](nil),
+	)
+}
+
+func (m *Module) buildGetAlertRulesToolDefinition(config ToolConfig) mcp.Tool {
+	return mcp.NewTool(m.BuildToolName(config.Name),
+		mcp.WithDescription(config.Description),
+		mcp.WithString("alert", mcp.Required(), mcp.Description("Alert name, the alertname label of the alerts, e.g. HighErrorRate")),
+		m.datasourceArgument(),
+		modules.OutputSchema[AlertRulesResponse](nil),
+	)
+}
+
+func (m *Module) buildListTargetsToolDefinition(config ToolConfig) mcp.Tool {
+	return mcp.NewTool(m.BuildToolName(config.Name),
+		mcp.WithDescription(config.Description),
+		mcp.WithString("job", mcp.Description("Only targets of this job or scrape pool, e.g. node")),
+		mcp.WithString("health", mcp.Description("Which targets to list (default: unhealthy, i.e. down and unknown). Scrape pools are always summarized"), mcp.Enum("up", "down", "unknown", "unhealthy", "all")),
+		mcp.WithString("limit", mcp.Description("Maximum number of targets to return (default: 100)")),
+		m.datasourceArgument(),
+		modules.OutputSchema[TargetsResponse](nil),
+	)
+}
+
+func (m *Module) buildGetTSDBStatusToolDefinition(config ToolConfig) mcp.Tool {
+	return mcp.NewTool(m.BuildToolName(config.Name),
+		mcp.WithDescription(config.Description),
+		mcp.WithString("limit", mcp.Description("Number of entries of each cardinality ranking (default: 10)")),
+		m.datasourceArgument(),
+		modules.OutputSchema[TSDBStatusResponse](nil),
+	)
+}
+
+func (m *Module) buildGetRuntimeInfoToolDefinition(config ToolConfig) mcp.Tool {
+	return mcp.NewTool(m.BuildToolName(config.Name),
+		mcp.WithDescription(config.Description),
+		m.datasourceArgument(),
+		modules.OutputSchema[RuntimeInfoResponse](nil),
+	)
+}
+
+func (m *Module) buildListAlertGroupsToolDefinition(config ToolConfig) mcp.Tool {
+	return mcp.NewTool(m.BuildToolName(config.Name),
+		mcp.WithDescription(config.Description),
+		mcp.WithArray("filter", mcp.Description("Label matchers the alerts must all satisfy, e.g. namespace=\"payments\" or service=~\"api-.*\""), mcp.WithStringItems()),
+		mcp.WithString("receiver", mcp.Description("Only groups notified to receivers matching this regex")),
+		mcp.WithBoolean("include_silenced", mcp.Description("Also list silenced alerts (default: false)")),
+		mcp.WithBoolean("include_inhibited", mcp.Description("Also list inhibited alerts (default: false)")),
+		mcp.WithString("limit", mcp.Description("Maximum number of alerts to return (default: 100)")),
+		modules.OutputSchema[AlertGroupsResponse](nil),
+	)
+}
+
+func (m *Module) buildGetAlertDetailsToolDefinition(config ToolConfig) mcp.Tool {
+	return mcp.NewTool(m.BuildToolName(config.Name),
+		mcp.WithDescription(config.Description),
+		mcp.WithString("fingerprint", mcp.Required(), mcp.Description("Fingerprint of the alert, as listed by "+m.BuildToolName(m.tools.AlertGroups.Name))),
+		modules.OutputSchema[AlertDetailsResponse](nil),
+	)
+}
+
+func (m *Module) buildListSilencesToolDefinition(config ToolConfig) mcp.Tool {
+	return mcp.NewTool(m.BuildToolName(config.Name),
+		mcp.WithDescription(config.Description),
+		mcp.WithString("state", mcp.Description("Only silences in this state (default: active and pending)"), mcp.Enum("active", "pending", "expired")),
+		mcp.WithArray("filter", mcp.Description("Label matchers the silences must match, e.g. namespace=\"payments\""), mcp.WithStringItems()),
+		mcp.WithString("limit", mcp.Description("Maximum number of silences to return (default: 100)")),
+		modules.OutputSchema[SilencesResponse](nil),
+	)
+}
+
+func (m *Module) buildCreateSilenceToolDefinition(config ToolConfig) mcp.Tool {
+	return mcp.NewTool(m.BuildToolName(config.Name),
+		mcp.WithDescription(config.Description),
+		mcp.WithArray("matchers", mcp.Required(), mcp.Description("Label matchers the silenced alerts must all satisfy, e.g. alertname=\"HighErrorRate\" and namespace=\"payments\""), mcp.WithStringItems()),
+		mcp.WithString("duration", mcp.Required(), mcp.Description("How long the silence lasts from now (examples: 30m, 2h, 1d)")),
+		mcp.WithString("comment", mcp.Required(), mcp.Description("Why the alerts are silenced, e.g. the incident or change ticket")),
+		mcp.WithString("created_by", mcp.Description("Author of the silence, only used when the server runs without authentication (default: ops-mcp-server)")),
+		mcp.WithReadOnlyHintAnnotation(false),
+		mcp.WithDestructiveHintAnnotation(false),
+		mcp.WithIdempotentHintAnnotation(false),
+		modules.OutputSchema[SilenceResult](nil),
+	)
+}
+
+func (m *Module) buildExpireSilenceToolDefinition(config ToolConfig) mcp.Tool {
+	return mcp.NewTool(m.BuildToolName(config.Name),
+		mcp.WithDescription(config.Description),
+		mcp.WithString("silence_id", mcp.Required(), mcp.Description("ID of the silence, as listed by "+m.BuildToolName(m.tools.Silences.Name))),
+		mcp.WithReadOnlyHintAnnotation(false),
+		mcp.WithDestructiveHintAnnotation(true),
+		mcp.WithIdempotentHintAnnotation(true),
+		modules.OutputSchema[SilenceResult](nil),
+	)
+}
+
+// metadataArguments returns the selector, time bound and limit arguments of
+// the labels, label values and series tools
+func metadataArguments(matchRequired bool) mcp.ToolOption {
+	return func(tool *mcp.Tool) {
+		matchOptions := []mcp.PropertyOption{
+			mcp.Description("Series selectors, e.g. {job=\"node\"} or up. Series matching any of them are used"),
+			mcp.WithStringItems(),
+		}
+		if matchRequired {
+			matchOptions = append(matchOptions, mcp.Required())
+		}
+		for _, option := range []mcp.ToolOption{
+			mcp.WithArray("match", matchOptions...),
+			mcp.WithString("start", mcp.Description("Start of the time range as RFC 3339 or Unix timestamp (default: Prometheus default, usually all data)")),
+			mcp.WithString("end", mcp.Description("End of the time range as RFC 3339 or Unix timestamp (default: now)")),
+			mcp.WithString("time_range", mcp.Description("Look back this long from end instead of setting start (examples: 1h, 24h, 7d)")),
+			mcp.WithString("limit", mcp.Description("Maximum number of results to return (default: 100)")),
+		} {
+			option(tool)
+		}
+	}
+}
+
+// prometheusSchemas describes samples as the [timestamp, "value"] pairs
+// they marshal to
+var prometheusSchemas = modules.TypeSchemas{
+	reflect.TypeFor[PrometheusValue](): {
+		Type:        "array",
+		PrefixItems: []*jsonschema.Schema{{Type: "number"}, {Type: "string"}},
+		MinItems:    jsonschema.Ptr(2),
+		MaxItems:    jsonschema.Ptr(2),
+	},
+}
+
+// datasourceArgument returns the optional datasource argument of the query tools
+func (m *Module) datasourceArgument() mcp.ToolOption {
+	description := "Prometheus datasource to query"
+	if m.tools.Datasources.Enabled {
+		description += ", see " + m.BuildToolName(m.tools.Datasources.Name)
+	}
+	if m.defaultDatasource != "" {
+		description += " (default: " + m.defaultDatasource + ")"
+	}
+	options := []mcp.PropertyOption{mcp.Description(description)}
+	if len(m.datasourceNames) > 0 {
+		options = append(options, mcp.Enum(m.datasourceNames...))
+	}
+	return mcp.WithString("datasource", options...)
+}
+
+// toolConfigs returns every tool of the configuration
+func (c *MetricsToolsConfig) toolConfigs() []*ToolConfig {
+	return []*ToolConfig{&c.ListMetrics, &c.QueryMetrics, &c.QueryRange, &c.Datasources, &c.Labels, &c.LabelValues, &c.Series, &c.Alerts, &c.AlertRules,
+		&c.Targets, &c.TSDBStatus, &c.RuntimeInfo,
+		&c.AlertGroups, &c.AlertDetails, &c.Silences, &c.CreateSilence, &c.ExpireSilence}
+}
+
+// alertmanagerToolConfigs returns the tools calling Alertmanager
+func (c *MetricsToolsConfig) alertmanagerToolConfigs() []*ToolConfig {
+	return []*ToolConfig{&c.AlertGroups, &c.AlertDetails, &c.Silences, &c.CreateSilence, &c.ExpireSilence}
+}
+
+// ToolNamesByDefault maps the default name of each enabled tool to the name
+// it is exposed under, after overrides, prefix and suffix
+func (m *Module) ToolNamesByDefault() map[string]string {
+	defaults := GetDefaultToolsConfig()
+	return modules.ToolNamesByDefault(defaults.toolConfigs(), m.tools.toolConfigs(), m.BuildToolName)
+}
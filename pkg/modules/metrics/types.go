@@ -112,9 +112,487 @@ type MetricsQueryRequest struct {
 
 // MetricsQueryResponse represents a metrics query response
 type MetricsQueryResponse struct {
-	Status   string            `json:"status"`
-	Data     interface{}       `json:"data"`
-	Error    string            `json:"error,omitempty"`
-	Warnings []string          `json:"warnings,omitempty"`
-	Metadata map[string]string `json:"metadata,omitempty"`
+	Status   string                `json:"status"`
+	Data     PrometheusQueryResult `json:"data"`
+	Error    string                `json:"error,omitempty"`
+	Warnings []string              `json:"warnings,omitempty"`
+	Metadata map[string]string     `json:"metadata,omitempty"`
+	// Summary replaces the samples of a summarized range query
+	Summary *RangeSummary `json:"summary,omitempty"`
+}
+
+// RangeSummary describes the series of a range query by their statistics
+// instead of their samples, the top TopK of them by SortBy. Series without
+// a finite sample are counted in EmptySeries and not ranked.
+type RangeSummary struct {
+	SeriesCount int             `json:"series_count"`
+	SampleCount int             `json:"sample_count"`
+	EmptySeries int             `json:"empty_series"`
+	SortBy      string          `json:"sort_by"`
+	TopK        int             `json:"top_k"`
+	Truncated   bool            `json:"truncated"`
+	Series      []SeriesSummary `json:"series"`
+}
+
+// SeriesSummary holds the statistics of a series over the queried range.
+// NaN and infinite samples are left out of the statistics.
+type SeriesSummary struct {
+	Metric  map[string]string `json:"metric"`
+	Samples int               `json:"samples"`
+	Min     float64           `json:"min"`
+	Max     float64           `json:"max"`
+	Avg     float64           `json:"avg"`
+	P95     float64           `json:"p95"`
+	Last    float64           `json:"last"`
+	MinAt   string            `json:"min_at"`
+	MaxAt   string            `json:"max_at"`
+	LastAt  string            `json:"last_at"`
+	// Slope is the least squares trend in units per second
+	Slope       float64      `json:"slope"`
+	StepChanges []StepChange `json:"step_changes,omitempty"`
+	Spikes      []Spike      `json:"spikes,omitempty"`
+}
+
+// StepChange is a lasting shift of the level of a series
+type StepChange struct {
+	Time   string  `json:"time"`
+	Before float64 `json:"before"`
+	After  float64 `json:"after"`
+}
+
+// Spike is a short excursion of a series away from its level
+type Spike struct {
+	Time     string  `json:"time"`
+	Value    float64 `json:"value"`
+	Baseline float64 `json:"baseline"`
+}
+
+// DatasourceInfo describes a configured Prometheus datasource
+type DatasourceInfo struct {
+	Name    string `json:"name"`
+	Default bool   `json:"default"`
+}
+
+// DatasourcesResponse represents the configured datasources
+type DatasourcesResponse struct {
+	Datasources       []DatasourceInfo `json:"datasources"`
+	DefaultDatasource string           `json:"default_datasource"`
+	TotalCount        int              `json:"total_count"`
+}
+
+// ListMetricsResponse represents the metric names of a datasource
+type ListMetricsResponse struct {
+	Datasource   string   `json:"datasource"`
+	Metrics      []string `json:"metrics"`
+	TotalCount   int      `json:"total_count"`
+	SearchFilter string   `json:"search_filter"`
+	Match        string   `json:"match"`
+	Limit        int      `json:"limit"`
+	Timestamp    string   `json:"timestamp"`
+	Status       string   `json:"status"`
+}
+
+// MetadataSelection describes the series selectors, time range and limit
+// applied to a labels, label values or series lookup
+type MetadataSelection struct {
+	Datasource string   `json:"datasource"`
+	Match      []string `json:"match,omitempty"`
+	Start      string   `json:"start,omitempty"`
+	End        string   `json:"end,omitempty"`
+	Limit      int      `json:"limit"`
+	// Truncated is set when more results than the limit matched
+	Truncated bool     `json:"truncated"`
+	Warnings  []string `json:"warnings,omitempty"`
+}
+
+// LabelsResponse represents the label names of a datasource
+type LabelsResponse struct {
+	MetadataSelection
+	Labels     []string `json:"labels"`
+	TotalCount int      `json:"total_count"`
+}
+
+// LabelValuesResponse represents the values of a label
+type LabelValuesResponse struct {
+	MetadataSelection
+	Label      string   `json:"label"`
+	Values     []string `json:"values"`
+	TotalCount int      `json:"total_count"`
+}
+
+// SeriesResponse represents the label sets of the matching series
+type SeriesResponse struct {
+	MetadataSelection
+	Series     []map[string]string `json:"series"`
+	TotalCount int                 `json:"total_count"`
+}
+
+// PrometheusAlert is an active alert as returned by /api/v1/alerts and
+// within the alerting rules of /api/v1/rules
+type PrometheusAlert struct {
+	Labels      map[string]string `json:"labels"`
+	Annotations map[string]string `json:"annotations"`
+	State       string            `json:"state"`
+	ActiveAt    string            `json:"activeAt,omitempty"`
+	Value       string            `json:"value"`
+}
+
+// PrometheusRuleGroup is a rule group as returned by /api/v1/rules
+type PrometheusRuleGroup struct {
+	Name  string           `json:"name"`
+	File  string           `json:"file"`
+	Rules []PrometheusRule `json:"rules"`
+}
+
+// PrometheusRule is an alerting or recording rule, durations in seconds
+type PrometheusRule struct {
+	Type           string            `json:"type"`
+	Name           string            `json:"name"`
+	Query          string            `json:"query"`
+	Duration       float64           `json:"duration"`
+	KeepFiringFor  float64           `json:"keepFiringFor"`
+	Labels         map[string]string `json:"labels"`
+	Annotations    map[string]string `json:"annotations"`
+	Alerts         []PrometheusAlert `json:"alerts"`
+	State          string            `json:"state"`
+	Health         string            `json:"health"`
+	LastError      string            `json:"lastError"`
+	LastEvaluation string            `json:"lastEvaluation"`
+	EvaluationTime float64           `json:"evaluationTime"`
+}
+
+// Alert is an active alert with its alertname and severity labels lifted
+// out of the other labels
+type Alert struct {
+	Name        string            `json:"name"`
+	State       string            `json:"state"`
+	Severity    string            `json:"severity,omitempty"`
+	Labels      map[string]string `json:"labels,omitempty"`
+	Annotations map[string]string `json:"annotations,omitempty"`
+	ActiveAt    string            `json:"active_at,omitempty"`
+	Value       string            `json:"value,omitempty"`
+}
+
+// AlertsResponse represents the active alerts matching the filters
+type AlertsResponse struct {
+	Datasource string   `json:"datasource"`
+	State      string   `json:"state,omitempty"`
+	Severity   []string `json:"severity,omitempty"`
+	Matchers   []string `json:"labels,omitempty"`
+	Alerts     []Alert  `json:"alerts"`
+	Firing     int      `json:"firing"`
+	Pending    int      `json:"pending"`
+	// TotalCount counts every matching alert, Alerts holds up to Limit of them
+	TotalCount int  `json:"total_count"`
+	Limit      int  `json:"limit"`
+	Truncated  bool `json:"truncated"`
+}
+
+// AlertRule is an alerting rule with its evaluation status, durations as
+// Go durations like 5m
+type AlertRule struct {
+	Name           string            `json:"name"`
+	Group          string            `json:"group"`
+	File           string            `json:"file,omitempty"`
+	Query          string            `json:"query"`
+	For            string            `json:"for,omitempty"`
+	KeepFiringFor  string            `json:"keep_firing_for,omitempty"`
+	Labels         map[string]string `json:"labels,omitempty"`
+	Annotations    map[string]string `json:"annotations,omitempty"`
+	State          string            `json:"state"`
+	ActiveAlerts   int               `json:"active_alerts"`
+	Health         string            `json:"health"`
+	LastError      string            `json:"last_error,omitempty"`
+	LastEvaluation string            `json:"last_evaluation,omitempty"`
+	EvaluationTime string            `json:"evaluation_time,omitempty"`
+}
+
+// AlertRulesResponse represents the alerting rules of an alert name
+type AlertRulesResponse struct {
+	Datasource string      `json:"datasource"`
+	Alert      string      `json:"alert"`
+	Rules      []AlertRule `json:"rules"`
+	TotalCount int         `json:"total_count"`
+}
+
+// AlertmanagerMatcher is a label matcher of an Alertmanager silence
+type AlertmanagerMatcher struct {
+	Name    string `json:"name"`
+	Value   string `json:"value"`
+	IsRegex bool   `json:"isRegex"`
+	// IsEqual is unset by Alertmanager versions before negative matchers
+	IsEqual *bool `json:"isEqual,omitempty"`
+}
+
+// AlertmanagerAlert is an alert as returned by the Alertmanager v2 API
+type AlertmanagerAlert struct {
+	Fingerprint  string            `json:"fingerprint"`
+	Labels       map[string]string `json:"labels"`
+	Annotations  map[string]string `json:"annotations"`
+	StartsAt     string            `json:"startsAt"`
+	EndsAt       string            `json:"endsAt"`
+	UpdatedAt    string            `json:"updatedAt"`
+	GeneratorURL string            `json:"generatorURL"`
+	Receivers    []struct {
+		Name string `json:"name"`
+	} `json:"receivers"`
+	Status struct {
+		State       string   `json:"state"`
+		SilencedBy  []string `json:"silencedBy"`
+		InhibitedBy []string `json:"inhibitedBy"`
+	} `json:"status"`
+}
+
+// AlertmanagerAlertGroup is an alert group as returned by the Alertmanager v2 API
+type AlertmanagerAlertGroup struct {
+	Labels   map[string]string `json:"labels"`
+	Receiver struct {
+		Name string `json:"name"`
+	} `json:"receiver"`
+	Alerts []AlertmanagerAlert `json:"alerts"`
+}
+
+// AlertmanagerSilence is a silence as returned by the Alertmanager v2 API
+type AlertmanagerSilence struct {
+	ID        string                `json:"id,omitempty"`
+	Matchers  []AlertmanagerMatcher `json:"matchers"`
+	StartsAt  string                `json:"startsAt"`
+	EndsAt    string                `json:"endsAt"`
+	UpdatedAt string                `json:"updatedAt,omitempty"`
+	CreatedBy string                `json:"createdBy"`
+	Comment   string                `json:"comment"`
+	Status    *struct {
+		State string `json:"state"`
+	} `json:"status,omitempty"`
+}
+
+// ManagedAlert is an alert known to Alertmanager with its alertname and
+// severity labels lifted out of the other labels
+type ManagedAlert struct {
+	Fingerprint  string            `json:"fingerprint"`
+	Name         string            `json:"name"`
+	State        string            `json:"state"`
+	Severity     string            `json:"severity,omitempty"`
+	Labels       map[string]string `json:"labels,omitempty"`
+	Annotations  map[string]string `json:"annotations,omitempty"`
+	StartsAt     string            `json:"starts_at,omitempty"`
+	EndsAt       string            `json:"ends_at,omitempty"`
+	GeneratorURL string            `json:"generator_url,omitempty"`
+	Receivers    []string          `json:"receivers,omitempty"`
+	SilencedBy   []string          `json:"silenced_by,omitempty"`
+	InhibitedBy  []string          `json:"inhibited_by,omitempty"`
+}
+
+// AlertGroup is a group of alerts notified together to a receiver
+type AlertGroup struct {
+	Labels   map[string]string `json:"labels"`
+	Receiver string            `json:"receiver"`
+	Alerts   []ManagedAlert    `json:"alerts"`
+}
+
+// AlertGroupsResponse represents the alert groups matching the filters
+type AlertGroupsResponse struct {
+	Filter     []string     `json:"filter,omitempty"`
+	Receiver   string       `json:"receiver,omitempty"`
+	Groups     []AlertGroup `json:"groups"`
+	Active     int          `json:"active"`
+	Suppressed int          `json:"suppressed"`
+	// TotalCount counts every matching alert, Groups hold up to Limit of them
+	TotalCount int  `json:"total_count"`
+	Limit      int  `json:"limit"`
+	Truncated  bool `json:"truncated"`
+}
+
+// Silence is an Alertmanager silence with its matchers rendered like
+// namespace="payments"
+type Silence struct {
+	ID        string   `json:"id"`
+	Matchers  []string `json:"matchers"`
+	State     string   `json:"state,omitempty"`
+	StartsAt  string   `json:"starts_at"`
+	EndsAt    string   `json:"ends_at"`
+	CreatedBy string   `json:"created_by"`
+	Comment   string   `json:"comment"`
+}
+
+// AlertDetailsResponse represents an alert with the silences and alerts
+// suppressing it
+type AlertDetailsResponse struct {
+	Alert       ManagedAlert   `json:"alert"`
+	Silences    []Silence      `json:"silences,omitempty"`
+	InhibitedBy []ManagedAlert `json:"inhibited_by,omitempty"`
+}
+
+// SilencesResponse represents the silences matching the filters
+type SilencesResponse struct {
+	State      string    `json:"state,omitempty"`
+	Filter     []string  `json:"filter,omitempty"`
+	Silences   []Silence `json:"silences"`
+	TotalCount int       `json:"total_count"`
+	Limit      int       `json:"limit"`
+	Truncated  bool      `json:"truncated"`
+}
+
+// SilenceResult represents a silence created or expired, or the reason the
+// user did not approve it
+type SilenceResult struct {
+	Status  string   `json:"status"`
+	Silence *Silence `json:"silence,omitempty"`
+	// MatchedAlerts counts the alerts the silence matched when it was created
+	MatchedAlerts int    `json:"matched_alerts"`
+	Reason        string `json:"reason,omitempty"`
+	Message       string `json:"message,omitempty"`
+}
+
+// PrometheusTarget is an active scrape target as returned by /api/v1/targets,
+// without its discovered labels
+type PrometheusTarget struct {
+	Labels             map[string]string `json:"labels"`
+	ScrapePool         string            `json:"scrapePool"`
+	ScrapeURL          string            `json:"scrapeUrl"`
+	LastError          string            `json:"lastError"`
+	LastScrape         string            `json:"lastScrape"`
+	LastScrapeDuration float64           `json:"lastScrapeDuration"`
+	Health             string            `json:"health"`
+	ScrapeInterval     string            `json:"scrapeInterval"`
+	ScrapeTimeout      string            `json:"scrapeTimeout"`
+}
+
+// ScrapePool summarizes the health and scrape durations of the targets of a
+// scrape pool, usually named after its job
+type ScrapePool struct {
+	Name    string `json:"name"`
+	Up      int    `json:"up"`
+	Down    int    `json:"down"`
+	Unknown int    `json:"unknown"`
+	// Scrape durations in seconds
+	AvgScrapeDuration float64 `json:"avg_scrape_duration"`
+	MaxScrapeDuration float64 `json:"max_scrape_duration"`
+	ScrapeInterval    string  `json:"scrape_interval,omitempty"`
+	ScrapeTimeout     string  `json:"scrape_timeout,omitempty"`
+}
+
+// Target is a scrape target with its job and instance lifted out of its labels
+type Target struct {
+	Pool               string            `json:"pool"`
+	Job                string            `json:"job"`
+	Instance           string            `json:"instance"`
+	Health             string            `json:"health"`
+	ScrapeURL          string            `json:"scrape_url"`
+	Labels             map[string]string `json:"labels,omitempty"`
+	LastError          string            `json:"last_error,omitempty"`
+	LastScrape         string            `json:"last_scrape,omitempty"`
+	LastScrapeDuration float64           `json:"last_scrape_duration"`
+}
+
+// TargetsResponse represents the scrape pools of a datasource and the targets
+// matching the filters
+type TargetsResponse struct {
+	Datasource string       `json:"datasource"`
+	Job        string       `json:"job,omitempty"`
+	Health     string       `json:"health"`
+	Pools      []ScrapePool `json:"pools"`
+	Targets    []Target     `json:"targets"`
+	// TotalCount counts every matching target, Targets holds up to Limit of them
+	TotalCount int  `json:"total_count"`
+	Limit      int  `json:"limit"`
+	Truncated  bool `json:"truncated"`
+}
+
+// PrometheusTSDBStatus is the cardinality report of /api/v1/status/tsdb
+type PrometheusTSDBStatus struct {
+	HeadStats struct {
+		NumSeries     int64 `json:"numSeries"`
+		NumLabelPairs int64 `json:"numLabelPairs"`
+		ChunkCount    int64 `json:"chunkCount"`
+		MinTime       int64 `json:"minTime"`
+		MaxTime       int64 `json:"maxTime"`
+	} `json:"headStats"`
+	SeriesCountByMetricName     []NameCount `json:"seriesCountByMetricName"`
+	LabelValueCountByLabelName  []NameCount `json:"labelValueCountByLabelName"`
+	MemoryInBytesByLabelName    []NameCount `json:"memoryInBytesByLabelName"`
+	SeriesCountByLabelValuePair []NameCount `json:"seriesCountByLabelValuePair"`
+}
+
+// NameCount is an entry of a TSDB cardinality ranking
+type NameCount struct {
+	Name  string `json:"name"`
+	Value int64  `json:"value"`
+}
+
+// HeadStats describes the series held in the TSDB head block
+type HeadStats struct {
+	Series     int64  `json:"series"`
+	LabelPairs int64  `json:"label_pairs"`
+	Chunks     int64  `json:"chunks"`
+	MinTime    string `json:"min_time,omitempty"`
+	MaxTime    string `json:"max_time,omitempty"`
+}
+
+// TSDBStatusResponse represents the cardinality of the TSDB head block with
+// the top Limit entries of each ranking
+type TSDBStatusResponse struct {
+	Datasource                  string      `json:"datasource"`
+	HeadStats                   HeadStats   `json:"head_stats"`
+	SeriesCountByMetricName     []NameCount `json:"series_count_by_metric_name"`
+	LabelValueCountByLabelName  []NameCount `json:"label_value_count_by_label_name"`
+	MemoryInBytesByLabelName    []NameCount `json:"memory_in_bytes_by_label_name"`
+	SeriesCountByLabelValuePair []NameCount `json:"series_count_by_label_value_pair"`
+	Limit                       int         `json:"limit"`
+}
+
+// PrometheusBuildInfo is the build information of /api/v1/status/buildinfo
+type PrometheusBuildInfo struct {
+	Version   string `json:"version"`
+	Revision  string `json:"revision"`
+	Branch    string `json:"branch"`
+	BuildUser string `json:"buildUser"`
+	BuildDate string `json:"buildDate"`
+	GoVersion string `json:"goVersion"`
+}
+
+// PrometheusRuntimeInfo is the runtime information of /api/v1/status/runtimeinfo
+type PrometheusRuntimeInfo struct {
+	StartTime           string `json:"startTime"`
+	CWD                 string `json:"CWD"`
+	ReloadConfigSuccess bool   `json:"reloadConfigSuccess"`
+	LastConfigTime      string `json:"lastConfigTime"`
+	CorruptionCount     int64  `json:"corruptionCount"`
+	GoroutineCount      int    `json:"goroutineCount"`
+	GOMAXPROCS          int    `json:"GOMAXPROCS"`
+	GOMEMLIMIT          int64  `json:"GOMEMLIMIT"`
+	GOGC                string `json:"GOGC"`
+	GODEBUG             string `json:"GODEBUG"`
+	StorageRetention    string `json:"storageRetention"`
+}
+
+// BuildInfo is the version of a Prometheus server
+type BuildInfo struct {
+	Version   string `json:"version"`
+	Revision  string `json:"revision,omitempty"`
+	Branch    string `json:"branch,omitempty"`
+	BuildDate string `json:"build_date,omitempty"`
+	GoVersion string `json:"go_version,omitempty"`
+}
+
+// RuntimeInfo is the runtime state of a Prometheus server
+type RuntimeInfo struct {
+	StartTime           string `json:"start_time"`
+	ReloadConfigSuccess bool   `json:"reload_config_success"`
+	LastConfigTime      string `json:"last_config_time,omitempty"`
+	CorruptionCount     int64  `json:"corruption_count"`
+	GoroutineCount      int    `json:"goroutine_count"`
+	GOMAXPROCS          int    `json:"gomaxprocs"`
+	GOMEMLIMIT          int64  `json:"gomemlimit,omitempty"`
+	GOGC                string `json:"gogc,omitempty"`
+	StorageRetention    string `json:"storage_retention,omitempty"`
+}
+
+// RuntimeInfoResponse represents the build and runtime information of a
+// datasource, either left out when the server does not provide it
+type RuntimeInfoResponse struct {
+	Datasource string       `json:"datasource"`
+	Build      *BuildInfo   `json:"build,omitempty"`
+	Runtime    *RuntimeInfo `json:"runtime,omitempty"`
+	Warnings   []string     `json:"warnings,omitempty"`
 }
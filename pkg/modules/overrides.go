@@ -0,0 +1,83 @@
+package modules
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/shaowenchen/ops-mcp-server/pkg/config"
+	"go.uber.org/zap"
+)
+
+// ToolConfig defines configuration for a single tool
+type ToolConfig struct {
+	Enabled     bool   // Whether the tool is enabled
+	Name        string // Tool name
+	Description string // Tool description
+}
+
+// ApplyToolOverrides applies the tools.overrides of the module configured
+// at section, keyed by default tool name, to tools, which must still carry
+// their default names. buildName returns the name a tool is exposed under.
+func ApplyToolOverrides(section string, tools []*ToolConfig, overrides map[string]config.ToolOverride, buildName func(string) string, logger *zap.Logger) error {
+	byName := make(map[string]*ToolConfig, len(tools))
+	defaultNames := make([]string, 0, len(tools))
+	for _, tool := range tools {
+		byName[tool.Name] = tool
+		defaultNames = append(defaultNames, tool.Name)
+	}
+
+	names := make([]string, 0, len(overrides))
+	for name := range overrides {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		tool, ok := byName[name]
+		if !ok {
+			return fmt.Errorf("%s.tools.overrides.%s: unknown tool, expected one of %s", section, name, strings.Join(defaultNames, ", "))
+		}
+		override := overrides[name]
+		if override.Enabled != nil {
+			tool.Enabled = *override.Enabled
+		}
+		if override.Name != "" {
+			tool.Name = override.Name
+		}
+		if override.Description != "" {
+			tool.Description = override.Description
+		}
+		logger.Info("Tool override applied",
+			zap.String("tool", name),
+			zap.String("name", buildName(tool.Name)),
+			zap.Bool("enabled", tool.Enabled),
+			zap.Bool("description_overridden", override.Description != ""))
+	}
+
+	// A renamed tool must not take the name of another one
+	seen := make(map[string]bool)
+	for _, tool := range tools {
+		if !tool.Enabled {
+			continue
+		}
+		if seen[tool.Name] {
+			return fmt.Errorf("%s.tools.overrides: more than one tool is named %s", section, tool.Name)
+		}
+		seen[tool.Name] = true
+	}
+	return nil
+}
+
+// ToolNamesByDefault maps the default name of each enabled tool to the name
+// it is exposed under. defaults and current list the same tools in the same
+// order, before and after overrides.
+func ToolNamesByDefault(defaults, current []*ToolConfig, buildName func(string) string) map[string]string {
+	names := make(map[string]string)
+	for i, tool := range defaults {
+		if current[i].Enabled {
+			names[tool.Name] = buildName(current[i].Name)
+		}
+	}
+	return names
+}
@@ -0,0 +1,63 @@
+package modules
+
+import (
+	"strings"
+	"testing"
+
+	"go.uber.org/zap"
+
+	"github.com/shaowenchen/ops-mcp-server/pkg/config"
+)
+
+func defaultTools() []*ToolConfig {
+	return []*ToolConfig{
+		{Enabled: true, Name: "query-metrics", Description: "Query metrics"},
+		{Enabled: true, Name: "list-metrics", Description: "List metrics"},
+	}
+}
+
+func prefixed(name string) string {
+	return "ops-" + name
+}
+
+func TestApplyToolOverrides(t *testing.T) {
+	disabled := false
+	tools := defaultTools()
+	overrides := map[string]config.ToolOverride{
+		"query-metrics": {Name: "promql", Description: "Run PromQL"},
+		"list-metrics":  {Enabled: &disabled},
+	}
+	if err := ApplyToolOverrides("metrics", tools, overrides, prefixed, zap.NewNop()); err != nil {
+		t.Fatalf("ApplyToolOverrides: %v", err)
+	}
+	if tools[0].Name != "promql" || tools[0].Description != "Run PromQL" || !tools[0].Enabled {
+		t.Errorf("query-metrics overridden to %+v", *tools[0])
+	}
+	if tools[1].Enabled {
+		t.Error("list-metrics still enabled")
+	}
+
+	names := ToolNamesByDefault(defaultTools(), tools, prefixed)
+	if len(names) != 1 || names["query-metrics"] != "ops-promql" {
+		t.Errorf("names by default %v, want query-metrics exposed as ops-promql only", names)
+	}
+}
+
+func TestApplyToolOverridesRejects(t *testing.T) {
+	tests := []struct {
+		name      string
+		overrides map[string]config.ToolOverride
+		wantErr   string
+	}{
+		{"unknown tool", map[string]config.ToolOverride{"query": {Name: "q"}}, "metrics.tools.overrides.query: unknown tool, expected one of query-metrics, list-metrics"},
+		{"duplicate name", map[string]config.ToolOverride{"list-metrics": {Name: "query-metrics"}}, "metrics.tools.overrides: more than one tool is named query-metrics"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ApplyToolOverrides("metrics", defaultTools(), tt.overrides, prefixed, zap.NewNop())
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("ApplyToolOverrides error %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,113 @@
+package modules
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// ProgressInterval is how often Wait reports that a backend call is still running
+const ProgressInterval = 5 * time.Second
+
+// progressMethod is the MCP method of progress notifications
+const progressMethod = "notifications/progress"
+
+// Progress sends MCP progress notifications for a tool call whose client
+// passed a progressToken. A nil Progress, returned when the client did not
+// ask for progress, ignores every report.
+type Progress struct {
+	ctx    context.Context
+	server *server.MCPServer
+	token  mcp.ProgressToken
+
+	mu       sync.Mutex
+	progress float64
+}
+
+// NewProgress returns the progress reporter of request, nil when the client
+// did not pass a progressToken
+func NewProgress(ctx context.Context, request mcp.CallToolRequest) *Progress {
+	if request.Params.Meta == nil || request.Params.Meta.ProgressToken == nil {
+		return nil
+	}
+	mcpServer := server.ServerFromContext(ctx)
+	if mcpServer == nil {
+		return nil
+	}
+	return &Progress{ctx: ctx, server: mcpServer, token: request.Params.Meta.ProgressToken}
+}
+
+// ProgressMiddleware stores the progress reporter of each tool call in its
+// context, where backend requests and handlers find it
+func ProgressMiddleware(next server.ToolHandlerFunc) server.ToolHandlerFunc {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		if progress := NewProgress(ctx, request); progress != nil {
+			ctx = context.WithValue(ctx, progressKey{}, progress)
+		}
+		return next(ctx, request)
+	}
+}
+
+type progressKey struct{}
+
+// ProgressFromContext returns the progress reporter of the tool call of ctx,
+// nil when the client did not ask for progress
+func ProgressFromContext(ctx context.Context) *Progress {
+	progress, _ := ctx.Value(progressKey{}).(*Progress)
+	return progress
+}
+
+// Step reports progress one step further than the last report
+func (p *Progress) Step(message string) {
+	if p == nil {
+		return
+	}
+	p.mu.Lock()
+	p.progress++
+	progress := p.progress
+	p.mu.Unlock()
+	p.send(progress, message)
+}
+
+// Wait reports every ProgressInterval that a backend call described by
+// message is still running, until the returned function is called
+func (p *Progress) Wait(message string) func() {
+	if p == nil {
+		return func() {}
+	}
+	done := make(chan struct{})
+	var once sync.Once
+	start := time.Now()
+	go func() {
+		ticker := time.NewTicker(ProgressInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				p.Step(fmt.Sprintf("%s (%s elapsed)", message, time.Since(start).Round(time.Second)))
+			case <-done:
+				return
+			case <-p.ctx.Done():
+				return
+			}
+		}
+	}()
+	return func() { once.Do(func() { close(done) }) }
+}
+
+// send delivers a progress notification, failures only mean the client
+// will not see it and are ignored
+func (p *Progress) send(progress float64, message string) {
+	params := map[string]any{
+		"progressToken": p.token,
+		"progress":      progress,
+	}
+	if message != "" {
+		params["message"] = message
+	}
+	_ = p.server.SendNotificationToClient(p.ctx, progressMethod, params)
+}
@@ -0,0 +1,206 @@
+package modules
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"sort"
+	"sync"
+
+	"github.com/mark3labs/mcp-go/server"
+	"github.com/shaowenchen/ops-mcp-server/pkg/config"
+	"go.uber.org/zap"
+)
+
+// Module is implemented by every module instance
+type Module interface {
+	// GetTools returns the MCP tools exposed by the module
+	GetTools() []server.ServerTool
+	// ToolNamesByDefault maps the default name of each enabled tool to the
+	// name it is exposed under, so prompts can refer to renamed tools
+	ToolNamesByDefault() map[string]string
+	// HealthCheck reports whether the module is ready to serve tool calls.
+	// It must not call the backend, it is used by /healthz.
+	HealthCheck() error
+}
+
+// ResourceProvider is implemented by modules exposing MCP resources, such as
+// the catalogs of their backend
+type ResourceProvider interface {
+	// GetResources returns the resources with a fixed URI
+	GetResources() []server.ServerResource
+	// GetResourceTemplates returns the resources addressed by a URI template
+	GetResourceTemplates() []server.ServerResourceTemplate
+}
+
+// Completer is implemented by modules suggesting argument values from their
+// backend, for completion/complete requests
+type Completer interface {
+	// CompleteArgument returns the candidate values of the argument name, nil
+	// when the module does not complete it. args holds the arguments already
+	// set, such as the service whose operations are completed.
+	CompleteArgument(ctx context.Context, name string, args map[string]string) ([]string, error)
+}
+
+// Factory describes how to build a module from the server configuration
+type Factory struct {
+	// Name is the module name used in logs, metrics and the ?enabled= filter
+	Name string
+	// Section is the top-level config key of the module, e.g. "metrics"
+	Section string
+	// Enabled returns a pointer to the module's enabled flag in cfg so callers
+	// can both read it and override it from CLI flags or environment variables
+	Enabled func(cfg *config.Config) *bool
+	// New creates a module instance from cfg
+	New func(cfg *config.Config, logger *zap.Logger) (Module, error)
+}
+
+// Instance is a module built from a Factory
+type Instance struct {
+	Factory
+	Module            Module
+	Tools             []server.ServerTool
+	Resources         []server.ServerResource
+	ResourceTemplates []server.ServerResourceTemplate
+}
+
+// ToolNames returns the names of the tools exposed by the instance
+func (i *Instance) ToolNames() []string {
+	names := make([]string, 0, len(i.Tools))
+	for _, tool := range i.Tools {
+		names = append(names, tool.Tool.Name)
+	}
+	return names
+}
+
+var (
+	mu        sync.RWMutex
+	factories []Factory
+)
+
+// Register adds a module factory to the registry. It is meant to be called
+// from the init function of each module package and panics on duplicates.
+func Register(f Factory) {
+	mu.Lock()
+	defer mu.Unlock()
+
+	if f.Name == "" || f.New == nil || f.Enabled == nil {
+		panic("modules: factory requires Name, Enabled and New")
+	}
+	for _, existing := range factories {
+		if existing.Name == f.Name {
+			panic(fmt.Sprintf("modules: factory %q registered twice", f.Name))
+		}
+	}
+	if f.Section == "" {
+		f.Section = f.Name
+	}
+	factories = append(factories, f)
+	sort.Slice(factories, func(i, j int) bool {
+		return factories[i].Name < factories[j].Name
+	})
+}
+
+// All returns the registered factories sorted by name
+func All() []Factory {
+	mu.RLock()
+	defer mu.RUnlock()
+
+	out := make([]Factory, len(factories))
+	copy(out, factories)
+	return out
+}
+
+// Names returns the names of all registered modules
+func Names() []string {
+	all := All()
+	names := make([]string, 0, len(all))
+	for _, f := range all {
+		names = append(names, f.Name)
+	}
+	return names
+}
+
+// Build creates an instance of every module enabled in cfg
+func Build(cfg *config.Config, logger *zap.Logger) ([]*Instance, error) {
+	var instances []*Instance
+	for _, f := range All() {
+		if !*f.Enabled(cfg) {
+			continue
+		}
+		instance, err := f.Build(cfg, logger)
+		if err != nil {
+			return nil, err
+		}
+		instances = append(instances, instance)
+	}
+	if err := CheckToolNames(instances); err != nil {
+		return nil, err
+	}
+	return instances, nil
+}
+
+// Rebuild creates an instance of every module enabled in cfg like Build, but
+// keeps the instance in previous of each module whose config section is the
+// same in previousCfg and cfg, so only the modules that changed reconnect
+// to their backend
+func Rebuild(previous []*Instance, previousCfg, cfg *config.Config, logger *zap.Logger) ([]*Instance, error) {
+	running := make(map[string]*Instance, len(previous))
+	for _, instance := range previous {
+		running[instance.Name] = instance
+	}
+
+	var instances []*Instance
+	for _, f := range All() {
+		if !*f.Enabled(cfg) {
+			continue
+		}
+		if instance, ok := running[f.Name]; ok && reflect.DeepEqual(previousCfg.Section(f.Section), cfg.Section(f.Section)) {
+			instances = append(instances, instance)
+			continue
+		}
+		instance, err := f.Build(cfg, logger)
+		if err != nil {
+			return nil, err
+		}
+		logger.Info("Module rebuilt from the new configuration", zap.String("module", f.Name))
+		instances = append(instances, instance)
+	}
+	if err := CheckToolNames(instances); err != nil {
+		return nil, err
+	}
+	return instances, nil
+}
+
+// CheckToolNames reports tools exposed under the same name by several
+// modules, which prefixes, suffixes and renames in tools.overrides can cause
+func CheckToolNames(instances []*Instance) error {
+	owners := make(map[string]string)
+	for _, instance := range instances {
+		for _, name := range instance.ToolNames() {
+			if owner, ok := owners[name]; ok {
+				return fmt.Errorf("tool %s is exposed by both the %s and %s modules", name, owner, instance.Name)
+			}
+			owners[name] = instance.Name
+		}
+	}
+	return nil
+}
+
+// Build creates a module instance from cfg and collects its tools and resources
+func (f Factory) Build(cfg *config.Config, logger *zap.Logger) (*Instance, error) {
+	module, err := f.New(cfg, logger)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create %s module: %w", f.Name, err)
+	}
+	instance := &Instance{
+		Factory: f,
+		Module:  module,
+		Tools:   module.GetTools(),
+	}
+	if provider, ok := module.(ResourceProvider); ok {
+		instance.Resources = provider.GetResources()
+		instance.ResourceTemplates = provider.GetResourceTemplates()
+	}
+	return instance, nil
+}
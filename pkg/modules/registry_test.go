@@ -0,0 +1,122 @@
+package modules
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/server"
+	"go.uber.org/zap"
+
+	"github.com/shaowenchen/ops-mcp-server/pkg/config"
+)
+
+// stubModule is a module without tools
+type stubModule struct{}
+
+func (stubModule) GetTools() []server.ServerTool         { return nil }
+func (stubModule) ToolNamesByDefault() map[string]string { return nil }
+func (stubModule) HealthCheck() error                    { return nil }
+
+// down lists the backends the stub modules fail to connect to
+var down = map[string]bool{}
+
+func init() {
+	Register(Factory{
+		Name:    "metrics",
+		Enabled: func(cfg *config.Config) *bool { return &cfg.Metrics.Enabled },
+		New: func(cfg *config.Config, logger *zap.Logger) (Module, error) {
+			if down[cfg.Metrics.Prometheus.Endpoint] {
+				return nil, errors.New("connection refused")
+			}
+			return stubModule{}, nil
+		},
+	})
+	Register(Factory{
+		Name:    "logs",
+		Enabled: func(cfg *config.Config) *bool { return &cfg.Logs.Enabled },
+		New: func(cfg *config.Config, logger *zap.Logger) (Module, error) {
+			if down[cfg.Logs.Elasticsearch.Endpoint] {
+				return nil, errors.New("connection refused")
+			}
+			return stubModule{}, nil
+		},
+	})
+}
+
+// stubConfig returns a configuration enabling both stub modules
+func stubConfig(prometheus, elasticsearch string) *config.Config {
+	cfg := &config.Config{}
+	cfg.Metrics.Enabled = true
+	cfg.Metrics.Prometheus = &config.PrometheusConfig{Endpoint: prometheus}
+	cfg.Logs.Enabled = true
+	cfg.Logs.Elasticsearch = &config.LogsElasticsearchConfig{Endpoint: elasticsearch}
+	return cfg
+}
+
+// instanceOf returns the instance of the named module
+func instanceOf(instances []*Instance, name string) *Instance {
+	for _, instance := range instances {
+		if instance.Name == name {
+			return instance
+		}
+	}
+	return nil
+}
+
+func TestRebuildKeepsUnchangedModules(t *testing.T) {
+	previousCfg := stubConfig("http://prometheus-a:9090", "http://elasticsearch:9200")
+	previous, err := Build(previousCfg, zap.NewNop())
+	if err != nil {
+		t.Fatalf("Build: %v", err)
+	}
+
+	// Elasticsearch is down, its unchanged module must not fail the reload
+	down["http://elasticsearch:9200"] = true
+	t.Cleanup(func() { delete(down, "http://elasticsearch:9200") })
+	cfg := stubConfig("http://prometheus-b:9090", "http://elasticsearch:9200")
+
+	instances, err := Rebuild(previous, previousCfg, cfg, zap.NewNop())
+	if err != nil {
+		t.Fatalf("Rebuild: %v", err)
+	}
+	if instanceOf(instances, "logs") != instanceOf(previous, "logs") {
+		t.Error("logs module rebuilt although its config did not change")
+	}
+	if instanceOf(instances, "metrics") == instanceOf(previous, "metrics") {
+		t.Error("metrics module kept although its config changed")
+	}
+}
+
+func TestRebuildFailsOnChangedModule(t *testing.T) {
+	previousCfg := stubConfig("http://prometheus:9090", "http://elasticsearch-a:9200")
+	previous, err := Build(previousCfg, zap.NewNop())
+	if err != nil {
+		t.Fatalf("Build: %v", err)
+	}
+
+	down["http://elasticsearch-b:9200"] = true
+	t.Cleanup(func() { delete(down, "http://elasticsearch-b:9200") })
+	cfg := stubConfig("http://prometheus:9090", "http://elasticsearch-b:9200")
+
+	if _, err := Rebuild(previous, previousCfg, cfg, zap.NewNop()); err == nil {
+		t.Error("Rebuild succeeded although the changed logs module failed to build")
+	}
+}
+
+func TestRebuildDropsDisabledModules(t *testing.T) {
+	previousCfg := stubConfig("http://prometheus:9090", "http://elasticsearch:9200")
+	previous, err := Build(previousCfg, zap.NewNop())
+	if err != nil {
+		t.Fatalf("Build: %v", err)
+	}
+
+	cfg := stubConfig("http://prometheus:9090", "http://elasticsearch:9200")
+	cfg.Logs.Enabled = false
+	instances, err := Rebuild(previous, previousCfg, cfg, zap.NewNop())
+	if err != nil {
+		t.Fatalf("Rebuild: %v", err)
+	}
+	if len(instances) != 1 || instances[0] != instanceOf(previous, "metrics") {
+		t.Errorf("rebuilt %d instances, want the running metrics instance only", len(instances))
+	}
+}
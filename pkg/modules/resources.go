@@ -0,0 +1,53 @@
+package modules
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// ToolResource returns a resource handler serving the JSON a tool handler
+// returns. arguments maps the read request, including the variables of a
+// URI template, to the tool arguments.
+func ToolResource(handler server.ToolHandlerFunc, arguments func(request mcp.ReadResourceRequest) map[string]any) server.ResourceHandlerFunc {
+	return func(ctx context.Context, request mcp.ReadResourceRequest) ([]mcp.ResourceContents, error) {
+		call := mcp.CallToolRequest{}
+		call.Params.Arguments = arguments(request)
+		result, err := handler(ctx, call)
+		if err != nil {
+			return nil, err
+		}
+
+		var text strings.Builder
+		for _, content := range result.Content {
+			if textContent, ok := content.(mcp.TextContent); ok {
+				text.WriteString(textContent.Text)
+			}
+		}
+		if result.IsError {
+			return nil, fmt.Errorf("%s", text.String())
+		}
+		return []mcp.ResourceContents{
+			mcp.TextResourceContents{
+				URI:      request.Params.URI,
+				MIMEType: "application/json",
+				Text:     text.String(),
+			},
+		}, nil
+	}
+}
+
+// ResourceArgument returns the value of a URI template variable, empty when
+// the URI does not set it
+func ResourceArgument(request mcp.ReadResourceRequest, name string) string {
+	switch value := request.Params.Arguments[name].(type) {
+	case string:
+		return value
+	case []string:
+		return strings.Join(value, ",")
+	}
+	return ""
+}
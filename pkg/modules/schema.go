@@ -0,0 +1,54 @@
+package modules
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+
+	"github.com/google/jsonschema-go/jsonschema"
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// TypeSchemas overrides the schemas inferred for Go types that marshal to
+// another shape than their fields, like Prometheus [timestamp, "value"] pairs
+type TypeSchemas map[reflect.Type]*jsonschema.Schema
+
+// AnyJSON is the schema of a value that can be any JSON, used for
+// json.RawMessage and other pass-through backend payloads
+var AnyJSON = &jsonschema.Schema{}
+
+// OutputSchema returns the tool option declaring the JSON schema of T as the
+// structured content of the tool results. The schema is inferred from the
+// JSON fields of T, with overrides for the types it cannot describe.
+func OutputSchema[T any](overrides TypeSchemas) mcp.ToolOption {
+	schemas := TypeSchemas{reflect.TypeFor[json.RawMessage](): AnyJSON}
+	for t, schema := range overrides {
+		schemas[t] = schema
+	}
+	schema, err := jsonschema.For[T](&jsonschema.ForOptions{IgnoreInvalidTypes: true, TypeSchemas: schemas})
+	if err != nil {
+		panic(fmt.Sprintf("infer output schema of %s: %v", reflect.TypeFor[T](), err))
+	}
+	if schema.Type != "object" {
+		panic(fmt.Sprintf("output schema of %s must be an object, not %q", reflect.TypeFor[T](), schema.Type))
+	}
+	data, err := json.Marshal(schema)
+	if err != nil {
+		panic(fmt.Sprintf("marshal output schema of %s: %v", reflect.TypeFor[T](), err))
+	}
+	return mcp.WithRawOutputSchema(data)
+}
+
+// StructuredResult returns a tool result carrying data as structured content
+// and text as its rendering for clients that only read text content. An
+// empty text renders data as compact JSON.
+func StructuredResult(data any, text string) (*mcp.CallToolResult, error) {
+	if text == "" {
+		compact, err := json.Marshal(data)
+		if err != nil {
+			return nil, fmt.Errorf("failed to marshal response: %w", err)
+		}
+		text = string(compact)
+	}
+	return mcp.NewToolResultStructured(data, text), nil
+}
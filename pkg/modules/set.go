@@ -0,0 +1,214 @@
+package modules
+
+import (
+	"context"
+	"fmt"
+	"sync/atomic"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// Set holds the module instances currently serving tool calls. Swap replaces
+// them atomically on configuration reload, calls already running finish on
+// the instances they started with.
+type Set struct {
+	current atomic.Pointer[snapshot]
+}
+
+// snapshot is an immutable view of the instances with their tools and
+// resources indexed
+type snapshot struct {
+	instances []*Instance
+	tools     map[string]server.ServerTool
+	modules   map[string]string
+	resources map[string]server.ServerResource
+	templates map[string]server.ServerResourceTemplate
+	// resourceModules maps resource URIs and raw URI templates to their module
+	resourceModules map[string]string
+	// toolNames maps module names to the exposed names of their tools by default name
+	toolNames map[string]map[string]string
+}
+
+// NewSet creates a set serving the given instances
+func NewSet(instances []*Instance) *Set {
+	s := &Set{}
+	s.Swap(instances)
+	return s
+}
+
+// Swap replaces the instances and returns the previous ones
+func (s *Set) Swap(instances []*Instance) []*Instance {
+	next := &snapshot{
+		instances: instances,
+		tools:     make(map[string]server.ServerTool),
+		modules:   make(map[string]string),
+		resources: make(map[string]server.ServerResource),
+		templates: make(map[string]server.ServerResourceTemplate),
+
+		resourceModules: make(map[string]string),
+		toolNames:       make(map[string]map[string]string),
+	}
+	for _, instance := range instances {
+		next.toolNames[instance.Name] = instance.Module.ToolNamesByDefault()
+		for _, tool := range instance.Tools {
+			next.tools[tool.Tool.Name] = tool
+			next.modules[tool.Tool.Name] = instance.Name
+		}
+		for _, resource := range instance.Resources {
+			next.resources[resource.Resource.URI] = resource
+			next.resourceModules[resource.Resource.URI] = instance.Name
+		}
+		for _, template := range instance.ResourceTemplates {
+			raw := template.Template.URITemplate.Raw()
+			next.templates[raw] = template
+			next.resourceModules[raw] = instance.Name
+		}
+	}
+	if previous := s.current.Swap(next); previous != nil {
+		return previous.instances
+	}
+	return nil
+}
+
+// Instances returns the current instances sorted by module name
+func (s *Set) Instances() []*Instance {
+	return s.current.Load().instances
+}
+
+// ModuleOf returns the module exposing tool, empty when no module does
+func (s *Set) ModuleOf(tool string) string {
+	return s.current.Load().modules[tool]
+}
+
+// ToolName returns the name the tool of module with the given default name is
+// exposed under, empty when the tool is disabled or module is not enabled.
+// All modules are considered when enabled is nil.
+func (s *Set) ToolName(enabled map[string]bool, module, tool string) string {
+	if enabled != nil && !enabled[module] {
+		return ""
+	}
+	return s.current.Load().toolNames[module][tool]
+}
+
+// CompleteArgument returns the candidate values of the argument name of
+// module, nil when the module is not enabled or does not complete it. All
+// modules are considered when enabled is nil.
+func (s *Set) CompleteArgument(ctx context.Context, enabled map[string]bool, module, name string, args map[string]string) ([]string, error) {
+	if enabled != nil && !enabled[module] {
+		return nil, nil
+	}
+	for _, instance := range s.Instances() {
+		if instance.Name != module {
+			continue
+		}
+		if completer, ok := instance.Module.(Completer); ok {
+			return completer.CompleteArgument(ctx, name, args)
+		}
+	}
+	return nil, nil
+}
+
+// ModuleOfResource returns the module exposing the resource at uri, matching
+// URI templates when no resource has that exact URI. It is empty when no
+// module exposes uri.
+func (s *Set) ModuleOfResource(uri string) string {
+	current := s.current.Load()
+	if module, ok := current.resourceModules[uri]; ok {
+		return module
+	}
+	for raw, template := range current.templates {
+		if template.Template.URITemplate.Regexp().MatchString(uri) {
+			return current.resourceModules[raw]
+		}
+	}
+	return ""
+}
+
+// Tools returns the tools of the enabled modules, all modules when enabled
+// is nil. Their handlers dispatch to whichever instance is current when the
+// call arrives, so MCP servers only need updating when definitions change.
+func (s *Set) Tools(enabled map[string]bool) []server.ServerTool {
+	var tools []server.ServerTool
+	for _, instance := range s.Instances() {
+		if enabled != nil && !enabled[instance.Name] {
+			continue
+		}
+		for _, tool := range instance.Tools {
+			tools = append(tools, server.ServerTool{
+				Tool:    tool.Tool,
+				Handler: s.dispatch(tool.Tool.Name),
+			})
+		}
+	}
+	return tools
+}
+
+// dispatch returns a handler calling the current handler of the named tool
+func (s *Set) dispatch(name string) server.ToolHandlerFunc {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		tool, ok := s.current.Load().tools[name]
+		if !ok {
+			return nil, fmt.Errorf("tool %s is no longer available", name)
+		}
+		return tool.Handler(ctx, request)
+	}
+}
+
+// Resources returns the resources of the enabled modules, all modules when
+// enabled is nil, with handlers dispatching to the current instances
+func (s *Set) Resources(enabled map[string]bool) []server.ServerResource {
+	var resources []server.ServerResource
+	for _, instance := range s.Instances() {
+		if enabled != nil && !enabled[instance.Name] {
+			continue
+		}
+		for _, resource := range instance.Resources {
+			resources = append(resources, server.ServerResource{
+				Resource: resource.Resource,
+				Handler:  s.dispatchResource(resource.Resource.URI),
+			})
+		}
+	}
+	return resources
+}
+
+// ResourceTemplates returns the resource templates of the enabled modules,
+// all modules when enabled is nil, with handlers dispatching to the current instances
+func (s *Set) ResourceTemplates(enabled map[string]bool) []server.ServerResourceTemplate {
+	var templates []server.ServerResourceTemplate
+	for _, instance := range s.Instances() {
+		if enabled != nil && !enabled[instance.Name] {
+			continue
+		}
+		for _, template := range instance.ResourceTemplates {
+			templates = append(templates, server.ServerResourceTemplate{
+				Template: template.Template,
+				Handler:  s.dispatchResourceTemplate(template.Template.URITemplate.Raw()),
+			})
+		}
+	}
+	return templates
+}
+
+// dispatchResource returns a handler calling the current handler of the resource at uri
+func (s *Set) dispatchResource(uri string) server.ResourceHandlerFunc {
+	return func(ctx context.Context, request mcp.ReadResourceRequest) ([]mcp.ResourceContents, error) {
+		resource, ok := s.current.Load().resources[uri]
+		if !ok {
+			return nil, fmt.Errorf("resource %s is no longer available", uri)
+		}
+		return resource.Handler(ctx, request)
+	}
+}
+
+// dispatchResourceTemplate returns a handler calling the current handler of the raw URI template
+func (s *Set) dispatchResourceTemplate(raw string) server.ResourceTemplateHandlerFunc {
+	return func(ctx context.Context, request mcp.ReadResourceRequest) ([]mcp.ResourceContents, error) {
+		template, ok := s.current.Load().templates[raw]
+		if !ok {
+			return nil, fmt.Errorf("resource template %s is no longer available", raw)
+		}
+		return template.Handler(ctx, request)
+	}
+}
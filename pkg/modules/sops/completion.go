@@ -0,0 +1,16 @@
+package sops
+
+import "context"
+
+// CompleteArgument suggests the IDs of the SOPS procedures loaded from the API
+func (m *Module) CompleteArgument(ctx context.Context, name string, args map[string]string) ([]string, error) {
+	if name != "sops_id" && name != "id" {
+		return nil, nil
+	}
+	sops := m.catalog()
+	ids := make([]string, 0, len(sops))
+	for id := range sops {
+		ids = append(ids, id)
+	}
+	return ids, nil
+}
@@ -0,0 +1,202 @@
+package sops
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/shaowenchen/ops-mcp-server/pkg/modules"
+	opsv1 "github.com/shaowenchen/ops/api/v1"
+)
+
+// dangerousLabel marks pipelines whose execution the user must confirm,
+// set as a label or an annotation with the value "true"
+const dangerousLabel = "ops/dangerous"
+
+// confirmProperty is the elicited field confirming a dangerous procedure
+const confirmProperty = "confirm_execution"
+
+// isDangerous reports whether the pipeline requires confirmation, from its
+// labels, annotations or the configured list of dangerous SOPS
+func (m *Module) isDangerous(pipeline opsv1.Pipeline) bool {
+	return pipeline.Labels[dangerousLabel] == "true" ||
+		pipeline.Annotations[dangerousLabel] == "true" ||
+		slices.Contains(m.config.Dangerous, pipeline.Name)
+}
+
+// confirmExecution elicits the variables that are missing or invalid and,
+// for dangerous procedures, an explicit confirmation. It returns the
+// parameters to run with, or the result to return when the user declined.
+func (m *Module) confirmExecution(ctx context.Context, sopsID string, sops *SOPSConfig, parameters map[string]interface{}) (map[string]interface{}, *mcp.CallToolResult, error) {
+	missing := invalidVariables(sops.Variables, parameters)
+	if len(missing) == 0 && !sops.Dangerous {
+		return parameters, nil, nil
+	}
+
+	session, ok := modules.ElicitationSession(ctx)
+	if !ok {
+		if len(missing) > 0 {
+			return nil, nil, fmt.Errorf("SOPS '%s' has missing or invalid variables: %s, see its parameters for the allowed values",
+				sopsID, strings.Join(missing, ", "))
+		}
+		return nil, nil, fmt.Errorf("SOPS '%s' is dangerous and must be confirmed by the user, but the client does not support elicitation", sopsID)
+	}
+
+	request := mcp.ElicitationRequest{
+		Params: mcp.ElicitationParams{
+			Message:         elicitationMessage(sopsID, sops, parameters, missing),
+			RequestedSchema: elicitationSchema(sops, missing),
+		},
+	}
+	request.Method = string(mcp.MethodElicitationCreate)
+	result, err := session.RequestElicitation(ctx, request)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to ask the user to confirm SOPS '%s': %w", sopsID, err)
+	}
+	if result.Action != mcp.ElicitationResponseActionAccept {
+		return nil, cancelledResult(sopsID, string(result.Action)), nil
+	}
+
+	content, _ := result.Content.(map[string]interface{})
+	if sops.Dangerous {
+		if confirmed, _ := content[confirmProperty].(bool); !confirmed {
+			return nil, cancelledResult(sopsID, "not confirmed"), nil
+		}
+	}
+	next := make(map[string]interface{}, len(parameters)+len(missing))
+	for k, v := range parameters {
+		next[k] = v
+	}
+	for _, name := range missing {
+		if value, ok := content[name]; ok && value != "" {
+			next[name] = value
+		}
+	}
+	if invalid := invalidVariables(sops.Variables, next); len(invalid) > 0 {
+		return nil, nil, fmt.Errorf("SOPS '%s' still has missing or invalid variables: %s", sopsID, strings.Join(invalid, ", "))
+	}
+	return next, nil, nil
+}
+
+// invalidVariables returns the sorted names of the variables that are
+// required but unset, or whose value is not one of their enums or does not
+// match their regex. Unset variables fall back to their value or default.
+func invalidVariables(variables opsv1.Variables, parameters map[string]interface{}) []string {
+	var invalid []string
+	for name, variable := range variables {
+		variable.Value = variableValue(variable, parameters[name])
+		if variable.Value == "" && !variable.Required {
+			continue
+		}
+		if !variable.Validate() {
+			invalid = append(invalid, name)
+		}
+	}
+	sort.Strings(invalid)
+	return invalid
+}
+
+// variableValue returns the value a parameter sets, or the value or default
+// of the variable when the parameter is unset
+func variableValue(variable opsv1.Variable, parameter interface{}) string {
+	if parameter != nil {
+		if value := fmt.Sprintf("%v", parameter); value != "" {
+			return value
+		}
+	}
+	return variable.GetValue()
+}
+
+// elicitationMessage describes the procedure and the variables it runs with
+func elicitationMessage(sopsID string, sops *SOPSConfig, parameters map[string]interface{}, missing []string) string {
+	var message strings.Builder
+	fmt.Fprintf(&message, "SOPS '%s' is about to run", sopsID)
+	if sops.Desc != "" {
+		fmt.Fprintf(&message, ": %s", sops.Desc)
+	}
+	message.WriteString(".")
+
+	names := make([]string, 0, len(parameters))
+	for name := range parameters {
+		if !slices.Contains(missing, name) {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	if len(names) > 0 {
+		message.WriteString("\nVariables:")
+		for _, name := range names {
+			fmt.Fprintf(&message, "\n- %s = %v", name, parameters[name])
+		}
+	}
+	if len(missing) > 0 {
+		message.WriteString("\nPlease fill in the missing or invalid variables.")
+	}
+	if sops.Dangerous {
+		message.WriteString("\nThis procedure is marked dangerous, confirm to run it.")
+	}
+	return message.String()
+}
+
+// elicitationSchema returns the form asking for the missing variables and,
+// for dangerous procedures, the confirmation. Elicitation schemas have no
+// pattern, so regexes are described and checked once the user answered.
+func elicitationSchema(sops *SOPSConfig, missing []string) map[string]interface{} {
+	properties := make(map[string]interface{})
+	required := make([]string, 0, len(missing)+1)
+	for _, name := range missing {
+		variable := sops.Variables[name]
+		description := variable.Desc
+		if variable.Regex != "" {
+			description = strings.TrimSpace(fmt.Sprintf("%s (must match %s)", description, variable.Regex))
+		}
+		property := map[string]interface{}{
+			"type":  "string",
+			"title": name,
+		}
+		if variable.Display != "" {
+			property["title"] = variable.Display
+		}
+		if description != "" {
+			property["description"] = description
+		}
+		if len(variable.Enums) > 0 {
+			property["enum"] = variable.Enums
+		}
+		if value := variable.GetValue(); value != "" {
+			property["default"] = value
+		}
+		properties[name] = property
+		if variable.Required {
+			required = append(required, name)
+		}
+	}
+	if sops.Dangerous {
+		properties[confirmProperty] = map[string]interface{}{
+			"type":        "boolean",
+			"title":       "Run this procedure",
+			"description": "Confirm that this dangerous procedure should run now",
+			"default":     false,
+		}
+		required = append(required, confirmProperty)
+	}
+	return map[string]interface{}{
+		"type":       "object",
+		"properties": properties,
+		"required":   required,
+	}
+}
+
+// cancelledResult returns the result telling the model the user did not run the procedure
+func cancelledResult(sopsID, reason string) *mcp.CallToolResult {
+	result, _ := modules.StructuredResult(SOPSRunResult{
+		SopsID:  sopsID,
+		Status:  "cancelled",
+		Reason:  reason,
+		Message: "The user did not approve running this SOPS procedure, do not retry unless asked to",
+	}, "")
+	return result
+}
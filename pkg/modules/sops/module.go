@@ -7,12 +7,18 @@ import (
 	"io"
 	"net"
 	"net/http"
+	"net/url"
+	"reflect"
+	"sort"
 	"strings"
+	"sync/atomic"
 	"time"
 
 	"github.com/mark3labs/mcp-go/mcp"
 	"github.com/mark3labs/mcp-go/server"
 	"github.com/shaowenchen/ops-copilot/pkg/copilot"
+	"github.com/shaowenchen/ops-mcp-server/pkg/audit"
+	"github.com/shaowenchen/ops-mcp-server/pkg/modules"
 	opsv1 "github.com/shaowenchen/ops/api/v1"
 	"github.com/shaowenchen/ops/pkg/log"
 	"go.uber.org/zap"
@@ -109,7 +115,10 @@ type Module struct {
 	config     *Config
 	logger     *zap.Logger
 	httpClient *http.Client
-	sops       map[string]*SOPSConfig
+	// sops holds the procedures by ID, replaced as a whole when they are
+	// listed again while calls read them
+	sops  atomic.Pointer[map[string]*SOPSConfig]
+	tools SOPSToolsConfig
 }
 
 // New creates a new sops module instance
@@ -134,13 +143,17 @@ func New(config *Config, logger *zap.Logger) (*Module, error) {
 	module := &Module{
 		config: config,
 		logger: logger,
-		sops:   make(map[string]*SOPSConfig),
 		httpClient: &http.Client{
 			Transport: transport,
 			Timeout:   120 * time.Second, // SOPS operations may take longer
 		},
 	}
 
+	module.tools = GetDefaultToolsConfig()
+	if err := modules.ApplyToolOverrides("sops", module.tools.toolConfigs(), module.config.Tools.Overrides, module.BuildToolName, module.logger); err != nil {
+		return nil, err
+	}
+
 	// Load SOPS configurations from API only if endpoint is configured
 	if config.Endpoint != "" {
 		if err := module.loadSOPSConfigsFromAPI(); err != nil {
@@ -155,19 +168,56 @@ func New(config *Config, logger *zap.Logger) (*Module, error) {
 
 // GetTools returns the list of available tools
 func (m *Module) GetTools() []server.ServerTool {
-	// Get default tool configuration
-	toolsConfig := GetDefaultToolsConfig()
+	return m.BuildTools(m.tools)
+}
 
-	// Tool configuration can be modified based on config file or other conditions
-	// For example: disable certain tools based on m.config
+// GetResources returns the SOPS catalog, exposed while the list SOPS tool is enabled
+func (m *Module) GetResources() []server.ServerResource {
+	if !m.tools.ListSOPS.Enabled {
+		return nil
+	}
+	return []server.ServerResource{
+		{
+			Resource: mcp.NewResource("ops://sops", "SOPS procedures",
+				mcp.WithResourceDescription("Standard operation procedures with their descriptions and variables"),
+				mcp.WithMIMEType("application/json")),
+			Handler: modules.ToolResource(m.handleListSOPS, func(mcp.ReadResourceRequest) map[string]any {
+				return map[string]any{}
+			}),
+		},
+	}
+}
 
-	return m.BuildTools(toolsConfig)
+// GetResourceTemplates returns the parameters of a single SOPS procedure,
+// exposed while the get parameters tool is enabled
+func (m *Module) GetResourceTemplates() []server.ServerResourceTemplate {
+	if !m.tools.GetParameters.Enabled {
+		return nil
+	}
+	return []server.ServerResourceTemplate{
+		{
+			Template: mcp.NewResourceTemplate("ops://sops/{id}", "SOPS procedure parameters",
+				mcp.WithTemplateDescription("Parameters of the SOPS procedure id, listed in ops://sops"),
+				mcp.WithTemplateMIMEType("application/json")),
+			Handler: server.ResourceTemplateHandlerFunc(modules.ToolResource(m.handleGetSOPSParameters, func(request mcp.ReadResourceRequest) map[string]any {
+				return map[string]any{"sops_id": modules.ResourceArgument(request, "id")}
+			})),
+		},
+	}
+}
+
+// HealthCheck reports whether the SOPS API is configured
+func (m *Module) HealthCheck() error {
+	if m.config.Endpoint == "" {
+		return fmt.Errorf("SOPS API endpoint not configured - please set sops.ops.endpoint in config")
+	}
+	return nil
 }
 
 // fetchPipelinesFromOpsAPI lists pipelines using the same path as ops-copilot but tolerates
 // multiple JSON shapes (data.list vs data.items vs Kubernetes PipelineList).
 func (m *Module) fetchPipelinesFromOpsAPI(ctx context.Context) ([]opsv1.Pipeline, error) {
-	const uri = "/api/v1/namespaces/ops-system/pipelines?labels_selector=ops/copilot=enabled&page_size=999"
+	uri := "/api/v1/namespaces/" + url.PathEscape(m.config.Namespace) + "/pipelines?labels_selector=ops/copilot=enabled&page_size=999"
 	base := strings.TrimRight(m.config.Endpoint, "/")
 	req, err := http.NewRequestWithContext(ctx, http.MethodGet, base+uri, nil)
 	if err != nil {
@@ -210,9 +260,18 @@ func (m *Module) rebuildSopsFromPipelines(pipelines []opsv1.Pipeline) {
 		next[pipeline.Name] = &SOPSConfig{
 			Desc:      pipeline.Spec.Desc,
 			Variables: pipeline.Spec.Variables,
+			Dangerous: m.isDangerous(pipeline),
 		}
 	}
-	m.sops = next
+	m.sops.Store(&next)
+}
+
+// catalog returns the procedures loaded from the API by ID
+func (m *Module) catalog() map[string]*SOPSConfig {
+	if sops := m.sops.Load(); sops != nil {
+		return *sops
+	}
+	return nil
 }
 
 // loadSOPSConfigsFromAPI loads SOPS configurations from the API endpoint
@@ -246,53 +305,120 @@ func (m *Module) handleExecuteSOPS(ctx context.Context, request mcp.CallToolRequ
 	}
 
 	// Get SOPS configuration
-	sops, exists := m.sops[sopsID]
+	catalog := m.catalog()
+	sops, exists := catalog[sopsID]
 	if !exists {
 		// Return available SOPS IDs
-		availableIDs := make([]string, 0, len(m.sops))
-		for id := range m.sops {
+		availableIDs := make([]string, 0, len(catalog))
+		for id := range catalog {
 			availableIDs = append(availableIDs, id)
 		}
 		return nil, fmt.Errorf("SOPS with ID '%s' not found. Available SOPS IDs: %v", sopsID, availableIDs)
 	}
 
-	parameters := collectExecuteSOPSVariables(args)
+	requested := collectExecuteSOPSVariables(args)
+
+	// Ask the user for missing variables and confirmation of dangerous procedures
+	parameters, cancelled, err := m.confirmExecution(ctx, sopsID, sops, requested)
+	if err != nil {
+		return nil, err
+	}
+	if cancelled != nil {
+		return cancelled, nil
+	}
+	if !reflect.DeepEqual(parameters, requested) {
+		executed := map[string]interface{}{"sops_id": sopsID}
+		for k, v := range parameters {
+			executed[k] = v
+		}
+		audit.SetExecutedArguments(ctx, executed)
+	}
 
 	// Execute SOPS
-	executionJSON, err := m.executeSOPS(ctx, sopsID, sops, parameters)
+	pr, err := m.executeSOPS(ctx, sopsID, parameters)
 	if err != nil {
 		return nil, fmt.Errorf("failed to execute SOPS: %w", err)
 	}
 
-	return &mcp.CallToolResult{
-		Content: []mcp.Content{
-			mcp.NewTextContent(executionJSON),
-		},
-	}, nil
+	return modules.StructuredResult(pipelineRunResult(sopsID, pr), (&copilot.PipelineRunsManager{}).PrintMarkdownPipelineRuns(pr))
 }
 
-// executeSOPS executes a SOPS procedure via API
-func (m *Module) executeSOPS(ctx context.Context, sopsID string, sops *SOPSConfig, parameters map[string]interface{}) (string, error) {
-	pipelinerunsManager, err := copilot.NewPipelineRunsManager(m.config.Endpoint, m.config.Token, "ops-system")
-	if err != nil {
-		return "", fmt.Errorf("failed to create pipeline runs manager: %w", err)
-	}
+// executeSOPS runs the pipeline of the SOPS procedure with the synchronous
+// pipeline run API of the Ops server, which answers once the run finished,
+// and reports progress while it waits. The ops-copilot client takes no
+// context, so ctx only keeps a cancelled call from starting the pipeline.
+func (m *Module) executeSOPS(ctx context.Context, sopsID string, parameters map[string]interface{}) (*opsv1.PipelineRun, error) {
 	variables := make(map[string]string)
 	for k, v := range parameters {
 		variables[k] = fmt.Sprintf("%v", v)
 	}
-	logger := log.NewLogger()
 	pr := &opsv1.PipelineRun{
 		Spec: opsv1.PipelineRunSpec{
 			PipelineRef: sopsID,
 			Variables:   variables,
 		},
 	}
-	err = pipelinerunsManager.Run(logger, pr)
+
+	// The Ops API runs the pipeline within a single request that takes no
+	// context, so a call cancelled once the run started waits for its end
+	if err := ctx.Err(); err != nil {
+		return nil, fmt.Errorf("pipeline %s not started: %w", sopsID, err)
+	}
+	done := modules.ProgressFromContext(ctx).Wait(fmt.Sprintf("Running pipeline %s", sopsID))
+	defer done()
+	pipelinerunsManager, err := copilot.NewPipelineRunsManager(m.config.Endpoint, m.config.Token, m.config.Namespace)
 	if err != nil {
-		return "", fmt.Errorf("failed to run pipeline: %w", err)
+		return nil, fmt.Errorf("failed to create pipeline runs manager: %w", err)
+	}
+	if err := pipelinerunsManager.Run(log.NewLogger().Build(), pr); err != nil {
+		return nil, fmt.Errorf("failed to run pipeline: %w", err)
+	}
+	return pr, nil
+}
+
+// pipelineRunResult returns the task, node and step statuses of a finished
+// pipeline run
+func pipelineRunResult(sopsID string, pr *opsv1.PipelineRun) SOPSRunResult {
+	result := SOPSRunResult{
+		SopsID:      sopsID,
+		PipelineRun: pr.Name,
+		Status:      orPending(pr.Status.RunStatus),
+		Tasks:       make([]SOPSTaskRun, 0, len(pr.Status.PipelineRunStatus)),
+	}
+	for _, task := range pr.Status.PipelineRunStatus {
+		taskRun := SOPSTaskRun{Name: task.TaskName, TaskRef: task.TaskRef, Status: "Pending"}
+		if task.TaskRunStatus != nil {
+			taskRun.Status = orPending(task.TaskRunStatus.RunStatus)
+			nodes := make([]string, 0, len(task.TaskRunStatus.TaskRunNodeStatus))
+			for node := range task.TaskRunStatus.TaskRunNodeStatus {
+				nodes = append(nodes, node)
+			}
+			sort.Strings(nodes)
+			for _, node := range nodes {
+				nodeStatus := task.TaskRunStatus.TaskRunNodeStatus[node]
+				if nodeStatus == nil {
+					continue
+				}
+				nodeRun := SOPSNodeRun{Node: node, Status: nodeStatus.RunStatus}
+				for _, step := range nodeStatus.TaskRunStep {
+					if step != nil {
+						nodeRun.Steps = append(nodeRun.Steps, SOPSStepRun{Name: step.StepName, Status: step.StepStatus, Output: step.StepOutput})
+					}
+				}
+				taskRun.Nodes = append(taskRun.Nodes, nodeRun)
+			}
+		}
+		result.Tasks = append(result.Tasks, taskRun)
 	}
-	return fmt.Sprintf("%s", pipelinerunsManager.PrintMarkdownPipelineRuns(pr)), nil
+	return result
+}
+
+// orPending returns status, or Pending when the run has no status yet
+func orPending(status string) string {
+	if status == "" {
+		return "Pending"
+	}
+	return status
 }
 
 // handleListSOPS handles listing all available SOPS procedures
@@ -309,32 +435,23 @@ func (m *Module) handleListSOPS(ctx context.Context, request mcp.CallToolRequest
 	}
 	m.rebuildSopsFromPipelines(pipelines)
 
-	sopsList := make([]map[string]interface{}, 0, len(pipelines))
+	sopsList := make([]SOPSInfo, 0, len(pipelines))
 	for _, pipeline := range pipelines {
 		if pipeline.Name == "" {
 			continue
 		}
-		sopsList = append(sopsList, map[string]interface{}{
-			"id":          pipeline.Name,
-			"description": pipeline.Spec.Desc,
-			"variables":   pipeline.Spec.Variables,
+		sopsList = append(sopsList, SOPSInfo{
+			ID:          pipeline.Name,
+			Description: pipeline.Spec.Desc,
+			Variables:   pipeline.Spec.Variables,
+			Dangerous:   m.isDangerous(pipeline),
 		})
 	}
 
-	// Convert to JSON
-	sopsJSON, err := json.MarshalIndent(map[string]interface{}{
-		"available_sops": sopsList,
-		"count":          len(sopsList),
-	}, "", "  ")
-	if err != nil {
-		return nil, fmt.Errorf("failed to marshal SOPS list: %w", err)
-	}
-
-	return &mcp.CallToolResult{
-		Content: []mcp.Content{
-			mcp.NewTextContent(string(sopsJSON)),
-		},
-	}, nil
+	return modules.StructuredResult(SOPSListResponse{
+		AvailableSops: sopsList,
+		Count:         len(sopsList),
+	}, "")
 }
 
 // handleGetSOPSParameters returns the parameter schema for a specific SOPS procedure.
@@ -350,56 +467,37 @@ func (m *Module) handleGetSOPSParameters(ctx context.Context, request mcp.CallTo
 	}
 
 	// Get SOPS configuration
-	sops, exists := m.sops[sopsID]
+	catalog := m.catalog()
+	sops, exists := catalog[sopsID]
 	if !exists {
 		// Return available SOPS IDs
-		availableIDs := make([]string, 0, len(m.sops))
-		for id := range m.sops {
+		availableIDs := make([]string, 0, len(catalog))
+		for id := range catalog {
 			availableIDs = append(availableIDs, id)
 		}
 		return nil, fmt.Errorf("SOPS with ID '%s' not found. Available SOPS IDs: %v", sopsID, availableIDs)
 	}
 
 	// Extract parameters from variables
-	parameters := make([]map[string]interface{}, 0)
+	parameters := make([]SOPSParameter, 0, len(sops.Variables))
 	for name, variable := range sops.Variables {
-		param := map[string]interface{}{
-			"name":        name,
-			"description": variable.Desc,
-			"required":    variable.Required,
-			"display":     variable.Display,
-		}
-		if variable.Value != "" {
-			param["value"] = variable.Value
-		}
-		if variable.Default != "" {
-			param["default"] = variable.Default
-		}
-		if variable.Regex != "" {
-			param["regex"] = variable.Regex
-		}
-		if len(variable.Enums) > 0 {
-			param["enums"] = variable.Enums
-		}
-		if len(variable.Examples) > 0 {
-			param["examples"] = variable.Examples
-		}
-		parameters = append(parameters, param)
-	}
-
-	// Convert to JSON
-	paramsJSON, err := json.MarshalIndent(map[string]interface{}{
-		"sops_id":    sopsID,
-		"parameters": parameters,
-		"count":      len(parameters),
-	}, "", "  ")
-	if err != nil {
-		return nil, fmt.Errorf("failed to marshal parameters: %w", err)
+		parameters = append(parameters, SOPSParameter{
+			Name:        name,
+			Description: variable.Desc,
+			Required:    variable.Required,
+			Display:     variable.Display,
+			Value:       variable.Value,
+			Default:     variable.Default,
+			Regex:       variable.Regex,
+			Enums:       variable.Enums,
+			Examples:    variable.Examples,
+		})
 	}
 
-	return &mcp.CallToolResult{
-		Content: []mcp.Content{
-			mcp.NewTextContent(string(paramsJSON)),
-		},
-	}, nil
+	return modules.StructuredResult(SOPSParametersResponse{
+		SopsID:     sopsID,
+		Parameters: parameters,
+		Count:      len(parameters),
+		Dangerous:  sops.Dangerous,
+	}, "")
 }
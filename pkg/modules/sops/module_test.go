@@ -0,0 +1,265 @@
+package sops
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sync"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+	"go.uber.org/zap"
+
+	"github.com/shaowenchen/ops-mcp-server/pkg/audit"
+	"github.com/shaowenchen/ops-mcp-server/pkg/config"
+)
+
+// opsPipelines is a pipeline list of the Ops API with one SOPS procedure
+const opsPipelines = `{"data":{"list":[{"metadata":{"name":"restart-pod"},"spec":{"desc":"Restart a pod","variables":{"pod":{"required":true}}}}]}}`
+
+// opsPipelineRun is the answer of the synchronous pipeline run API
+const opsPipelineRun = `{"code":0,"data":{"metadata":{"name":"restart-pod-x7k2p"},"spec":{"pipelineRef":"restart-pod"},"status":{
+	"runStatus":"Successed",
+	"pipelineRunStatus":[{"name":"restart","taskRef":"restart-pod","taskRunStatus":{"runStatus":"Successed",
+		"taskrunNodeStatus":{"node-1":{"runStatus":"Successed","taskRunStep":[{"stepName":"delete","stepOutput":"pod deleted","stepStatus":"Successed"}]}}}}]}}}`
+
+// opsRequest is a request received by the fake Ops API
+type opsRequest struct {
+	method string
+	uri    string
+	auth   string
+	body   []byte
+}
+
+// newOpsServer starts a fake Ops API in namespace team-a that records the
+// requests it receives and answers pipeline runs with run
+func newOpsServer(t *testing.T, run http.HandlerFunc) (*httptest.Server, <-chan opsRequest) {
+	t.Helper()
+	requests := make(chan opsRequest, 16)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		requests <- opsRequest{method: r.Method, uri: r.URL.RequestURI(), auth: r.Header.Get("Authorization"), body: body}
+		switch r.URL.Path {
+		case "/api/v1/namespaces/team-a/pipelines":
+			io.WriteString(w, opsPipelines)
+		case "/api/v1/namespaces/team-a/clusters":
+			io.WriteString(w, `{"data":{"list":[]}}`)
+		case "/api/v1/namespaces/team-a/pipelineruns/sync":
+			run(w, r)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	t.Cleanup(server.Close)
+	return server, requests
+}
+
+// newTestModule creates a module against the fake Ops API
+func newTestModule(t *testing.T, endpoint string) *Module {
+	t.Helper()
+	m, err := New(&Config{Endpoint: endpoint, Token: "secret", Namespace: "team-a"}, zap.NewNop())
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	return m
+}
+
+// executeRequest returns an execute SOPS call of restart-pod
+func executeRequest() mcp.CallToolRequest {
+	var request mcp.CallToolRequest
+	request.Params.Arguments = map[string]any{"sops_id": "restart-pod", "pod": "web-1"}
+	return request
+}
+
+func TestExecuteSOPSRunsPipelineSynchronously(t *testing.T) {
+	server, requests := newOpsServer(t, func(w http.ResponseWriter, r *http.Request) {
+		io.WriteString(w, opsPipelineRun)
+	})
+	m := newTestModule(t, server.URL)
+
+	result, err := m.handleExecuteSOPS(context.Background(), executeRequest())
+	if err != nil {
+		t.Fatalf("handleExecuteSOPS: %v", err)
+	}
+
+	var run *opsRequest
+	for len(requests) > 0 {
+		request := <-requests
+		if request.auth != "Bearer secret" {
+			t.Errorf("%s %s sent Authorization %q, want the configured token", request.method, request.uri, request.auth)
+		}
+		if request.method == http.MethodPost {
+			run = &request
+		}
+	}
+	if run == nil {
+		t.Fatal("no pipeline run was requested")
+	}
+	if run.uri != "/api/v1/namespaces/team-a/pipelineruns/sync" {
+		t.Errorf("pipeline run requested at %s, want /api/v1/namespaces/team-a/pipelineruns/sync", run.uri)
+	}
+	var body map[string]any
+	if err := json.Unmarshal(run.body, &body); err != nil {
+		t.Fatalf("pipeline run body %s: %v", run.body, err)
+	}
+	wantBody := map[string]any{"pipelineRef": "restart-pod", "variables": map[string]any{"pod": "web-1"}}
+	if !reflect.DeepEqual(body, wantBody) {
+		t.Errorf("pipeline run body = %v, want the pipeline run spec %v", body, wantBody)
+	}
+
+	got, ok := result.StructuredContent.(SOPSRunResult)
+	if !ok {
+		t.Fatalf("structured content is %T, want SOPSRunResult", result.StructuredContent)
+	}
+	want := SOPSRunResult{
+		SopsID: "restart-pod",
+		Status: "Successed",
+		Tasks: []SOPSTaskRun{{
+			Name:    "restart",
+			TaskRef: "restart-pod",
+			Status:  "Successed",
+			Nodes: []SOPSNodeRun{{
+				Node:   "node-1",
+				Status: "Successed",
+				Steps:  []SOPSStepRun{{Name: "delete", Status: "Successed", Output: "pod deleted"}},
+			}},
+		}},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("result = %+v, want %+v", got, want)
+	}
+}
+
+func TestExecuteSOPSNotStartedWhenCancelled(t *testing.T) {
+	server, requests := newOpsServer(t, func(w http.ResponseWriter, r *http.Request) {
+		io.WriteString(w, opsPipelineRun)
+	})
+	m := newTestModule(t, server.URL)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err := m.executeSOPS(ctx, "restart-pod", map[string]interface{}{"pod": "web-1"})
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("executeSOPS error = %v, want the context error", err)
+	}
+	for len(requests) > 0 {
+		if request := <-requests; request.method == http.MethodPost {
+			t.Errorf("pipeline run requested at %s by a cancelled call", request.uri)
+		}
+	}
+}
+
+// Run with -race: listing replaces the procedures while calls read them
+func TestListSOPSConcurrentWithExecute(t *testing.T) {
+	server, requests := newOpsServer(t, func(w http.ResponseWriter, r *http.Request) {
+		io.WriteString(w, opsPipelineRun)
+	})
+	done := make(chan struct{})
+	defer close(done)
+	go func() {
+		for {
+			select {
+			case <-requests:
+			case <-done:
+				return
+			}
+		}
+	}()
+	m := newTestModule(t, server.URL)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(3)
+		go func() {
+			defer wg.Done()
+			if _, err := m.handleListSOPS(context.Background(), mcp.CallToolRequest{}); err != nil {
+				t.Errorf("handleListSOPS: %v", err)
+			}
+		}()
+		go func() {
+			defer wg.Done()
+			if _, err := m.handleExecuteSOPS(context.Background(), executeRequest()); err != nil {
+				t.Errorf("handleExecuteSOPS: %v", err)
+			}
+		}()
+		go func() {
+			defer wg.Done()
+			if ids, _ := m.CompleteArgument(context.Background(), "sops_id", nil); len(ids) != 1 {
+				t.Errorf("completed %v, want restart-pod", ids)
+			}
+		}()
+	}
+	wg.Wait()
+}
+
+// elicitingSession is a client session answering elicitations with content
+type elicitingSession struct {
+	content map[string]interface{}
+}
+
+func (s *elicitingSession) Initialize()                                         {}
+func (s *elicitingSession) Initialized() bool                                   { return true }
+func (s *elicitingSession) NotificationChannel() chan<- mcp.JSONRPCNotification { return nil }
+func (s *elicitingSession) SessionID() string                                   { return "test" }
+
+func (s *elicitingSession) RequestElicitation(ctx context.Context, request mcp.ElicitationRequest) (*mcp.ElicitationResult, error) {
+	result := &mcp.ElicitationResult{}
+	result.Action = mcp.ElicitationResponseActionAccept
+	result.Content = s.content
+	return result, nil
+}
+
+func TestExecuteSOPSAuditsElicitedVariables(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "audit.log")
+	auditLogger, err := audit.Init(config.AuditConfig{Enabled: true, Output: path}, zap.NewNop())
+	if err != nil {
+		t.Fatalf("audit.Init: %v", err)
+	}
+	defer auditLogger.Close()
+
+	server, requests := newOpsServer(t, func(w http.ResponseWriter, r *http.Request) {
+		io.WriteString(w, opsPipelineRun)
+	})
+	m := newTestModule(t, server.URL)
+	handler := audit.Middleware(func(string) string { return "sops" })(m.handleExecuteSOPS)
+
+	// The model left out the pod, the user names it when asked
+	var request mcp.CallToolRequest
+	request.Params.Name = "execute-sop"
+	request.Params.Arguments = map[string]any{"sops_id": "restart-pod"}
+	ctx := withSession(context.Background(), &elicitingSession{content: map[string]interface{}{"pod": "web-2"}})
+	if _, err := handler(ctx, request); err != nil {
+		t.Fatalf("execute-sop: %v", err)
+	}
+	for len(requests) > 0 {
+		<-requests
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("audit log: %v", err)
+	}
+	var record audit.Record
+	if err := json.Unmarshal(data, &record); err != nil {
+		t.Fatalf("audit record %q: %v", data, err)
+	}
+	if _, ok := record.Arguments["pod"]; ok {
+		t.Errorf("requested arguments audited as %v, want those of the model", record.Arguments)
+	}
+	want := map[string]interface{}{"sops_id": "restart-pod", "pod": "web-2"}
+	if !reflect.DeepEqual(record.ExecutedArguments, want) {
+		t.Errorf("executed arguments audited as %v, want %v", record.ExecutedArguments, want)
+	}
+}
+
+// withSession returns ctx with session as the client session of the call
+func withSession(ctx context.Context, session server.ClientSession) context.Context {
+	return server.NewMCPServer("test", "1.0.0").WithContext(ctx, session)
+}
@@ -0,0 +1,43 @@
+package sops
+
+import (
+	"github.com/shaowenchen/ops-mcp-server/pkg/config"
+	"github.com/shaowenchen/ops-mcp-server/pkg/modules"
+	"go.uber.org/zap"
+)
+
+func init() {
+	modules.Register(modules.Factory{
+		Name:    "sops",
+		Section: "sops",
+		Enabled: func(cfg *config.Config) *bool { return &cfg.Sops.Enabled },
+		New: func(cfg *config.Config, logger *zap.Logger) (modules.Module, error) {
+			return New(ConfigFrom(cfg), logger)
+		},
+	})
+}
+
+// defaultNamespace is the namespace of the Ops pipelines when ops.namespace
+// is not set
+const defaultNamespace = "ops-system"
+
+// ConfigFrom builds the sops module configuration from the server configuration
+func ConfigFrom(cfg *config.Config) *Config {
+	sopsConfig := &Config{
+		Tools: ToolsConfig{
+			Prefix:    cfg.Sops.Tools.Prefix,
+			Suffix:    cfg.Sops.Tools.Suffix,
+			Overrides: cfg.Sops.Tools.Overrides,
+		},
+		Namespace: defaultNamespace,
+		Dangerous: cfg.Sops.Dangerous,
+	}
+	if cfg.Sops.Ops != nil {
+		sopsConfig.Endpoint = cfg.Sops.Ops.Endpoint
+		sopsConfig.Token = cfg.Sops.Ops.Token
+		if cfg.Sops.Ops.Namespace != "" {
+			sopsConfig.Namespace = cfg.Sops.Ops.Namespace
+		}
+	}
+	return sopsConfig
+}
@@ -6,14 +6,11 @@ import (
 	"github.com/mark3labs/mcp-go/mcp"
 	"github.com/mark3labs/mcp-go/server"
 	"github.com/shaowenchen/ops-mcp-server/pkg/metrics"
+	"github.com/shaowenchen/ops-mcp-server/pkg/modules"
 )
 
 // ToolConfig defines configuration for a single tool
-type ToolConfig struct {
-	Name        string // Tool name
-	Description string // Tool description
-	Enabled     bool   // Whether the tool is enabled
-}
+type ToolConfig = modules.ToolConfig
 
 // SOPSToolsConfig defines configuration for all tools
 type SOPSToolsConfig struct {
@@ -118,12 +115,14 @@ func (m *Module) buildExecuteSOPSToolDefinition(config ToolConfig) mcp.Tool {
 		IdempotentHint:  mcp.ToBoolPtr(false),
 		OpenWorldHint:   mcp.ToBoolPtr(true),
 	}
+	modules.OutputSchema[SOPSRunResult](nil)(&tool)
 	return tool
 }
 
 func (m *Module) buildListSOPSToolDefinition(config ToolConfig) mcp.Tool {
 	return mcp.NewTool(m.BuildToolName(config.Name),
 		mcp.WithDescription(config.Description),
+		modules.OutputSchema[SOPSListResponse](nil),
 	)
 }
 
@@ -131,5 +130,18 @@ func (m *Module) buildGetSOPSParametersToolDefinition(config ToolConfig) mcp.Too
 	return mcp.NewTool(m.BuildToolName(config.Name),
 		mcp.WithDescription(config.Description),
 		mcp.WithString("sops_id", mcp.Required(), mcp.Description("ID of the SOPS procedure to get parameters for")),
+		modules.OutputSchema[SOPSParametersResponse](nil),
 	)
 }
+
+// toolConfigs returns every tool of the configuration
+func (c *SOPSToolsConfig) toolConfigs() []*ToolConfig {
+	return []*ToolConfig{&c.ExecuteSOPS, &c.ListSOPS, &c.GetParameters}
+}
+
+// ToolNamesByDefault maps the default name of each enabled tool to the name
+// it is exposed under, after overrides, prefix and suffix
+func (m *Module) ToolNamesByDefault() map[string]string {
+	defaults := GetDefaultToolsConfig()
+	return modules.ToolNamesByDefault(defaults.toolConfigs(), m.tools.toolConfigs(), m.BuildToolName)
+}
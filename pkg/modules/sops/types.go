@@ -1,6 +1,7 @@
 package sops
 
 import (
+	"github.com/shaowenchen/ops-mcp-server/pkg/config"
 	v1 "github.com/shaowenchen/ops/api/v1"
 )
 
@@ -8,17 +9,93 @@ import (
 type SOPSConfig struct {
 	Desc      string       `json:"desc,omitempty" yaml:"desc,omitempty"`
 	Variables v1.Variables `json:"variables,omitempty" yaml:"variables,omitempty"`
+	// Dangerous procedures run only after the user confirmed them
+	Dangerous bool `json:"dangerous,omitempty" yaml:"dangerous,omitempty"`
 }
 
 // Config contains sops module configuration
 type Config struct {
-	Endpoint string      `mapstructure:"endpoint" json:"endpoint" yaml:"endpoint"`
-	Token    string      `mapstructure:"token" json:"token" yaml:"token"`
-	Tools    ToolsConfig `mapstructure:"tools" json:"tools" yaml:"tools"`
+	Endpoint string `mapstructure:"endpoint" json:"endpoint" yaml:"endpoint"`
+	Token    string `mapstructure:"token" json:"token" yaml:"token"`
+	// Namespace is the namespace of the Ops pipelines and pipeline runs
+	Namespace string      `mapstructure:"namespace" json:"namespace" yaml:"namespace"`
+	Tools     ToolsConfig `mapstructure:"tools" json:"tools" yaml:"tools"`
+	// Dangerous lists the SOPS IDs the user must confirm before they run
+	Dangerous []string `mapstructure:"dangerous" json:"dangerous" yaml:"dangerous"`
 }
 
 // ToolsConfig contains tools configuration
 type ToolsConfig struct {
 	Prefix string `mapstructure:"prefix" json:"prefix" yaml:"prefix"`
 	Suffix string `mapstructure:"suffix" json:"suffix" yaml:"suffix"`
+	// Overrides change individual tools, keyed by their default name
+	Overrides map[string]config.ToolOverride `mapstructure:"overrides" json:"overrides" yaml:"overrides"`
+}
+
+// SOPSInfo describes an available SOPS procedure
+type SOPSInfo struct {
+	ID          string       `json:"id"`
+	Description string       `json:"description"`
+	Variables   v1.Variables `json:"variables,omitempty"`
+	Dangerous   bool         `json:"dangerous"`
+}
+
+// SOPSListResponse represents the available SOPS procedures
+type SOPSListResponse struct {
+	AvailableSops []SOPSInfo `json:"available_sops"`
+	Count         int        `json:"count"`
+}
+
+// SOPSParameter describes a variable of a SOPS procedure
+type SOPSParameter struct {
+	Name        string   `json:"name"`
+	Description string   `json:"description"`
+	Required    bool     `json:"required"`
+	Display     string   `json:"display"`
+	Value       string   `json:"value,omitempty"`
+	Default     string   `json:"default,omitempty"`
+	Regex       string   `json:"regex,omitempty"`
+	Enums       []string `json:"enums,omitempty"`
+	Examples    []string `json:"examples,omitempty"`
+}
+
+// SOPSParametersResponse represents the parameters of a SOPS procedure
+type SOPSParametersResponse struct {
+	SopsID     string          `json:"sops_id"`
+	Parameters []SOPSParameter `json:"parameters"`
+	Count      int             `json:"count"`
+	Dangerous  bool            `json:"dangerous"`
+}
+
+// SOPSRunResult represents the outcome of executing a SOPS procedure, a
+// finished pipeline run or the reason it did not run
+type SOPSRunResult struct {
+	SopsID      string        `json:"sops_id"`
+	PipelineRun string        `json:"pipelinerun,omitempty"`
+	Status      string        `json:"status"`
+	Reason      string        `json:"reason,omitempty"`
+	Message     string        `json:"message,omitempty"`
+	Tasks       []SOPSTaskRun `json:"tasks,omitempty"`
+}
+
+// SOPSTaskRun represents a task of a pipeline run
+type SOPSTaskRun struct {
+	Name    string        `json:"name"`
+	TaskRef string        `json:"task_ref,omitempty"`
+	Status  string        `json:"status"`
+	Nodes   []SOPSNodeRun `json:"nodes,omitempty"`
+}
+
+// SOPSNodeRun represents the steps a task ran on a node
+type SOPSNodeRun struct {
+	Node   string        `json:"node"`
+	Status string        `json:"status,omitempty"`
+	Steps  []SOPSStepRun `json:"steps,omitempty"`
+}
+
+// SOPSStepRun represents a step of a task and its output
+type SOPSStepRun struct {
+	Name   string `json:"name"`
+	Status string `json:"status,omitempty"`
+	Output string `json:"output"`
 }
@@ -0,0 +1,89 @@
+package traces
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"slices"
+)
+
+// CompleteArgument suggests service and operation names known to Jaeger
+func (m *Module) CompleteArgument(ctx context.Context, name string, args map[string]string) ([]string, error) {
+	if m.config.Endpoint == "" {
+		return nil, nil
+	}
+	switch name {
+	case "service", "serviceName":
+		return m.services(ctx)
+	case "operation", "operationName":
+		service := args["service"]
+		if service == "" {
+			service = args["serviceName"]
+		}
+		if service == "" {
+			return nil, nil
+		}
+		// Only known services are cached, the service comes from the client
+		services, err := m.services(ctx)
+		if err != nil {
+			return nil, err
+		}
+		if !slices.Contains(services, service) {
+			return nil, nil
+		}
+		return m.completions.Get(ctx, "operations/"+service, func(ctx context.Context) ([]string, error) {
+			return m.fetchNames(ctx, "/api/operations?"+url.Values{"service": {service}}.Encode())
+		})
+	}
+	return nil, nil
+}
+
+// services returns the service names known to Jaeger
+func (m *Module) services(ctx context.Context) ([]string, error) {
+	return m.completions.Get(ctx, "services", func(ctx context.Context) ([]string, error) {
+		return m.fetchNames(ctx, "/api/services")
+	})
+}
+
+// fetchNames returns the names listed in the data of a Jaeger API response,
+// which holds either strings or objects with a name
+func (m *Module) fetchNames(ctx context.Context, path string) ([]string, error) {
+	resp, err := m.makeJaegerRequest(ctx, "GET", path, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response body: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("Jaeger API returned status %d", resp.StatusCode)
+	}
+
+	var response struct {
+		Data []json.RawMessage `json:"data"`
+	}
+	if err := json.Unmarshal(body, &response); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal response: %w", err)
+	}
+	names := make([]string, 0, len(response.Data))
+	for _, item := range response.Data {
+		var name string
+		if err := json.Unmarshal(item, &name); err == nil {
+			names = append(names, name)
+			continue
+		}
+		var object struct {
+			Name string `json:"name"`
+		}
+		if err := json.Unmarshal(item, &object); err == nil && object.Name != "" {
+			names = append(names, object.Name)
+		}
+	}
+	return names, nil
+}
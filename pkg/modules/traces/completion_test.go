@@ -0,0 +1,46 @@
+package traces
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+
+	"go.uber.org/zap"
+)
+
+func TestCompleteOperationsOfKnownServicesOnly(t *testing.T) {
+	var operationRequests atomic.Int32
+	jaeger := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/services":
+			io.WriteString(w, `{"data":["web"]}`)
+		case "/api/operations":
+			operationRequests.Add(1)
+			io.WriteString(w, `{"data":[{"name":"GET /","spanKind":"server"}]}`)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer jaeger.Close()
+	m, err := New(&Config{Endpoint: jaeger.URL}, zap.NewNop())
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+
+	operations, err := m.CompleteArgument(context.Background(), "operation", map[string]string{"service": "web"})
+	if err != nil || len(operations) != 1 || operations[0] != "GET /" {
+		t.Fatalf("operations of web = %v, %v, want GET /", operations, err)
+	}
+	for i := 0; i < 3; i++ {
+		service := string(rune('a' + i))
+		if operations, err := m.CompleteArgument(context.Background(), "operation", map[string]string{"service": service}); err != nil || operations != nil {
+			t.Errorf("operations of unknown service %s = %v, %v, want none", service, operations, err)
+		}
+	}
+	if n := operationRequests.Load(); n != 1 {
+		t.Errorf("%d operation requests, want unknown services not fetched", n)
+	}
+}
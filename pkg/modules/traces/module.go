@@ -11,8 +11,11 @@ import (
 	"strings"
 	"time"
 
+	"github.com/mark3labs/mcp-go/mcp"
 	"github.com/mark3labs/mcp-go/server"
+	"github.com/shaowenchen/ops-mcp-server/pkg/config"
 	appMetrics "github.com/shaowenchen/ops-mcp-server/pkg/metrics"
+	"github.com/shaowenchen/ops-mcp-server/pkg/modules"
 	"go.uber.org/zap"
 )
 
@@ -30,6 +33,8 @@ type Config struct {
 type ToolsConfig struct {
 	Prefix string `mapstructure:"prefix" json:"prefix" yaml:"prefix"`
 	Suffix string `mapstructure:"suffix" json:"suffix" yaml:"suffix"`
+	// Overrides change individual tools, keyed by their default name
+	Overrides map[string]config.ToolOverride `mapstructure:"overrides" json:"overrides" yaml:"overrides"`
 }
 
 // Module represents the Jaeger module
@@ -38,6 +43,9 @@ type Module struct {
 	logger     *zap.Logger
 	httpClient *http.Client
 	baseURL    string
+	tools      JaegerToolsConfig
+	// completions caches service and operation names for completion
+	completions *modules.CompletionCache
 }
 
 // New creates a new Jaeger module
@@ -97,7 +105,8 @@ func New(config *Config, logger *zap.Logger) (*Module, error) {
 			Transport: transport,
 			Timeout:   timeout, // Use configured timeout for faster connection release
 		},
-		baseURL: baseURL,
+		baseURL:     baseURL,
+		completions: modules.NewCompletionCache(modules.CompletionTTL),
 	}
 
 	if config.Endpoint != "" {
@@ -111,6 +120,11 @@ func New(config *Config, logger *zap.Logger) (*Module, error) {
 		m.logger.Info("Jaeger module created without Jaeger configuration - tools will return configuration required error")
 	}
 
+	m.tools = GetDefaultToolsConfig()
+	if err := modules.ApplyToolOverrides("traces", m.tools.toolConfigs(), m.config.Tools.Overrides, m.BuildToolName, m.logger); err != nil {
+		return nil, err
+	}
+
 	return m, nil
 }
 
@@ -145,7 +159,9 @@ func (m *Module) makeJaegerRequest(ctx context.Context, method, path string, bod
 		req.Header.Set("Authorization", m.config.Auth)
 	}
 
+	waiting := modules.ProgressFromContext(ctx).Wait("Waiting for Jaeger")
 	resp, err := m.httpClient.Do(req)
+	waiting()
 	if err != nil {
 		m.logger.Error("Jaeger request failed",
 			zap.String("method", method),
@@ -163,8 +179,38 @@ func (m *Module) makeJaegerRequest(ctx context.Context, method, path string, bod
 
 // GetTools returns all MCP tools for the Jaeger module
 func (m *Module) GetTools() []server.ServerTool {
-	toolsConfig := GetDefaultToolsConfig()
-	return m.BuildTools(toolsConfig)
+	return m.BuildTools(m.tools)
+}
+
+// GetResources returns the service catalog, exposed while the get services
+// tool is enabled
+func (m *Module) GetResources() []server.ServerResource {
+	if !m.tools.GetServices.Enabled {
+		return nil
+	}
+	return []server.ServerResource{
+		{
+			Resource: mcp.NewResource("ops://traces/services", "Jaeger services",
+				mcp.WithResourceDescription("Names of the services reporting traces to Jaeger"),
+				mcp.WithMIMEType("application/json")),
+			Handler: modules.ToolResource(m.handleGetServices, func(mcp.ReadResourceRequest) map[string]any {
+				return map[string]any{}
+			}),
+		},
+	}
+}
+
+// GetResourceTemplates returns no templates, services have a fixed URI
+func (m *Module) GetResourceTemplates() []server.ServerResourceTemplate {
+	return nil
+}
+
+// HealthCheck reports whether Jaeger is configured
+func (m *Module) HealthCheck() error {
+	if m.config.Endpoint == "" {
+		return fmt.Errorf("Jaeger configuration not found - please set traces.jaeger.endpoint in config")
+	}
+	return nil
 }
 
 // BuildToolName builds tool name based on configuration
@@ -0,0 +1,36 @@
+package traces
+
+import (
+	"github.com/shaowenchen/ops-mcp-server/pkg/config"
+	"github.com/shaowenchen/ops-mcp-server/pkg/modules"
+	"go.uber.org/zap"
+)
+
+func init() {
+	modules.Register(modules.Factory{
+		Name:    "traces",
+		Section: "traces",
+		Enabled: func(cfg *config.Config) *bool { return &cfg.Traces.Enabled },
+		New: func(cfg *config.Config, logger *zap.Logger) (modules.Module, error) {
+			return New(ConfigFrom(cfg), logger)
+		},
+	})
+}
+
+// ConfigFrom builds the traces module configuration from the server configuration
+func ConfigFrom(cfg *config.Config) *Config {
+	tracesConfig := &Config{
+		Tools: ToolsConfig{
+			Prefix:    cfg.Traces.Tools.Prefix,
+			Suffix:    cfg.Traces.Tools.Suffix,
+			Overrides: cfg.Traces.Tools.Overrides,
+		},
+	}
+	if cfg.Traces.Jaeger != nil {
+		tracesConfig.Endpoint = cfg.Traces.Jaeger.Endpoint
+		tracesConfig.Protocol = "HTTP" // default protocol
+		tracesConfig.Port = 16686      // default port
+		tracesConfig.Timeout = cfg.Traces.Jaeger.Timeout
+	}
+	return tracesConfig
+}
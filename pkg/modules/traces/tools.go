@@ -7,21 +7,20 @@ import (
 	"io"
 	"net/http"
 	"net/url"
+	"reflect"
 	"strconv"
 	"time"
 
+	"github.com/google/jsonschema-go/jsonschema"
 	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/shaowenchen/ops-mcp-server/pkg/modules"
 	"go.opentelemetry.io/otel/attribute"
 	"go.opentelemetry.io/otel/trace"
 	"go.uber.org/zap"
 )
 
 // ToolConfig defines configuration for a single tool
-type ToolConfig struct {
-	Enabled     bool   // Whether the tool is enabled
-	Name        string // Tool name
-	Description string // Tool description
-}
+type ToolConfig = modules.ToolConfig
 
 // JaegerToolsConfig defines configuration for all tools
 type JaegerToolsConfig struct {
@@ -58,125 +57,133 @@ func GetDefaultToolsConfig() JaegerToolsConfig {
 }
 
 // convertJaegerTraceToOpenTelemetry converts Jaeger trace data to OpenTelemetry format
-func (m *Module) convertJaegerTraceToOpenTelemetry(traceData map[string]interface{}) map[string]interface{} {
-	result := make(map[string]interface{})
+func (m *Module) convertJaegerTraceToOpenTelemetry(traceData map[string]interface{}) OTelTrace {
+	var result OTelTrace
 
 	// Extract trace ID
 	if traceID, ok := traceData["traceID"].(string); ok {
-		result["trace_id"] = traceID
+		result.TraceID = traceID
 		// Convert to OpenTelemetry TraceID format
 		if tid, err := trace.TraceIDFromHex(traceID); err == nil {
-			result["trace_id_hex"] = tid.String()
+			result.TraceIDHex = tid.String()
 		}
 	}
 
 	// Extract spans
 	if spans, ok := traceData["spans"].([]interface{}); ok {
-		convertedSpans := make([]map[string]interface{}, 0, len(spans))
+		result.Spans = make([]OTelSpan, 0, len(spans))
 		for _, span := range spans {
 			if spanMap, ok := span.(map[string]interface{}); ok {
-				convertedSpan := m.convertJaegerSpanToOpenTelemetry(spanMap)
-				convertedSpans = append(convertedSpans, convertedSpan)
+				result.Spans = append(result.Spans, m.convertJaegerSpanToOpenTelemetry(spanMap))
 			}
 		}
-		result["spans"] = convertedSpans
 	}
 
 	// Extract processes
 	if processes, ok := traceData["processes"].(map[string]interface{}); ok {
-		result["processes"] = processes
+		result.Processes = processes
 	}
 
 	// Extract warnings
 	if warnings, ok := traceData["warnings"].([]interface{}); ok {
-		result["warnings"] = warnings
+		result.Warnings = warnings
 	}
 
 	return result
 }
 
 // convertJaegerSpanToOpenTelemetry converts Jaeger span data to OpenTelemetry format
-func (m *Module) convertJaegerSpanToOpenTelemetry(spanData map[string]interface{}) map[string]interface{} {
-	result := make(map[string]interface{})
+func (m *Module) convertJaegerSpanToOpenTelemetry(spanData map[string]interface{}) OTelSpan {
+	var result OTelSpan
 
 	// Extract span ID
 	if spanID, ok := spanData["spanID"].(string); ok {
-		result["span_id"] = spanID
+		result.SpanID = spanID
 		// Convert to OpenTelemetry SpanID format
 		if sid, err := trace.SpanIDFromHex(spanID); err == nil {
-			result["span_id_hex"] = sid.String()
+			result.SpanIDHex = sid.String()
 		}
 	}
 
 	// Extract trace ID
 	if traceID, ok := spanData["traceID"].(string); ok {
-		result["trace_id"] = traceID
+		result.TraceID = traceID
 	}
 
 	// Extract parent span ID
 	if parentSpanID, ok := spanData["parentSpanID"].(string); ok {
-		result["parent_span_id"] = parentSpanID
+		result.ParentSpanID = parentSpanID
 	}
 
 	// Extract operation name
 	if operationName, ok := spanData["operationName"].(string); ok {
-		result["name"] = operationName
-		result["operation_name"] = operationName
+		result.Name = operationName
+		result.OperationName = operationName
 	}
 
 	// Extract start time and duration
 	if startTime, ok := spanData["startTime"].(float64); ok {
-		result["start_time"] = int64(startTime)
-		result["start_time_ns"] = int64(startTime * 1000) // Convert microseconds to nanoseconds
+		result.StartTime = int64(startTime)
+		result.StartTimeNs = int64(startTime * 1000) // Convert microseconds to nanoseconds
 	}
 
 	if duration, ok := spanData["duration"].(float64); ok {
-		result["duration"] = int64(duration)
-		result["duration_ns"] = int64(duration * 1000) // Convert microseconds to nanoseconds
+		result.Duration = int64(duration)
+		result.DurationNs = int64(duration * 1000) // Convert microseconds to nanoseconds
 	}
 
 	// Extract tags and convert to OpenTelemetry attributes
 	if tags, ok := spanData["tags"].([]interface{}); ok {
-		attributes := make([]attribute.KeyValue, 0, len(tags))
-		tagMap := make(map[string]interface{})
+		result.Attributes = make(map[string]interface{}, len(tags))
+		result.OtelAttributes = make([]attribute.KeyValue, 0, len(tags))
 
 		for _, tag := range tags {
 			if tagMap, ok := tag.(map[string]interface{}); ok {
 				if key, keyOk := tagMap["key"].(string); keyOk {
 					if value, valueOk := tagMap["value"].(string); valueOk {
-						attributes = append(attributes, attribute.String(key, value))
-						tagMap[key] = value
+						result.OtelAttributes = append(result.OtelAttributes, attribute.String(key, value))
+						result.Attributes[key] = value
 					}
 				}
 			}
 		}
-
-		result["attributes"] = tagMap
-		result["otel_attributes"] = attributes
 	}
 
 	// Extract logs
 	if logs, ok := spanData["logs"].([]interface{}); ok {
-		result["logs"] = logs
+		result.Logs = logs
 	}
 
 	// Extract process
 	if process, ok := spanData["process"].(map[string]interface{}); ok {
-		result["process"] = process
+		result.Process = process
 	}
 
 	// Extract references
 	if references, ok := spanData["references"].([]interface{}); ok {
-		result["references"] = references
+		result.References = references
 	}
 
 	return result
 }
 
+// otelSchemas describes OpenTelemetry attribute values as the type and
+// value they marshal to
+var otelSchemas = modules.TypeSchemas{
+	reflect.TypeFor[attribute.Value](): {
+		Type: "object",
+		Properties: map[string]*jsonschema.Schema{
+			"Type":  {Type: "string"},
+			"Value": {},
+		},
+	},
+}
+
 // Tool definition builder methods
 func (m *Module) buildGetServicesToolDefinition(config ToolConfig) mcp.Tool {
 	return mcp.NewTool(m.BuildToolName(config.Name),
 		mcp.WithDescription(config.Description),
+		modules.OutputSchema[ServicesResponse](nil),
 	)
 }
 
@@ -185,6 +192,7 @@ func (m *Module) buildGetOperationsToolDefinition(config ToolConfig) mcp.Tool {
 		mcp.WithDescription(config.Description),
 		mcp.WithString("service", mcp.Required(), mcp.Description("Filters operations by service name")),
 		mcp.WithString("spanKind", mcp.Description("Filters operations by OpenTelemetry span kind (server, client, producer, consumer, internal)")),
+		modules.OutputSchema[OperationsResponse](nil),
 	)
 }
 
@@ -194,6 +202,7 @@ func (m *Module) buildGetTraceToolDefinition(config ToolConfig) mcp.Tool {
 		mcp.WithString("traceId", mcp.Required(), mcp.Description("Filters spans by OpenTelemetry compatible trace id in 32-character hexadecimal string format")),
 		mcp.WithString("startTime", mcp.Description("The start time to filter spans in the RFC 3339, section 5.6 format, (e.g., 2017-07-21T17:32:28Z)")),
 		mcp.WithString("endTime", mcp.Description("The end time to filter spans in the RFC 3339, section 5.6 format, (e.g., 2017-07-21T17:32:28Z)")),
+		modules.OutputSchema[TraceResponse](otelSchemas),
 	)
 }
 
@@ -207,6 +216,7 @@ func (m *Module) buildFindTracesToolDefinition(config ToolConfig) mcp.Tool {
 		mcp.WithString("durationMin", mcp.Description("Minimum duration of a span in milliseconds")),
 		mcp.WithString("durationMax", mcp.Description("Maximum duration of a span in milliseconds")),
 		mcp.WithString("searchDepth", mcp.Description("Defines the maximum search depth")),
+		modules.OutputSchema[FindTracesResponse](otelSchemas),
 	)
 }
 
@@ -241,7 +251,7 @@ func (m *Module) handleGetServices(ctx context.Context, request mcp.CallToolRequ
 		return nil, fmt.Errorf("Jaeger API returned status %d, body: %s", resp.StatusCode, string(body))
 	}
 
-	var response map[string]interface{}
+	var response JaegerServicesResponse
 	if err := json.Unmarshal(body, &response); err != nil {
 		m.logger.Error("Failed to unmarshal services response",
 			zap.Error(err),
@@ -249,33 +259,11 @@ func (m *Module) handleGetServices(ctx context.Context, request mcp.CallToolRequ
 		return nil, fmt.Errorf("failed to unmarshal services response: %w, body: %s", err, string(body))
 	}
 
-	// Extract services from the response
-	var services []interface{}
-	if data, ok := response["data"]; ok {
-		if servicesArray, ok := data.([]interface{}); ok {
-			services = servicesArray
-		}
-	}
-
-	result := map[string]interface{}{
-		"services":  services,
-		"count":     len(services),
-		"timestamp": time.Now().Format(time.RFC3339),
-	}
-
-	data, err := json.Marshal(result)
-	if err != nil {
-		return nil, fmt.Errorf("failed to marshal response error: %w, body: %s", err, string(body))
-	}
-
-	return &mcp.CallToolResult{
-		Content: []mcp.Content{
-			mcp.TextContent{
-				Type: "text",
-				Text: string(data),
-			},
-		},
-	}, nil
+	return modules.StructuredResult(ServicesResponse{
+		Services:  response.Data,
+		Count:     len(response.Data),
+		Timestamp: time.Now().Format(time.RFC3339),
+	}, "")
 }
 
 func (m *Module) handleGetOperations(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
@@ -330,7 +318,7 @@ func (m *Module) handleGetOperations(ctx context.Context, request mcp.CallToolRe
 		return nil, fmt.Errorf("Jaeger API returned status %d, body: %s", resp.StatusCode, string(body))
 	}
 
-	var response map[string]interface{}
+	var response JaegerOperationsResponse
 	if err := json.Unmarshal(body, &response); err != nil {
 		m.logger.Error("Failed to unmarshal operations response",
 			zap.Error(err),
@@ -338,35 +326,13 @@ func (m *Module) handleGetOperations(ctx context.Context, request mcp.CallToolRe
 		return nil, fmt.Errorf("failed to unmarshal operations response: %w, body: %s", err, string(body))
 	}
 
-	// Extract operations from the response
-	var operations []interface{}
-	if data, ok := response["data"]; ok {
-		if operationsArray, ok := data.([]interface{}); ok {
-			operations = operationsArray
-		}
-	}
-
-	result := map[string]interface{}{
-		"operations": operations,
-		"count":      len(operations),
-		"service":    service,
-		"spanKind":   spanKind,
-		"timestamp":  time.Now().Format(time.RFC3339),
-	}
-
-	data, err := json.Marshal(result)
-	if err != nil {
-		return nil, fmt.Errorf("failed to marshal response: %w", err)
-	}
-
-	return &mcp.CallToolResult{
-		Content: []mcp.Content{
-			mcp.TextContent{
-				Type: "text",
-				Text: string(data),
-			},
-		},
-	}, nil
+	return modules.StructuredResult(OperationsResponse{
+		Operations: response.Data,
+		Count:      len(response.Data),
+		Service:    service,
+		SpanKind:   spanKind,
+		Timestamp:  time.Now().Format(time.RFC3339),
+	}, "")
 }
 
 func (m *Module) handleGetTrace(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
@@ -433,52 +399,29 @@ func (m *Module) handleGetTrace(ctx context.Context, request mcp.CallToolRequest
 		return nil, fmt.Errorf("Jaeger API returned status %d, body: %s", resp.StatusCode, string(body))
 	}
 
-	var response map[string]interface{}
+	// Extract traces from the response and convert to OpenTelemetry format
+	var response struct {
+		Data []map[string]interface{} `json:"data"`
+	}
 	if err := json.Unmarshal(body, &response); err != nil {
 		m.logger.Error("Failed to unmarshal trace response",
 			zap.Error(err),
 			zap.String("response_body", string(body)))
 		return nil, fmt.Errorf("failed to unmarshal trace response: %w, body: %s", err, string(body))
 	}
-
-	// Extract traces from the response and convert to OpenTelemetry format
-	var traces []interface{}
-	var otelTraces []interface{}
-	if data, ok := response["data"]; ok {
-		if tracesArray, ok := data.([]interface{}); ok {
-			traces = tracesArray
-			// Convert each trace to OpenTelemetry format
-			for _, traceData := range tracesArray {
-				if traceMap, ok := traceData.(map[string]interface{}); ok {
-					otelTrace := m.convertJaegerTraceToOpenTelemetry(traceMap)
-					otelTraces = append(otelTraces, otelTrace)
-				}
-			}
-		}
+	otelTraces := make([]OTelTrace, 0, len(response.Data))
+	for _, traceData := range response.Data {
+		otelTraces = append(otelTraces, m.convertJaegerTraceToOpenTelemetry(traceData))
 	}
 
-	result := map[string]interface{}{
-		"traces":      traces,     // Original Jaeger format
-		"otel_traces": otelTraces, // OpenTelemetry format
-		"count":       len(traces),
-		"traceId":     traceID,
-		"format":      "opentelemetry",
-		"timestamp":   time.Now().Format(time.RFC3339),
-	}
-
-	data, err := json.Marshal(result)
-	if err != nil {
-		return nil, fmt.Errorf("failed to marshal response: %w", err)
-	}
-
-	return &mcp.CallToolResult{
-		Content: []mcp.Content{
-			mcp.TextContent{
-				Type: "text",
-				Text: string(data),
-			},
-		},
-	}, nil
+	return modules.StructuredResult(TraceResponse{
+		Traces:     response.Data, // Original Jaeger format
+		OtelTraces: otelTraces,    // OpenTelemetry format
+		Count:      len(response.Data),
+		TraceID:    traceID,
+		Format:     "opentelemetry",
+		Timestamp:  time.Now().Format(time.RFC3339),
+	}, "")
 }
 
 func (m *Module) handleFindTraces(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
@@ -575,56 +518,46 @@ func (m *Module) handleFindTraces(ctx context.Context, request mcp.CallToolReque
 		return nil, fmt.Errorf("Jaeger API returned status %d, body: %s", resp.StatusCode, string(body))
 	}
 
-	var response map[string]interface{}
+	// Extract traces from the response and convert to OpenTelemetry format
+	var response struct {
+		Data []map[string]interface{} `json:"data"`
+	}
 	if err := json.Unmarshal(body, &response); err != nil {
 		m.logger.Error("Failed to unmarshal traces response",
 			zap.Error(err),
 			zap.String("response_body", string(body)))
 		return nil, fmt.Errorf("failed to unmarshal traces response: %w, body: %s", err, string(body))
 	}
+	modules.ProgressFromContext(ctx).Step(fmt.Sprintf("Found %d traces of %s, converting them to OpenTelemetry", len(response.Data), serviceName))
+	otelTraces := make([]OTelTrace, 0, len(response.Data))
+	for _, traceData := range response.Data {
+		otelTraces = append(otelTraces, m.convertJaegerTraceToOpenTelemetry(traceData))
+	}
+
+	return modules.StructuredResult(FindTracesResponse{
+		Traces:        response.Data, // Original Jaeger format
+		OtelTraces:    otelTraces,    // OpenTelemetry format
+		Count:         len(response.Data),
+		ServiceName:   serviceName,
+		OperationName: operationName,
+		StartTimeMin:  startTimeMin,
+		StartTimeMax:  startTimeMax,
+		DurationMin:   durationMin,
+		DurationMax:   durationMax,
+		SearchDepth:   searchDepth,
+		Format:        "opentelemetry",
+		Timestamp:     time.Now().Format(time.RFC3339),
+	}, "")
+}
 
-	// Extract traces from the response and convert to OpenTelemetry format
-	var traces []interface{}
-	var otelTraces []interface{}
-	if data, ok := response["data"]; ok {
-		if tracesArray, ok := data.([]interface{}); ok {
-			traces = tracesArray
-			// Convert each trace to OpenTelemetry format
-			for _, traceData := range tracesArray {
-				if traceMap, ok := traceData.(map[string]interface{}); ok {
-					otelTrace := m.convertJaegerTraceToOpenTelemetry(traceMap)
-					otelTraces = append(otelTraces, otelTrace)
-				}
-			}
-		}
-	}
-
-	result := map[string]interface{}{
-		"traces":        traces,     // Original Jaeger format
-		"otel_traces":   otelTraces, // OpenTelemetry format
-		"count":         len(traces),
-		"serviceName":   serviceName,
-		"operationName": operationName,
-		"startTimeMin":  startTimeMin,
-		"startTimeMax":  startTimeMax,
-		"durationMin":   durationMin,
-		"durationMax":   durationMax,
-		"searchDepth":   searchDepth,
-		"format":        "opentelemetry",
-		"timestamp":     time.Now().Format(time.RFC3339),
-	}
-
-	data, err := json.Marshal(result)
-	if err != nil {
-		return nil, fmt.Errorf("failed to marshal response: %w", err)
-	}
+// toolConfigs returns every tool of the configuration
+func (c *JaegerToolsConfig) toolConfigs() []*ToolConfig {
+	return []*ToolConfig{&c.GetServices, &c.GetOperations, &c.GetTrace, &c.FindTraces}
+}
 
-	return &mcp.CallToolResult{
-		Content: []mcp.Content{
-			mcp.TextContent{
-				Type: "text",
-				Text: string(data),
-			},
-		},
-	}, nil
+// ToolNamesByDefault maps the default name of each enabled tool to the name
+// it is exposed under, after overrides, prefix and suffix
+func (m *Module) ToolNamesByDefault() map[string]string {
+	defaults := GetDefaultToolsConfig()
+	return modules.ToolNamesByDefault(defaults.toolConfigs(), m.tools.toolConfigs(), m.BuildToolName)
 }
@@ -3,6 +3,8 @@ package traces
 import (
 	"encoding/json"
 	"time"
+
+	"go.opentelemetry.io/otel/attribute"
 )
 
 // JaegerOperation represents a Jaeger operation
@@ -11,6 +13,18 @@ type JaegerOperation struct {
 	SpanKind string `json:"spanKind"`
 }
 
+// UnmarshalJSON reads an operation from an object or, as older Jaeger
+// versions list them, from a plain name
+func (o *JaegerOperation) UnmarshalJSON(data []byte) error {
+	var name string
+	if err := json.Unmarshal(data, &name); err == nil {
+		o.Name = name
+		return nil
+	}
+	type operation JaegerOperation
+	return json.Unmarshal(data, (*operation)(o))
+}
+
 // JaegerService represents a Jaeger service
 type JaegerService struct {
 	Name string `json:"name"`
@@ -131,3 +145,75 @@ func (jt *JaegerTime) UnmarshalJSON(data []byte) error {
 func (jt JaegerTime) MarshalJSON() ([]byte, error) {
 	return json.Marshal(jt.Time.UnixNano() / 1000) // Convert to microseconds
 }
+
+// ServicesResponse represents the services known to Jaeger
+type ServicesResponse struct {
+	Services  []string `json:"services"`
+	Count     int      `json:"count"`
+	Timestamp string   `json:"timestamp"`
+}
+
+// OperationsResponse represents the operations of a service
+type OperationsResponse struct {
+	Operations []JaegerOperation `json:"operations"`
+	Count      int               `json:"count"`
+	Service    string            `json:"service"`
+	SpanKind   string            `json:"spanKind"`
+	Timestamp  string            `json:"timestamp"`
+}
+
+// OTelTrace represents a Jaeger trace converted to OpenTelemetry format
+type OTelTrace struct {
+	TraceID    string                 `json:"trace_id,omitempty"`
+	TraceIDHex string                 `json:"trace_id_hex,omitempty"`
+	Spans      []OTelSpan             `json:"spans,omitempty"`
+	Processes  map[string]interface{} `json:"processes,omitempty"`
+	Warnings   []interface{}          `json:"warnings,omitempty"`
+}
+
+// OTelSpan represents a Jaeger span converted to OpenTelemetry format,
+// times and durations are in microseconds and, with the _ns suffix, nanoseconds
+type OTelSpan struct {
+	SpanID         string                 `json:"span_id,omitempty"`
+	SpanIDHex      string                 `json:"span_id_hex,omitempty"`
+	TraceID        string                 `json:"trace_id,omitempty"`
+	ParentSpanID   string                 `json:"parent_span_id,omitempty"`
+	Name           string                 `json:"name,omitempty"`
+	OperationName  string                 `json:"operation_name,omitempty"`
+	StartTime      int64                  `json:"start_time,omitempty"`
+	StartTimeNs    int64                  `json:"start_time_ns,omitempty"`
+	Duration       int64                  `json:"duration,omitempty"`
+	DurationNs     int64                  `json:"duration_ns,omitempty"`
+	Attributes     map[string]interface{} `json:"attributes,omitempty"`
+	OtelAttributes []attribute.KeyValue   `json:"otel_attributes,omitempty"`
+	Logs           []interface{}          `json:"logs,omitempty"`
+	Process        map[string]interface{} `json:"process,omitempty"`
+	References     []interface{}          `json:"references,omitempty"`
+}
+
+// TraceResponse represents a trace in Jaeger and OpenTelemetry formats
+type TraceResponse struct {
+	Traces     []map[string]interface{} `json:"traces"`
+	OtelTraces []OTelTrace              `json:"otel_traces"`
+	Count      int                      `json:"count"`
+	TraceID    string                   `json:"traceId"`
+	Format     string                   `json:"format"`
+	Timestamp  string                   `json:"timestamp"`
+}
+
+// FindTracesResponse represents the traces matching a search in Jaeger and
+// OpenTelemetry formats
+type FindTracesResponse struct {
+	Traces        []map[string]interface{} `json:"traces"`
+	OtelTraces    []OTelTrace              `json:"otel_traces"`
+	Count         int                      `json:"count"`
+	ServiceName   string                   `json:"serviceName"`
+	OperationName string                   `json:"operationName"`
+	StartTimeMin  string                   `json:"startTimeMin"`
+	StartTimeMax  string                   `json:"startTimeMax"`
+	DurationMin   string                   `json:"durationMin"`
+	DurationMax   string                   `json:"durationMax"`
+	SearchDepth   int                      `json:"searchDepth"`
+	Format        string                   `json:"format"`
+	Timestamp     string                   `json:"timestamp"`
+}
@@ -0,0 +1,243 @@
+package prompts
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// ToolLookup returns the name the tool of module with the given default name
+// is exposed under, empty when the caller cannot use it
+type ToolLookup func(ctx context.Context, module, tool string) string
+
+// CompletionSource is a module argument whose completions apply to a prompt argument
+type CompletionSource struct {
+	Module   string
+	Argument string
+}
+
+// argument is a prompt argument, all MCP prompt arguments are strings
+type argument struct {
+	name        string
+	description string
+	required    bool
+	// defaultValue is used when the argument is not set
+	defaultValue string
+	// completions are where values of the argument are suggested from
+	completions []CompletionSource
+}
+
+// step is one tool call of a workflow
+type step struct {
+	module string
+	tool   string
+	// instructions tells how to call tool, exposed under name
+	instructions func(name string, args arguments) string
+}
+
+// workflow is an investigation runbook exposed as a prompt
+type workflow struct {
+	name        string
+	description string
+	arguments   []argument
+	goal        func(args arguments) string
+	steps       []step
+	conclusion  string
+}
+
+// arguments holds the prompt arguments with defaults applied and the time
+// window resolved against the time the prompt was requested
+type arguments struct {
+	values map[string]string
+	start  time.Time
+	end    time.Time
+}
+
+// get returns the value of the argument name
+func (a arguments) get(name string) string {
+	return a.values[name]
+}
+
+// startRFC3339 returns the start of the window as RFC 3339
+func (a arguments) startRFC3339() string {
+	return a.start.UTC().Format(time.RFC3339)
+}
+
+// endRFC3339 returns the end of the window as RFC 3339
+func (a arguments) endRFC3339() string {
+	return a.end.UTC().Format(time.RFC3339)
+}
+
+// windowArgument is the time window shared by every workflow
+var windowArgument = argument{
+	name:         "window",
+	description:  "Time window to investigate, such as 30m, 1h or 1d (default: 1h)",
+	defaultValue: "1h",
+}
+
+// Build returns the prompts of the workflows that can use at least one tool.
+// The tool names in the messages are looked up again when a prompt is
+// requested, so they follow reloads and the scope of the caller.
+func Build(lookup ToolLookup) []server.ServerPrompt {
+	var prompts []server.ServerPrompt
+	for _, w := range workflows {
+		if len(w.availableSteps(context.Background(), lookup)) == 0 {
+			continue
+		}
+		prompts = append(prompts, server.ServerPrompt{
+			Prompt:  w.prompt(),
+			Handler: w.handler(lookup),
+		})
+	}
+	return prompts
+}
+
+// CompletionSources returns where the values of argument of the prompt name
+// are completed from, nil when name is not a prompt
+func CompletionSources(name, argument string) []CompletionSource {
+	for _, w := range workflows {
+		if w.name != name {
+			continue
+		}
+		for _, arg := range w.arguments {
+			if arg.name == argument {
+				return arg.completions
+			}
+		}
+	}
+	return nil
+}
+
+// prompt returns the MCP definition of the workflow
+func (w workflow) prompt() mcp.Prompt {
+	options := []mcp.PromptOption{mcp.WithPromptDescription(w.description)}
+	for _, arg := range w.arguments {
+		argOptions := []mcp.ArgumentOption{mcp.ArgumentDescription(arg.description)}
+		if arg.required {
+			argOptions = append(argOptions, mcp.RequiredArgument())
+		}
+		options = append(options, mcp.WithArgument(arg.name, argOptions...))
+	}
+	return mcp.NewPrompt(w.name, options...)
+}
+
+// availableSteps returns the steps whose tool the caller can use, with the
+// name the tool is exposed under
+func (w workflow) availableSteps(ctx context.Context, lookup ToolLookup) map[int]string {
+	names := make(map[int]string)
+	for i, s := range w.steps {
+		if name := lookup(ctx, s.module, s.tool); name != "" {
+			names[i] = name
+		}
+	}
+	return names
+}
+
+// handler returns the prompt handler expanding the workflow into a goal, a
+// plan listing the available tool calls and the request to carry it out
+func (w workflow) handler(lookup ToolLookup) server.PromptHandlerFunc {
+	return func(ctx context.Context, request mcp.GetPromptRequest) (*mcp.GetPromptResult, error) {
+		args, err := w.resolveArguments(request.Params.Arguments, time.Now())
+		if err != nil {
+			return nil, err
+		}
+		names := w.availableSteps(ctx, lookup)
+		if len(names) == 0 {
+			return nil, fmt.Errorf("prompt %s has no tools available, enable the events, metrics, logs, traces or sops module", w.name)
+		}
+
+		var plan strings.Builder
+		plan.WriteString("I will investigate step by step with the tools of the ops MCP server:\n")
+		n := 0
+		for i, s := range w.steps {
+			name, ok := names[i]
+			if !ok {
+				continue
+			}
+			n++
+			fmt.Fprintf(&plan, "\n%d. %s", n, s.instructions(name, args))
+		}
+
+		return &mcp.GetPromptResult{
+			Description: w.description,
+			Messages: []mcp.PromptMessage{
+				mcp.NewPromptMessage(mcp.RoleUser, mcp.NewTextContent(w.goal(args))),
+				mcp.NewPromptMessage(mcp.RoleAssistant, mcp.NewTextContent(plan.String())),
+				mcp.NewPromptMessage(mcp.RoleUser, mcp.NewTextContent(
+					"Go ahead and run these steps in order, adapting the arguments to what earlier steps return. "+
+						"Skip a step when it does not apply and say why. "+w.conclusion)),
+			},
+		}, nil
+	}
+}
+
+// resolveArguments applies defaults, checks required arguments and resolves
+// the time window ending at now
+func (w workflow) resolveArguments(values map[string]string, now time.Time) (arguments, error) {
+	args := arguments{values: make(map[string]string), end: now}
+	for _, arg := range w.arguments {
+		value := strings.TrimSpace(values[arg.name])
+		if value == "" {
+			value = arg.defaultValue
+		}
+		if value == "" && arg.required {
+			return arguments{}, fmt.Errorf("argument %s is required", arg.name)
+		}
+		args.values[arg.name] = value
+	}
+
+	window, err := parseDuration(args.get("window"))
+	if err != nil {
+		return arguments{}, fmt.Errorf("invalid window: %w", err)
+	}
+	args.start = now.Add(-window)
+	return args, nil
+}
+
+// parseDuration parses a Go duration, also accepting days such as 7d
+func parseDuration(value string) (time.Duration, error) {
+	if days, ok := strings.CutSuffix(value, "d"); ok {
+		n, err := strconv.Atoi(days)
+		if err != nil || n <= 0 {
+			return 0, fmt.Errorf("expected a duration such as 30m, 1h or 1d, got %q", value)
+		}
+		return time.Duration(n) * 24 * time.Hour, nil
+	}
+	d, err := time.ParseDuration(value)
+	if err != nil || d <= 0 {
+		return 0, fmt.Errorf("expected a duration such as 30m, 1h or 1d, got %q", value)
+	}
+	return d, nil
+}
+
+// label returns a PromQL matcher of name equal to value, empty when value is empty
+func label(name, value string) string {
+	if value == "" {
+		return ""
+	}
+	return fmt.Sprintf("%s=%q", name, value)
+}
+
+// selector returns a PromQL label selector of the non-empty matchers
+func selector(matchers ...string) string {
+	var nonEmpty []string
+	for _, matcher := range matchers {
+		if matcher != "" {
+			nonEmpty = append(nonEmpty, matcher)
+		}
+	}
+	return "{" + strings.Join(nonEmpty, ",") + "}"
+}
+
+// orAny returns value, or the NATS wildcard when it is empty
+func orAny(value string) string {
+	if value == "" {
+		return "*"
+	}
+	return value
+}
@@ -0,0 +1,150 @@
+package prompts
+
+import "fmt"
+
+// workflows are the investigation runbooks shipped as prompts
+var workflows = []workflow{
+	{
+		name:        "investigate-error-rate",
+		description: "Investigate a high error rate of a service using metrics, logs, traces and events",
+		arguments: []argument{
+			{name: "service", description: "Service with the high error rate", required: true,
+				completions: []CompletionSource{{"traces", "service"}, {"metrics", "service"}}},
+			{name: "namespace", description: "Kubernetes namespace of the service (optional)",
+				completions: []CompletionSource{{"metrics", "namespace"}}},
+			windowArgument,
+		},
+		goal: func(args arguments) string {
+			where := ""
+			if ns := args.get("namespace"); ns != "" {
+				where = fmt.Sprintf(" in namespace %s", ns)
+			}
+			return fmt.Sprintf("Investigate the high error rate of service %s%s between %s and %s (the last %s).",
+				args.get("service"), where, args.startRFC3339(), args.endRFC3339(), args.get("window"))
+		},
+		steps: []step{
+			{module: "metrics", tool: "list-alerts", instructions: func(name string, args arguments) string {
+				return fmt.Sprintf("Call `%s` with labels `%s` to see which alerts are firing for %s, and note their start times.",
+					name, label("service", args.get("service")), args.get("service"))
+			}},
+			{module: "metrics", tool: "list-metrics", instructions: func(name string, args arguments) string {
+				return fmt.Sprintf("Call `%s` with search set to `request` or `error` to find the request and error counters exported for %s.",
+					name, args.get("service"))
+			}},
+			{module: "metrics", tool: "query-metrics-range", instructions: func(name string, args arguments) string {
+				service, namespace := label("service", args.get("service")), label("namespace", args.get("namespace"))
+				labels := selector(service, namespace)
+				errors := selector(service, namespace, `code=~"5.."`)
+				return fmt.Sprintf("Call `%s` with time_range `%s` and the ratio of failed to total requests, for example `sum(rate(http_requests_total%s[5m])) / sum(rate(http_requests_total%s[5m]))` adapted to the metrics found, and note when the error rate started rising.",
+					name, args.get("window"), errors, labels)
+			}},
+			{module: "logs", tool: "list-log-indices", instructions: func(name string, args arguments) string {
+				return fmt.Sprintf("Call `%s` to find the indices holding the logs of %s.", name, args.get("service"))
+			}},
+			{module: "logs", tool: "search-logs", instructions: func(name string, args arguments) string {
+				return fmt.Sprintf("Call `%s` on those indices with a body matching error logs of %s with @timestamp between %s and %s, sorted by @timestamp descending, and group the most frequent error messages.",
+					name, args.get("service"), args.startRFC3339(), args.endRFC3339())
+			}},
+			{module: "traces", tool: "find-traces", instructions: func(name string, args arguments) string {
+				return fmt.Sprintf("Call `%s` with serviceName `%s`, startTimeMin `%s` and startTimeMax `%s`, and look for spans tagged with error to find the failing operation and the downstream dependency involved.",
+					name, args.get("service"), args.startRFC3339(), args.endRFC3339())
+			}},
+			{module: "events", tool: "get-events", instructions: func(name string, args arguments) string {
+				return fmt.Sprintf("Call `%s` with subject_pattern `ops.clusters.*.namespaces.%s.>` and start_time `%d` to check for deployments, restarts or scaling around the time the errors began.",
+					name, orAny(args.get("namespace")), args.start.UnixMilli())
+			}},
+		},
+		conclusion: "Finally summarize the likely root cause with the evidence from each step, when the problem started and the next actions you recommend.",
+	},
+	{
+		name:        "investigate-crashloop",
+		description: "Find out why a Kubernetes pod is crashlooping using events, metrics, logs and SOPS procedures",
+		arguments: []argument{
+			{name: "pod", description: "Name of the crashlooping pod", required: true,
+				completions: []CompletionSource{{"metrics", "pod"}}},
+			{name: "namespace", description: "Kubernetes namespace of the pod", required: true,
+				completions: []CompletionSource{{"metrics", "namespace"}}},
+			windowArgument,
+		},
+		goal: func(args arguments) string {
+			return fmt.Sprintf("Find out why pod %s in namespace %s is crashlooping, looking at %s to %s (the last %s).",
+				args.get("pod"), args.get("namespace"), args.startRFC3339(), args.endRFC3339(), args.get("window"))
+		},
+		steps: []step{
+			{module: "events", tool: "get-events", instructions: func(name string, args arguments) string {
+				return fmt.Sprintf("Call `%s` with subject_pattern `ops.clusters.*.namespaces.%s.pods.%s.event` and start_time `%d` to see the back-off, probe failure, OOM and scheduling events of the pod.",
+					name, args.get("namespace"), args.get("pod"), args.start.UnixMilli())
+			}},
+			{module: "metrics", tool: "query-metrics", instructions: func(name string, args arguments) string {
+				labels := selector(label("namespace", args.get("namespace")), label("pod", args.get("pod")))
+				return fmt.Sprintf("Call `%s` with `kube_pod_container_status_restarts_total%s` and `kube_pod_container_status_last_terminated_reason%s` to get the restart count and the reason of the last termination, such as OOMKilled or Error.",
+					name, labels, labels)
+			}},
+			{module: "metrics", tool: "query-metrics-range", instructions: func(name string, args arguments) string {
+				labels := selector(label("namespace", args.get("namespace")), label("pod", args.get("pod")))
+				return fmt.Sprintf("Call `%s` with time_range `%s` and `container_memory_working_set_bytes%s` compared with `kube_pod_container_resource_limits%s` to confirm or rule out memory exhaustion.",
+					name, args.get("window"), labels, labels)
+			}},
+			{module: "logs", tool: "search-logs", instructions: func(name string, args arguments) string {
+				return fmt.Sprintf("Call `%s` with a body matching the logs of pod %s in namespace %s with @timestamp between %s and %s, and read the last lines written before each restart.",
+					name, args.get("pod"), args.get("namespace"), args.startRFC3339(), args.endRFC3339())
+			}},
+			{module: "sops", tool: "list-sops", instructions: func(name string, args arguments) string {
+				return fmt.Sprintf("Call `%s` to check whether a standard operation procedure covers this failure, and propose it without executing it.", name)
+			}},
+		},
+		conclusion: "Finally explain why the pod is crashlooping, for example an application error, memory exhaustion, a failing probe or a missing configuration or dependency, with the evidence, and propose a fix.",
+	},
+	{
+		name:        "find-slow-traces",
+		description: "Find slow traces of a service endpoint and the spans responsible for the latency",
+		arguments: []argument{
+			{name: "service", description: "Service serving the slow endpoint", required: true,
+				completions: []CompletionSource{{"traces", "service"}}},
+			{name: "operation", description: "Endpoint or operation name, all operations when empty (optional)",
+				completions: []CompletionSource{{"traces", "operation"}}},
+			{name: "min_duration", description: "Only consider traces at least this slow, such as 500ms or 2s (default: 1s)", defaultValue: "1s"},
+			windowArgument,
+		},
+		goal: func(args arguments) string {
+			target := "the endpoints of service " + args.get("service")
+			if op := args.get("operation"); op != "" {
+				target = fmt.Sprintf("endpoint %s of service %s", op, args.get("service"))
+			}
+			return fmt.Sprintf("Find traces slower than %s for %s between %s and %s (the last %s) and explain where the time goes.",
+				args.get("min_duration"), target, args.startRFC3339(), args.endRFC3339(), args.get("window"))
+		},
+		steps: []step{
+			{module: "traces", tool: "get-operations", instructions: func(name string, args arguments) string {
+				if op := args.get("operation"); op != "" {
+					return fmt.Sprintf("Call `%s` with service `%s` to check the exact name of operation %s.", name, args.get("service"), op)
+				}
+				return fmt.Sprintf("Call `%s` with service `%s` to list its operations and pick the endpoints to look at.", name, args.get("service"))
+			}},
+			{module: "traces", tool: "find-traces", instructions: func(name string, args arguments) string {
+				operation := ""
+				if op := args.get("operation"); op != "" {
+					operation = fmt.Sprintf(", operationName `%s`", op)
+				}
+				minDuration := args.get("min_duration")
+				if d, err := parseDuration(minDuration); err == nil {
+					minDuration = fmt.Sprintf("%d", d.Milliseconds())
+				}
+				return fmt.Sprintf("Call `%s` with serviceName `%s`%s, startTimeMin `%s`, startTimeMax `%s` and durationMin `%s` to find the slowest traces.",
+					name, args.get("service"), operation, args.startRFC3339(), args.endRFC3339(), minDuration)
+			}},
+			{module: "traces", tool: "get-trace", instructions: func(name string, args arguments) string {
+				return fmt.Sprintf("Call `%s` with the traceId of the slowest traces and identify the spans taking most of the time, such as database queries, downstream calls or queueing.", name)
+			}},
+			{module: "metrics", tool: "query-metrics-range", instructions: func(name string, args arguments) string {
+				return fmt.Sprintf("Call `%s` with time_range `%s` and a latency quantile such as `histogram_quantile(0.99, sum by (le) (rate(http_request_duration_seconds_bucket%s[5m])))` to tell whether the whole endpoint is slow or only outliers are.",
+					name, args.get("window"), selector(label("service", args.get("service"))))
+			}},
+			{module: "logs", tool: "search-logs", instructions: func(name string, args arguments) string {
+				return fmt.Sprintf("Call `%s` with a body matching the trace ids found to look for timeouts, retries or errors logged by %s during the slow requests.",
+					name, args.get("service"))
+			}},
+		},
+		conclusion: "Finally report the slowest operations with their durations, the spans responsible for the latency and the likely cause.",
+	},
+}